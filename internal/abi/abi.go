@@ -0,0 +1,400 @@
+package abi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tron-api-go/internal/tron"
+)
+
+// 参数类型分类
+type Kind int
+
+const (
+	KindUint Kind = iota
+	KindInt
+	KindBool
+	KindAddress
+	KindFixedBytes
+	KindBytes
+	KindString
+	KindArray // 定长数组 T[N]
+	KindSlice // 变长数组 T[]
+	KindTuple
+)
+
+// 解析后的参数类型
+type Type struct {
+	Kind       Kind
+	Size       int        // 整数位数、定长bytes的字节数或定长数组的长度
+	Elem       *Type      // 数组元素类型
+	Components []Argument // 元组成员
+	Fields     []*Type    // 元组成员类型，与Components一一对应
+}
+
+// ABI中的参数定义
+type Argument struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Indexed    bool       `json:"indexed,omitempty"`
+	Components []Argument `json:"components,omitempty"`
+}
+
+// ABI中的函数、事件或构造函数定义
+type Entry struct {
+	Type            string     `json:"type"`
+	Name            string     `json:"name"`
+	Inputs          []Argument `json:"inputs"`
+	Outputs         []Argument `json:"outputs,omitempty"`
+	Anonymous       bool       `json:"anonymous,omitempty"`
+	StateMutability string     `json:"stateMutability,omitempty"`
+}
+
+// 合约ABI
+type ABI []Entry
+
+// 解析ABI JSON，支持标准数组格式以及TRON节点返回的 {"entrys":[...]} 格式
+func Parse(data []byte) (ABI, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) == 0 {
+		return nil, errors.New("ABI不能为空")
+	}
+
+	var entries []Entry
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("ABI格式错误: %v", err)
+		}
+	} else {
+		var wrapped struct {
+			Entrys []Entry          `json:"entrys"`
+			ABI    *json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("ABI格式错误: %v", err)
+		}
+		if wrapped.ABI != nil {
+			return Parse(*wrapped.ABI)
+		}
+		entries = wrapped.Entrys
+	}
+
+	// TRON节点返回的类型为首字母大写的Function、Event等
+	for i := range entries {
+		entries[i].Type = strings.ToLower(entries[i].Type)
+		if entries[i].Type == "" {
+			entries[i].Type = "function"
+		}
+		entries[i].StateMutability = strings.ToLower(entries[i].StateMutability)
+		for _, args := range [][]Argument{entries[i].Inputs, entries[i].Outputs} {
+			for _, arg := range args {
+				if _, err := arg.ParseType(); err != nil {
+					return nil, fmt.Errorf("%s的参数%s: %v", entries[i].Name, arg.Name, err)
+				}
+			}
+		}
+	}
+	return ABI(entries), nil
+}
+
+// 解析参数类型
+func (a Argument) ParseType() (*Type, error) {
+	return ParseType(a.Type, a.Components)
+}
+
+// 解析类型字符串，如 uint256、address[]、bytes32、tuple[2]
+func ParseType(s string, components []Argument) (*Type, error) {
+	s = strings.TrimSpace(s)
+
+	// 数组后缀从右向左解析，uint256[2][] 是元素为 uint256[2] 的变长数组
+	if strings.HasSuffix(s, "]") {
+		open := strings.LastIndex(s, "[")
+		if open < 0 {
+			return nil, fmt.Errorf("类型格式错误: %s", s)
+		}
+		elem, err := ParseType(s[:open], components)
+		if err != nil {
+			return nil, err
+		}
+		size := s[open+1 : len(s)-1]
+		if size == "" {
+			return &Type{Kind: KindSlice, Elem: elem}, nil
+		}
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("数组长度错误: %s", s)
+		}
+		return &Type{Kind: KindArray, Size: n, Elem: elem}, nil
+	}
+
+	switch {
+	case s == "address":
+		return &Type{Kind: KindAddress}, nil
+	case s == "bool":
+		return &Type{Kind: KindBool}, nil
+	case s == "string":
+		return &Type{Kind: KindString}, nil
+	case s == "bytes":
+		return &Type{Kind: KindBytes}, nil
+	case s == "trcToken":
+		// TRC10代币ID，编码方式与uint256相同
+		return &Type{Kind: KindUint, Size: 256}, nil
+	case s == "tuple":
+		t := &Type{Kind: KindTuple, Components: components}
+		for _, c := range components {
+			field, err := c.ParseType()
+			if err != nil {
+				return nil, err
+			}
+			t.Fields = append(t.Fields, field)
+		}
+		return t, nil
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+		// 函数签名中的元组写法 (address,uint256)
+		parts, err := splitTypes(s[1 : len(s)-1])
+		if err != nil {
+			return nil, err
+		}
+		t := &Type{Kind: KindTuple}
+		for _, p := range parts {
+			field, err := ParseType(p, nil)
+			if err != nil {
+				return nil, err
+			}
+			t.Components = append(t.Components, Argument{Type: p})
+			t.Fields = append(t.Fields, field)
+		}
+		return t, nil
+	case strings.HasPrefix(s, "uint"), strings.HasPrefix(s, "int"):
+		kind, bits := KindUint, strings.TrimPrefix(s, "uint")
+		if !strings.HasPrefix(s, "uint") {
+			kind, bits = KindInt, strings.TrimPrefix(s, "int")
+		}
+		size := 256
+		if bits != "" {
+			n, err := strconv.Atoi(bits)
+			if err != nil || n <= 0 || n > 256 || n%8 != 0 {
+				return nil, fmt.Errorf("整数类型错误: %s", s)
+			}
+			size = n
+		}
+		return &Type{Kind: kind, Size: size}, nil
+	case strings.HasPrefix(s, "bytes"):
+		n, err := strconv.Atoi(strings.TrimPrefix(s, "bytes"))
+		if err != nil || n <= 0 || n > 32 {
+			return nil, fmt.Errorf("bytes类型错误: %s", s)
+		}
+		return &Type{Kind: KindFixedBytes, Size: n}, nil
+	}
+	return nil, fmt.Errorf("不支持的类型: %s", s)
+}
+
+// 按顶层逗号拆分类型列表，忽略括号内的逗号
+func splitTypes(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("括号不匹配: %s", s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("括号不匹配: %s", s)
+	}
+	return append(parts, strings.TrimSpace(s[start:])), nil
+}
+
+// 规范类型名，用于计算函数选择器和事件主题
+func (t *Type) String() string {
+	switch t.Kind {
+	case KindUint:
+		return "uint" + strconv.Itoa(t.Size)
+	case KindInt:
+		return "int" + strconv.Itoa(t.Size)
+	case KindBool:
+		return "bool"
+	case KindAddress:
+		return "address"
+	case KindFixedBytes:
+		return "bytes" + strconv.Itoa(t.Size)
+	case KindBytes:
+		return "bytes"
+	case KindString:
+		return "string"
+	case KindArray:
+		return t.Elem.String() + "[" + strconv.Itoa(t.Size) + "]"
+	case KindSlice:
+		return t.Elem.String() + "[]"
+	case KindTuple:
+		names := make([]string, len(t.Fields))
+		for i, f := range t.Fields {
+			names[i] = f.String()
+		}
+		return "(" + strings.Join(names, ",") + ")"
+	}
+	return ""
+}
+
+// 是否为动态类型(编码时在头部只保存偏移量)
+func (t *Type) dynamic() bool {
+	switch t.Kind {
+	case KindBytes, KindString, KindSlice:
+		return true
+	case KindArray:
+		return t.Elem.dynamic()
+	case KindTuple:
+		for _, f := range t.Fields {
+			if f.dynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 在头部占用的字节数
+func (t *Type) headSize() int {
+	if t.dynamic() {
+		return 32
+	}
+	switch t.Kind {
+	case KindArray:
+		return t.Size * t.Elem.headSize()
+	case KindTuple:
+		size := 0
+		for _, f := range t.Fields {
+			size += f.headSize()
+		}
+		return size
+	}
+	return 32
+}
+
+// 解析参数列表的类型
+func parseArguments(args []Argument) ([]*Type, error) {
+	types := make([]*Type, len(args))
+	for i, a := range args {
+		t, err := a.ParseType()
+		if err != nil {
+			return nil, err
+		}
+		types[i] = t
+	}
+	return types, nil
+}
+
+// 函数或事件签名，如 transfer(address,uint256)
+func (e *Entry) Signature() string {
+	names := make([]string, len(e.Inputs))
+	for i, a := range e.Inputs {
+		t, err := a.ParseType()
+		if err != nil {
+			return ""
+		}
+		names[i] = t.String()
+	}
+	return e.Name + "(" + strings.Join(names, ",") + ")"
+}
+
+// 函数选择器(签名哈希的前4字节，十六进制)
+func (e *Entry) Selector() string {
+	return hex.EncodeToString(tron.Keccak256([]byte(e.Signature()))[:4])
+}
+
+// 事件主题(签名的Keccak256哈希，十六进制)
+func (e *Entry) Topic() string {
+	return hex.EncodeToString(tron.Keccak256([]byte(e.Signature())))
+}
+
+// 按名称、签名或主题哈希查找事件
+func (a ABI) Events(name string) []*Entry {
+	name = strings.TrimPrefix(strings.ToLower(strings.ReplaceAll(name, " ", "")), "0x")
+	var list []*Entry
+	for i := range a {
+		e := &a[i]
+		if e.Type != "event" {
+			continue
+		}
+		if name == "" || strings.ToLower(e.Name) == name || strings.ToLower(e.Signature()) == name || e.Topic() == name {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// 根据日志主题查找事件定义，要求索引参数数量与主题数一致
+// (TRC20与TRC721的Transfer事件签名相同，仅索引参数数量不同)
+func (a ABI) EventByTopics(topics []string) *Entry {
+	if len(topics) == 0 {
+		return nil
+	}
+	topic := strings.TrimPrefix(strings.ToLower(topics[0]), "0x")
+	for i := range a {
+		e := &a[i]
+		if e.Type != "event" || e.Anonymous || e.Topic() != topic {
+			continue
+		}
+		indexed := 0
+		for _, arg := range e.Inputs {
+			if arg.Indexed {
+				indexed++
+			}
+		}
+		if indexed == len(topics)-1 {
+			return e
+		}
+	}
+	return nil
+}
+
+// 常用标准事件(TRC20、TRC721)，合约未上传ABI时用于解码
+var Standard = mustParse(`[
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256"}]},
+	{"type":"event","name":"Approval","inputs":[
+		{"name":"owner","type":"address","indexed":true},
+		{"name":"spender","type":"address","indexed":true},
+		{"name":"value","type":"uint256"}]},
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"Approval","inputs":[
+		{"name":"owner","type":"address","indexed":true},
+		{"name":"approved","type":"address","indexed":true},
+		{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"ApprovalForAll","inputs":[
+		{"name":"owner","type":"address","indexed":true},
+		{"name":"operator","type":"address","indexed":true},
+		{"name":"approved","type":"bool"}]}
+]`)
+
+func mustParse(s string) ABI {
+	a, err := Parse([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
@@ -0,0 +1,225 @@
+package abi
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 将按32字节分行书写的十六进制拼接为字节
+func words(t *testing.T, lines ...string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		t.Fatalf("bad hex: %v", err)
+	}
+	return b
+}
+
+// 32字节左侧补零
+func pad(s string) string {
+	return strings.Repeat("0", 64-len(s)) + s
+}
+
+// 32字节右侧补零
+func padRight(s string) string {
+	return s + strings.Repeat("0", 64-len(s))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		values  []interface{}
+		encoded []string // 按32字节分行
+		decoded []interface{}
+	}{
+		{
+			name:    "static tuple inline",
+			args:    "(uint256,bool),uint8",
+			values:  []interface{}{[]interface{}{"7", true}, "9"},
+			encoded: []string{pad("7"), pad("1"), pad("9")},
+			decoded: []interface{}{map[string]interface{}{"0": "7", "1": true}, "9"},
+		},
+		{
+			name:   "dynamic tuple by offset",
+			args:   "uint8,(uint256,string)",
+			values: []interface{}{"1", []interface{}{"2", "hi"}},
+			encoded: []string{
+				pad("1"), pad("40"),
+				pad("2"), pad("40"), pad("2"), padRight("6869"),
+			},
+			decoded: []interface{}{"1", map[string]interface{}{"0": "2", "1": "hi"}},
+		},
+		{
+			// Solidity ABI规范中的示例 f(uint256,uint32[],bytes10,bytes)
+			name:   "spec example with bytes10",
+			args:   "uint256,uint32[],bytes10,bytes",
+			values: []interface{}{"0x123", []interface{}{"0x456", "0x789"}, "0x31323334353637383930", "0x48656c6c6f2c20776f726c6421"},
+			encoded: []string{
+				pad("123"), pad("80"), padRight("31323334353637383930"), pad("e0"),
+				pad("2"), pad("456"), pad("789"),
+				pad("d"), padRight("48656c6c6f2c20776f726c6421"),
+			},
+			decoded: []interface{}{"291", []interface{}{"1110", "1929"}, "31323334353637383930", "48656c6c6f2c20776f726c6421"},
+		},
+		{
+			// Solidity ABI规范中的示例 g(uint256[][],string[])
+			name: "nested dynamic arrays",
+			args: "uint256[][],string[]",
+			values: []interface{}{
+				[]interface{}{[]interface{}{"1", "2"}, []interface{}{"3"}},
+				[]interface{}{"one", "two", "three"},
+			},
+			encoded: []string{
+				pad("40"), pad("140"),
+				pad("2"), pad("40"), pad("a0"),
+				pad("2"), pad("1"), pad("2"),
+				pad("1"), pad("3"),
+				pad("3"), pad("60"), pad("a0"), pad("e0"),
+				pad("3"), padRight("6f6e65"),
+				pad("3"), padRight("74776f"),
+				pad("5"), padRight("7468726565"),
+			},
+			decoded: []interface{}{
+				[]interface{}{[]interface{}{"1", "2"}, []interface{}{"3"}},
+				[]interface{}{"one", "two", "three"},
+			},
+		},
+		{
+			name:    "fixed array of dynamic strings",
+			args:    "string[2]",
+			values:  []interface{}{[]interface{}{"a", "b"}},
+			encoded: []string{pad("20"), pad("40"), pad("80"), pad("1"), padRight("61"), pad("1"), padRight("62")},
+			decoded: []interface{}{[]interface{}{"a", "b"}},
+		},
+		{
+			name:   "signed integers sign-extend",
+			args:   "int8,int256,int32",
+			values: []interface{}{"-1", "-2", "5"},
+			encoded: []string{
+				strings.Repeat("f", 64),
+				strings.Repeat("f", 63) + "e",
+				pad("5"),
+			},
+			decoded: []interface{}{"-1", "-2", "5"},
+		},
+		{
+			name:    "bytesN right padded",
+			args:    "bytes4,bytes32,bytes1",
+			values:  []interface{}{"0xdeadbeef", "0x" + strings.Repeat("ab", 32), "0x01"},
+			encoded: []string{padRight("deadbeef"), strings.Repeat("ab", 32), padRight("01")},
+			decoded: []interface{}{"deadbeef", strings.Repeat("ab", 32), "01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := ParseArguments(tt.args)
+			if err != nil {
+				t.Fatalf("ParseArguments(%q) error: %v", tt.args, err)
+			}
+			want := words(t, tt.encoded...)
+
+			got, err := EncodeValues(args, tt.values)
+			if err != nil {
+				t.Fatalf("EncodeValues error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("EncodeValues =\n%x\nwant\n%x", got, want)
+			}
+
+			values, err := DecodeValues(args, want)
+			if err != nil {
+				t.Fatalf("DecodeValues error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.decoded) {
+				t.Fatalf("DecodeValues = %#v, want %#v", values, tt.decoded)
+			}
+		})
+	}
+}
+
+func TestEncodeRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  string
+		value interface{}
+	}{
+		{"int8 below range", "int8", "-129"},
+		{"int8 above range", "int8", "128"},
+		{"uint8 above range", "uint8", "256"},
+		{"negative uint", "uint256", "-1"},
+		{"bytes4 too short", "bytes4", "0xdead"},
+		{"bytes4 too long", "bytes4", "0xdeadbeef00"},
+		{"fixed array length", "uint256[2]", []interface{}{"1"}},
+		{"tuple member count", "(uint256,bool)", []interface{}{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := ParseArguments(tt.args)
+			if err != nil {
+				t.Fatalf("ParseArguments(%q) error: %v", tt.args, err)
+			}
+			if got, err := EncodeValues(args, []interface{}{tt.value}); err == nil {
+				t.Fatalf("EncodeValues(%v) = %x, want error", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsShortData(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		data []string
+	}{
+		{"missing word", "uint256,uint256", []string{pad("1")}},
+		{"offset out of range", "string", []string{pad("40")}},
+		{"length exceeds data", "bytes", []string{pad("20"), pad("40"), padRight("ab")}},
+		{"huge array length", "uint256[]", []string{pad("20"), strings.Repeat("f", 64)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := ParseArguments(tt.args)
+			if err != nil {
+				t.Fatalf("ParseArguments(%q) error: %v", tt.args, err)
+			}
+			if got, err := DecodeValues(args, words(t, tt.data...)); err == nil {
+				t.Fatalf("DecodeValues = %#v, want error", got)
+			}
+		})
+	}
+}
+
+func TestRevertReason(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "Error(string)",
+			data: "08c379a0" + pad("20") + pad("12") + padRight(hex.EncodeToString([]byte("insufficient funds"))),
+			want: "insufficient funds",
+		},
+		{"Panic overflow", "4e487b71" + pad("11"), "Panic(0x11): 算术运算溢出"},
+		{"Panic division by zero", "4e487b71" + pad("12"), "Panic(0x12): 除数或取模数为零"},
+		{"Panic unknown code", "4e487b71" + pad("99"), "Panic(0x99)"},
+		{"Panic truncated", "4e487b71" + "11", ""},
+		{"Error truncated", "08c379a0" + pad("20"), ""},
+		{"custom error", "12345678" + pad("1"), ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			if err != nil {
+				t.Fatalf("bad hex: %v", err)
+			}
+			if got := RevertReason(data); got != tt.want {
+				t.Fatalf("RevertReason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package abi
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"tron-api-go/internal/tron"
+)
+
+var errShortData = errors.New("ABI数据长度不足")
+
+// 解码参数列表。地址为Base58，整数为十进制字符串，bytes为十六进制，
+// 数组为列表，元组为以成员名(无名时为序号)为键的对象
+func DecodeValues(args []Argument, data []byte) ([]interface{}, error) {
+	types, err := parseArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTuple(types, data, 0)
+}
+
+// 解码参数列表并以参数名(无名时为序号)为键返回
+func Decode(args []Argument, data []byte) (map[string]interface{}, error) {
+	values, err := DecodeValues(args, data)
+	if err != nil {
+		return nil, err
+	}
+	return named(args, values), nil
+}
+
+func named(args []Argument, values []interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		out[argName(args, i)] = v
+	}
+	return out
+}
+
+func argName(args []Argument, i int) string {
+	if i < len(args) && args[i].Name != "" {
+		return args[i].Name
+	}
+	return strconv.Itoa(i)
+}
+
+// 从base开始解码一组连续编码的值，动态类型的偏移量相对于base
+func decodeTuple(types []*Type, data []byte, base int) ([]interface{}, error) {
+	values := make([]interface{}, len(types))
+	pos := base
+	for i, t := range types {
+		if t.dynamic() {
+			offset, err := readInt(data, pos)
+			if err != nil {
+				return nil, err
+			}
+			if values[i], err = decodeValue(t, data, base+offset); err != nil {
+				return nil, err
+			}
+			pos += 32
+			continue
+		}
+
+		v, err := decodeValue(t, data, pos)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+		pos += t.headSize()
+	}
+	return values, nil
+}
+
+func decodeValue(t *Type, data []byte, pos int) (interface{}, error) {
+	switch t.Kind {
+	case KindArray, KindSlice:
+		n := t.Size
+		if t.Kind == KindSlice {
+			length, err := readInt(data, pos)
+			if err != nil {
+				return nil, err
+			}
+			n, pos = length, pos+32
+		}
+		// 防止恶意长度导致大量内存分配
+		if n*32 > len(data) {
+			return nil, errShortData
+		}
+		types := make([]*Type, n)
+		for i := range types {
+			types[i] = t.Elem
+		}
+		return decodeTuple(types, data, pos)
+
+	case KindTuple:
+		values, err := decodeTuple(t.Fields, data, pos)
+		if err != nil {
+			return nil, err
+		}
+		return named(t.Components, values), nil
+
+	case KindBytes, KindString:
+		length, err := readInt(data, pos)
+		if err != nil {
+			return nil, err
+		}
+		start := pos + 32
+		if start+length > len(data) {
+			return nil, errShortData
+		}
+		b := data[start : start+length]
+		if t.Kind == KindString {
+			return string(b), nil
+		}
+		return hex.EncodeToString(b), nil
+	}
+
+	word, err := readWord(data, pos)
+	if err != nil {
+		return nil, err
+	}
+	return decodeWord(t, word)
+}
+
+// 解码单个32字节的静态值
+func decodeWord(t *Type, word []byte) (interface{}, error) {
+	switch t.Kind {
+	case KindUint:
+		return new(big.Int).SetBytes(word).String(), nil
+	case KindInt:
+		v := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return v.String(), nil
+	case KindBool:
+		return word[31] != 0, nil
+	case KindAddress:
+		return tron.AddressFromEVM(word[12:]).Base58(), nil
+	case KindFixedBytes:
+		return hex.EncodeToString(word[:t.Size]), nil
+	}
+	return nil, fmt.Errorf("类型%s不能按单个字解码", t)
+}
+
+func readWord(data []byte, pos int) ([]byte, error) {
+	if pos < 0 || pos+32 > len(data) {
+		return nil, errShortData
+	}
+	return data[pos : pos+32], nil
+}
+
+// 读取偏移量或长度
+func readInt(data []byte, pos int) (int, error) {
+	word, err := readWord(data, pos)
+	if err != nil {
+		return 0, err
+	}
+	v := new(big.Int).SetBytes(word)
+	if !v.IsInt64() || v.Int64() > int64(len(data)) {
+		return 0, errShortData
+	}
+	return int(v.Int64()), nil
+}
+
+// 解码事件日志：索引参数从主题中读取(动态类型的索引参数只保存哈希，原样返回)，其余参数从data中解码
+func DecodeLog(e *Entry, topics []string, data []byte) (map[string]interface{}, error) {
+	if !e.Anonymous {
+		topics = topics[1:]
+	}
+
+	var plain []Argument
+	for _, arg := range e.Inputs {
+		if !arg.Indexed {
+			plain = append(plain, arg)
+		}
+	}
+	values, err := DecodeValues(plain, data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{}, len(e.Inputs))
+	ti, pi := 0, 0
+	for i, arg := range e.Inputs {
+		name := argName(e.Inputs, i)
+		if !arg.Indexed {
+			out[name] = values[pi]
+			pi++
+			continue
+		}
+
+		if ti >= len(topics) {
+			return nil, errors.New("事件主题数量与ABI不一致")
+		}
+		topic := strings.TrimPrefix(topics[ti], "0x")
+		ti++
+
+		t, err := arg.ParseType()
+		if err != nil {
+			return nil, err
+		}
+		word, err := hex.DecodeString(topic)
+		if err != nil || len(word) != 32 {
+			return nil, errors.New("事件主题格式错误")
+		}
+		if t.dynamic() || t.Kind == KindArray || t.Kind == KindTuple {
+			out[name] = topic
+			continue
+		}
+		if out[name], err = decodeWord(t, word); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,414 @@
+package abi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"tron-api-go/internal/tron"
+)
+
+// 解析函数签名，如 transfer(address,uint256)、transfer(address to, uint256 amount)，
+// 可带返回值 balanceOf(address) returns (uint256)
+func ParseSignature(s string) (*Entry, error) {
+	s = strings.TrimSpace(s)
+	var returns string
+	if i := strings.Index(s, " returns"); i >= 0 {
+		s, returns = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+len(" returns"):])
+	}
+
+	open := strings.Index(s, "(")
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("函数签名格式错误: %s", s)
+	}
+	inputs, err := ParseArguments(s[open+1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{Type: "function", Name: strings.TrimSpace(s[:open]), Inputs: inputs}
+	if returns != "" {
+		if e.Outputs, err = ParseArguments(returns); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// 解析参数列表，如 "address to, uint256" 或 "(uint256,address)"
+func ParseArguments(s string) ([]Argument, error) {
+	s = strings.TrimSpace(s)
+	// 整体被括号包围时视为参数列表，如 returns (uint256,address)
+	if strings.HasPrefix(s, "(") && closing(s) == len(s)-1 {
+		s = s[1 : len(s)-1]
+	}
+
+	parts, err := splitTypes(s)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]Argument, 0, len(parts))
+	for _, p := range parts {
+		typ, name := p, ""
+		if i := strings.LastIndexAny(p, " \t"); i > 0 && !strings.ContainsAny(p[i+1:], ")]") {
+			typ, name = strings.TrimSpace(p[:i]), p[i+1:]
+		}
+		// 忽略Solidity的数据位置关键字
+		for _, kw := range []string{" memory", " calldata", " storage", " indexed"} {
+			typ = strings.TrimSuffix(typ, kw)
+		}
+		if name == "memory" || name == "calldata" || name == "storage" {
+			name = ""
+		}
+
+		if _, err := ParseType(typ, nil); err != nil {
+			return nil, err
+		}
+		args = append(args, Argument{Name: name, Type: typ})
+	}
+	return args, nil
+}
+
+// 第一个括号闭合的位置
+func closing(s string) int {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// 按名称或签名查找函数
+func (a ABI) Functions(name string) []*Entry {
+	compact := strings.ReplaceAll(name, " ", "")
+	var list []*Entry
+	for i := range a {
+		e := &a[i]
+		if e.Type != "function" {
+			continue
+		}
+		if e.Name == name || e.Signature() == compact {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// 构造函数定义，没有时返回nil
+func (a ABI) Constructor() *Entry {
+	for i := range a {
+		if a[i].Type == "constructor" {
+			return &a[i]
+		}
+	}
+	return nil
+}
+
+// 按参数定义编码参数值。values中的值为JSON解码结果：
+// 整数可为数字或十进制/0x十六进制字符串，地址为Base58或十六进制，bytes为十六进制，
+// 数组为列表，元组为列表或以成员名为键的对象
+func EncodeValues(args []Argument, values []interface{}) ([]byte, error) {
+	if len(values) != len(args) {
+		return nil, fmt.Errorf("参数数量不匹配：需要%d个，实际%d个", len(args), len(values))
+	}
+	types, err := parseArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	return encodeTuple(types, values, args)
+}
+
+// 将以参数名为键的对象转为按参数顺序排列的列表
+func Ordered(args []Argument, values map[string]interface{}) ([]interface{}, error) {
+	list := make([]interface{}, len(args))
+	for i := range args {
+		v, ok := values[argName(args, i)]
+		if !ok {
+			return nil, fmt.Errorf("缺少参数%s", argName(args, i))
+		}
+		list[i] = v
+	}
+	if len(values) != len(args) {
+		return nil, fmt.Errorf("参数数量不匹配：需要%d个，实际%d个", len(args), len(values))
+	}
+	return list, nil
+}
+
+func encodeTuple(types []*Type, values []interface{}, args []Argument) ([]byte, error) {
+	headLen := 0
+	for _, t := range types {
+		headLen += t.headSize()
+	}
+
+	var head, tail []byte
+	for i, t := range types {
+		enc, err := encodeValue(t, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("参数%s: %v", argName(args, i), err)
+		}
+		if t.dynamic() {
+			head = append(head, word(big.NewInt(int64(headLen+len(tail))))...)
+			tail = append(tail, enc...)
+		} else {
+			head = append(head, enc...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t *Type, v interface{}) ([]byte, error) {
+	switch t.Kind {
+	case KindArray, KindSlice:
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s需要数组", t)
+		}
+		if t.Kind == KindArray && len(list) != t.Size {
+			return nil, fmt.Errorf("%s需要%d个元素", t, t.Size)
+		}
+		types := make([]*Type, len(list))
+		for i := range types {
+			types[i] = t.Elem
+		}
+		enc, err := encodeTuple(types, list, nil)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == KindSlice {
+			enc = append(word(big.NewInt(int64(len(list)))), enc...)
+		}
+		return enc, nil
+
+	case KindTuple:
+		var list []interface{}
+		switch tv := v.(type) {
+		case []interface{}:
+			list = tv
+		case map[string]interface{}:
+			ordered, err := Ordered(t.Components, tv)
+			if err != nil {
+				return nil, err
+			}
+			list = ordered
+		default:
+			return nil, fmt.Errorf("%s需要数组或对象", t)
+		}
+		if len(list) != len(t.Fields) {
+			return nil, fmt.Errorf("%s需要%d个成员", t, len(t.Fields))
+		}
+		return encodeTuple(t.Fields, list, t.Components)
+
+	case KindBytes, KindString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s需要字符串", t)
+		}
+		b := []byte(s)
+		if t.Kind == KindBytes {
+			var err error
+			if b, err = decodeHex(s); err != nil {
+				return nil, err
+			}
+		}
+		padded := make([]byte, (len(b)+31)/32*32)
+		copy(padded, b)
+		return append(word(big.NewInt(int64(len(b)))), padded...), nil
+
+	case KindAddress:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("地址需要字符串")
+		}
+		addr, err := parseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		return tron.EncodeAddressParam(addr), nil
+
+	case KindBool:
+		var b bool
+		switch bv := v.(type) {
+		case bool:
+			b = bv
+		case string:
+			switch bv {
+			case "true", "1":
+				b = true
+			case "false", "0":
+			default:
+				return nil, errors.New("bool需要true或false")
+			}
+		default:
+			return nil, errors.New("bool需要true或false")
+		}
+		out := make([]byte, 32)
+		if b {
+			out[31] = 1
+		}
+		return out, nil
+
+	case KindFixedBytes:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s需要十六进制字符串", t)
+		}
+		b, err := decodeHex(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != t.Size {
+			return nil, fmt.Errorf("%s需要%d字节", t, t.Size)
+		}
+		out := make([]byte, 32)
+		copy(out, b)
+		return out, nil
+
+	case KindUint, KindInt:
+		n, err := parseInteger(v)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == KindUint {
+			if n.Sign() < 0 || n.BitLen() > t.Size {
+				return nil, fmt.Errorf("数值超出%s范围", t)
+			}
+			return word(n), nil
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("数值超出%s范围", t)
+		}
+		if n.Sign() < 0 {
+			// 补码表示
+			n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return word(n), nil
+	}
+	return nil, fmt.Errorf("不支持的类型: %s", t)
+}
+
+// 32字节大端整数
+func word(n *big.Int) []byte {
+	out := make([]byte, 32)
+	n.FillBytes(out)
+	return out
+}
+
+// 解析整数：JSON数字、十进制字符串或0x开头的十六进制字符串
+func parseInteger(v interface{}) (*big.Int, error) {
+	var s string
+	switch nv := v.(type) {
+	case json.Number:
+		s = nv.String()
+	case string:
+		s = strings.TrimSpace(nv)
+	case float64:
+		if nv != float64(int64(nv)) {
+			return nil, errors.New("整数不能带小数")
+		}
+		s = fmt.Sprintf("%.0f", nv)
+	default:
+		return nil, errors.New("整数需要数字或字符串")
+	}
+
+	n, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		n, ok = n.SetString(s[2:], 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("整数格式错误: %s", s)
+	}
+	return n, nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "0x"), "0X")
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("十六进制格式错误")
+	}
+	return b, nil
+}
+
+// 解析地址：Base58、41开头的十六进制或0x开头的20字节EVM地址
+func parseAddress(s string) (tron.Address, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") && len(s) == 42 {
+		b, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return tron.Address{}, errors.New("地址格式错误")
+		}
+		return tron.AddressFromEVM(b), nil
+	}
+	return tron.ParseAddress(s)
+}
+
+// 编码函数调用参数(不含选择器)，返回十六进制，用于节点接口的parameter字段
+func (e *Entry) EncodeInputs(values []interface{}) (string, error) {
+	data, err := EncodeValues(e.Inputs, values)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// 解码函数返回值
+func (e *Entry) DecodeOutputs(data []byte) (map[string]interface{}, error) {
+	return Decode(e.Outputs, data)
+}
+
+// Solidity Panic(uint256) 错误码
+var panicCodes = map[uint64]string{
+	0x00: "编译器插入的通用错误",
+	0x01: "assert断言失败",
+	0x11: "算术运算溢出",
+	0x12: "除数或取模数为零",
+	0x21: "枚举值转换越界",
+	0x22: "存储字节数组编码错误",
+	0x31: "对空数组执行pop",
+	0x32: "数组下标越界",
+	0x41: "内存分配过大",
+	0x51: "调用未初始化的函数指针",
+}
+
+// 解析合约回滚数据：Error(string) 返回原因，Panic(uint256) 返回错误码及说明，无法解析时返回空字符串
+func RevertReason(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	switch hex.EncodeToString(data[:4]) {
+	case "08c379a0":
+		values, err := DecodeValues([]Argument{{Type: "string"}}, data[4:])
+		if err != nil {
+			return ""
+		}
+		reason, _ := values[0].(string)
+		return reason
+	case "4e487b71":
+		word, err := readWord(data[4:], 0)
+		if err != nil {
+			return ""
+		}
+		code := new(big.Int).SetBytes(word)
+		reason := fmt.Sprintf("Panic(0x%02x)", code)
+		if desc, ok := panicCodes[code.Uint64()]; ok && code.IsUint64() {
+			reason += ": " + desc
+		}
+		return reason
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package amount
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// TRX精度 (1 TRX = 1,000,000 SUN)
+const TrxDecimals = 6
+
+// 代币精度上限(uint8)
+const maxDecimals = 77
+
+// 定点小数金额：以最小单位整数保存，按精度与十进制字符串互相转换，避免浮点误差
+type Amount struct {
+	units    *big.Int
+	decimals int
+}
+
+// 由最小单位和精度创建金额
+func New(units *big.Int, decimals int) Amount {
+	if units == nil {
+		units = new(big.Int)
+	}
+	return Amount{units: new(big.Int).Set(units), decimals: decimals}
+}
+
+// 由int64最小单位创建金额
+func FromInt64(units int64, decimals int) Amount {
+	return Amount{units: big.NewInt(units), decimals: decimals}
+}
+
+// 由SUN创建TRX金额
+func Sun(sun int64) Amount {
+	return FromInt64(sun, TrxDecimals)
+}
+
+// 解析十进制金额字符串，小数位数超过精度时返回错误
+func Parse(s string, decimals int) (Amount, error) {
+	if decimals < 0 || decimals > maxDecimals {
+		return Amount{}, fmt.Errorf("精度无效: %d", decimals)
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Amount{}, errors.New("金额不能为空")
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" && fracPart == "" || strings.Trim(intPart+fracPart, "0123456789") != "" {
+		return Amount{}, fmt.Errorf("金额格式错误: %s", s)
+	}
+
+	// 去掉小数末尾的0后再检查位数，如 1.500 按1位小数处理
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > decimals {
+		return Amount{}, fmt.Errorf("金额小数位数不能超过%d位", decimals)
+	}
+
+	units, ok := new(big.Int).SetString("0"+intPart+fracPart+strings.Repeat("0", decimals-len(fracPart)), 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("金额格式错误: %s", s)
+	}
+	return Amount{units: units, decimals: decimals}, nil
+}
+
+// 解析TRX金额
+func ParseTrx(s string) (Amount, error) {
+	return Parse(s, TrxDecimals)
+}
+
+// 最小单位(副本)
+func (a Amount) Units() *big.Int {
+	if a.units == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.units)
+}
+
+// 最小单位的十进制字符串
+func (a Amount) UnitsString() string {
+	if a.units == nil {
+		return "0"
+	}
+	return a.units.String()
+}
+
+// 精度
+func (a Amount) Decimals() int {
+	return a.decimals
+}
+
+// 以int64返回最小单位，超出范围时ok为false
+func (a Amount) Int64() (int64, bool) {
+	if a.units == nil {
+		return 0, true
+	}
+	if !a.units.IsInt64() {
+		return 0, false
+	}
+	return a.units.Int64(), true
+}
+
+// 符号：-1、0或1
+func (a Amount) Sign() int {
+	if a.units == nil {
+		return 0
+	}
+	return a.units.Sign()
+}
+
+// 比较两个相同精度的金额
+func (a Amount) Cmp(b Amount) int {
+	return a.Units().Cmp(b.Units())
+}
+
+// 相加两个相同精度的金额
+func (a Amount) Add(b Amount) Amount {
+	return Amount{units: new(big.Int).Add(a.Units(), b.Units()), decimals: a.decimals}
+}
+
+// 按精度格式化为十进制字符串，保留全部小数位，如 1.500000
+func (a Amount) String() string {
+	units := a.Units()
+	if a.decimals == 0 {
+		return units.String()
+	}
+
+	s := new(big.Int).Abs(units).String()
+	if len(s) <= a.decimals {
+		s = strings.Repeat("0", a.decimals-len(s)+1) + s
+	}
+	out := s[:len(s)-a.decimals] + "." + s[len(s)-a.decimals:]
+	if units.Sign() < 0 {
+		out = "-" + out
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package amount
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	huge := "115792089237316195423570985008687907853269984665640564039457584007913129639935" // 2^256-1
+
+	tests := []struct {
+		name     string
+		in       string
+		decimals int
+		units    string // 为空表示应返回错误
+	}{
+		{"integer", "1", 6, "1000000"},
+		{"fraction", "1.5", 6, "1500000"},
+		{"full precision", "0.000001", 6, "1"},
+		{"leading dot", ".5", 6, "500000"},
+		{"trailing dot", "5.", 6, "5000000"},
+		{"leading zeros", "007.25", 6, "7250000"},
+		{"trailing zeros beyond precision", "1.500000000", 6, "1500000"},
+		{"surrounding spaces", "  2.5 ", 6, "2500000"},
+		{"zero", "0", 6, "0"},
+		{"zero decimals", "42", 0, "42"},
+		{"zero decimals with zero fraction", "42.0", 0, "42"},
+		{"huge value", huge, 0, huge},
+		{"huge value with decimals", "1" + strings.Repeat("0", 60) + ".5", 18, "1" + strings.Repeat("0", 60) + "5" + strings.Repeat("0", 17)},
+
+		{"excess precision", "1.0000001", 6, ""},
+		{"excess precision zero decimals", "1.5", 0, ""},
+		{"empty", "", 6, ""},
+		{"spaces only", "   ", 6, ""},
+		{"dot only", ".", 6, ""},
+		{"negative", "-1", 6, ""},
+		{"explicit plus", "+1", 6, ""},
+		{"exponent", "1e6", 6, ""},
+		{"exponent with fraction", "1.5E3", 6, ""},
+		{"two dots", "1.2.3", 6, ""},
+		{"comma", "1,5", 6, ""},
+		{"inner space", "1 000", 6, ""},
+		{"hex", "0x10", 6, ""},
+		{"full-width digit", "１", 6, ""},
+		{"negative decimals", "1", -1, ""},
+		{"decimals too large", "1", 78, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Parse(tt.in, tt.decimals)
+			if tt.units == "" {
+				if err == nil {
+					t.Fatalf("Parse(%q, %d) = %s, want error", tt.in, tt.decimals, a.UnitsString())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q, %d) error: %v", tt.in, tt.decimals, err)
+			}
+			if got := a.UnitsString(); got != tt.units {
+				t.Fatalf("Parse(%q, %d) units = %s, want %s", tt.in, tt.decimals, got, tt.units)
+			}
+			if a.Decimals() != tt.decimals {
+				t.Fatalf("Parse(%q, %d) decimals = %d", tt.in, tt.decimals, a.Decimals())
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	tests := []struct {
+		name   string
+		amount Amount
+		want   string
+	}{
+		{"whole", FromInt64(1000000, 6), "1.000000"},
+		{"fraction", FromInt64(1500000, 6), "1.500000"},
+		{"smallest unit", FromInt64(1, 6), "0.000001"},
+		{"below one", FromInt64(123456, 6), "0.123456"},
+		{"zero", FromInt64(0, 6), "0.000000"},
+		{"negative", FromInt64(-1500000, 6), "-1.500000"},
+		{"negative below one", FromInt64(-1, 6), "-0.000001"},
+		{"zero decimals", FromInt64(42, 0), "42"},
+		{"negative zero decimals", FromInt64(-42, 0), "-42"},
+		{"huge", New(huge, 18), "123456789012.345678901234567890"},
+		{"nil units", New(nil, 2), "0.00"},
+		{"zero value", Amount{}, "0"},
+		{"sun", Sun(1), "0.000001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.String(); got != tt.want {
+				t.Fatalf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// 格式化结果可原样解析回相同金额
+func TestRoundTrip(t *testing.T) {
+	for _, s := range []string{"0.000000", "1.000000", "0.000001", "999999999999.999999", "1" + strings.Repeat("0", 70) + ".000000"} {
+		a, err := Parse(s, 6)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", s, err)
+		}
+		if got := a.String(); got != s {
+			t.Fatalf("Parse(%q).String() = %s", s, got)
+		}
+	}
+}
+
+func TestInt64(t *testing.T) {
+	a, _ := Parse("9223372036854.775807", 6)
+	if n, ok := a.Int64(); !ok || n != 9223372036854775807 {
+		t.Fatalf("Int64() = %d, %v, want max int64", n, ok)
+	}
+	a, _ = Parse("9223372036854.775808", 6)
+	if _, ok := a.Int64(); ok {
+		t.Fatal("Int64() of max int64 + 1 should overflow")
+	}
+}
@@ -0,0 +1,226 @@
+package blocks
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 代币类型
+const (
+	TokenTRX   = "trx"
+	TokenTRC10 = "trc10"
+	TokenTRC20 = "trc20"
+)
+
+// TRC20 Transfer(address,address,uint256) 事件签名
+const transferTopic = "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// 查询区块并解析其中成功执行的TRX、TRC10和TRC20转账，区块不存在时返回nil
+func Fetch(config *types.Config, num int64) (*types.Block, error) {
+	nb, err := utils.GetBlockByNum(config, num)
+	if err != nil {
+		return nil, err
+	}
+	if nb == nil {
+		return nil, nil
+	}
+
+	block := Header(nb)
+	block.Transfers = decodeNative(nb, block)
+
+	// TRC20转账以事件日志为准，可覆盖transferFrom和合约内部发起的转账
+	if hasContractCall(nb) {
+		infos, err := utils.GetTransactionInfoByBlockNum(config, num)
+		if err != nil {
+			return nil, err
+		}
+		block.Transfers = append(block.Transfers, decodeLogs(infos, block)...)
+	}
+
+	// 按交易在区块中的顺序排列
+	order := make(map[string]int, len(nb.Transactions))
+	for i, tx := range nb.Transactions {
+		order[tx.TxID] = i
+	}
+	sort.SliceStable(block.Transfers, func(i, j int) bool {
+		return order[block.Transfers[i].TxID] < order[block.Transfers[j].TxID]
+	})
+	return block, nil
+}
+
+// 区块头信息
+func Header(nb *types.NodeBlock) *types.Block {
+	raw := nb.BlockHeader.RawData
+	return &types.Block{
+		Number:     raw.Number,
+		Hash:       nb.BlockID,
+		ParentHash: raw.ParentHash,
+		Timestamp:  raw.Timestamp,
+		Witness:    raw.WitnessAddress,
+		TxCount:    len(nb.Transactions),
+	}
+}
+
+func hasContractCall(nb *types.NodeBlock) bool {
+	for _, tx := range nb.Transactions {
+		for _, ct := range tx.RawData.Contract {
+			if ct.Type == "TriggerSmartContract" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 解析TRX和TRC10转账
+func decodeNative(nb *types.NodeBlock, block *types.Block) []types.Transfer {
+	var transfers []types.Transfer
+	for _, tx := range nb.Transactions {
+		if len(tx.Ret) > 0 && tx.Ret[0].ContractRet != "" && tx.Ret[0].ContractRet != "SUCCESS" {
+			continue
+		}
+
+		for i, ct := range tx.RawData.Contract {
+			var value struct {
+				OwnerAddress string `json:"owner_address"`
+				ToAddress    string `json:"to_address"`
+				AssetName    string `json:"asset_name"`
+				Amount       int64  `json:"amount"`
+			}
+
+			t := types.Transfer{
+				TxID:        tx.TxID,
+				Index:       i,
+				BlockNumber: block.Number,
+				BlockTime:   block.Timestamp,
+			}
+			switch ct.Type {
+			case "TransferContract":
+				t.TokenType = TokenTRX
+				t.Token = "TRX"
+			case "TransferAssetContract":
+				t.TokenType = TokenTRC10
+			default:
+				continue
+			}
+			if err := json.Unmarshal(ct.Parameter.Value, &value); err != nil {
+				continue
+			}
+			if t.TokenType == TokenTRC10 {
+				t.Token = value.AssetName
+			}
+			t.From = value.OwnerAddress
+			t.To = value.ToAddress
+			t.AmountUnits = big.NewInt(value.Amount).String()
+			transfers = append(transfers, t)
+		}
+	}
+	return transfers
+}
+
+// 从事件日志解析TRC20转账
+func decodeLogs(infos []types.TransactionInfo, block *types.Block) []types.Transfer {
+	var transfers []types.Transfer
+	for _, info := range infos {
+		if info.Result == "FAILED" {
+			continue
+		}
+
+		for i, log := range info.Log {
+			// TRC721的Transfer事件tokenId同样为indexed参数，共4个topic，这里只取TRC20
+			if len(log.Topics) != 3 || !strings.EqualFold(log.Topics[0], transferTopic) {
+				continue
+			}
+			contract, err1 := hex.DecodeString(log.Address)
+			from, err2 := hex.DecodeString(log.Topics[1])
+			to, err3 := hex.DecodeString(log.Topics[2])
+			value, ok := new(big.Int).SetString(log.Data, 16)
+			if err1 != nil || err2 != nil || err3 != nil || !ok || len(log.Data) != 64 {
+				continue
+			}
+
+			transfers = append(transfers, types.Transfer{
+				TxID:        info.ID,
+				Index:       i,
+				BlockNumber: block.Number,
+				BlockTime:   block.Timestamp,
+				TokenType:   TokenTRC20,
+				Token:       tron.AddressFromEVM(contract).Base58(),
+				From:        tron.AddressFromEVM(from).Base58(),
+				To:          tron.AddressFromEVM(to).Base58(),
+				AmountUnits: value.String(),
+			})
+		}
+	}
+	return transfers
+}
+
+// 代币精度缓存，用于将转账金额格式化为十进制
+type DecimalsCache struct {
+	config *types.Config
+
+	mu    sync.Mutex
+	cache map[string]int
+}
+
+// 创建代币精度缓存
+func NewDecimalsCache(config *types.Config) *DecimalsCache {
+	return &DecimalsCache{
+		config: config,
+		cache:  map[string]int{"TRX": amount.TrxDecimals},
+	}
+}
+
+// 按代币精度填充转账的十进制金额，查询精度失败时只保留最小单位金额
+func (d *DecimalsCache) Fill(t *types.Transfer) {
+	decimals, ok := d.get(t)
+	if !ok {
+		return
+	}
+	units, ok := new(big.Int).SetString(t.AmountUnits, 10)
+	if !ok {
+		return
+	}
+	t.Amount = amount.New(units, decimals).String()
+	t.Decimals = &decimals
+}
+
+func (d *DecimalsCache) get(t *types.Transfer) (int, bool) {
+	d.mu.Lock()
+	decimals, ok := d.cache[t.Token]
+	d.mu.Unlock()
+	if ok {
+		return decimals, true
+	}
+
+	switch t.TokenType {
+	case TokenTRC10:
+		token, err := utils.GetTrc10Token(d.config, t.Token)
+		if err != nil {
+			return 0, false
+		}
+		decimals = token.Precision
+	case TokenTRC20:
+		v, err := utils.GetTokenDecimals(d.config, t.Token)
+		if err != nil {
+			return 0, false
+		}
+		decimals = v
+	default:
+		return 0, false
+	}
+
+	d.mu.Lock()
+	d.cache[t.Token] = decimals
+	d.mu.Unlock()
+	return decimals, true
+}
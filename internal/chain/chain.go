@@ -0,0 +1,424 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/simulator"
+	"tron-api-go/internal/types"
+)
+
+// 链后端类型
+const (
+	BackendTronGrid  = "trongrid"
+	BackendFullNode  = "fullnode"
+	BackendFixture   = "fixture"
+	BackendSimulator = simulator.Name
+)
+
+// 单次请求节点的默认超时时间和失败重试次数
+const (
+	requestTimeout = 10 * time.Second
+	defaultRetries = 2
+)
+
+// 所有API Key都达到速率上限时等待可用Key的最长时间
+const keyWait = 2 * time.Second
+
+// 后端不支持的查询
+var ErrUnsupported = errors.New("当前链后端不支持该查询")
+
+// 按配置创建链访问客户端
+func New(config *types.Config) (types.ChainClient, error) {
+	switch strings.ToLower(config.Backend) {
+	case "", BackendTronGrid:
+		g := NewTronGrid(config.TronAPIURL)
+		g.setEndpoints(config.SolidityNodeURL, config.EventServerURL)
+		g.setFallbacks(config.FallbackNodeURLs)
+		g.setPolicy(config.UpstreamTimeout, config.UpstreamRetries)
+		g.setAuth(config.APIKeys, config.APIKeyRate, config.Headers)
+		return g, nil
+	case BackendFullNode:
+		f := NewFullNode(config.TronAPIURL)
+		f.setEndpoints(config.SolidityNodeURL, "")
+		f.setFallbacks(config.FallbackNodeURLs)
+		f.setPolicy(config.UpstreamTimeout, config.UpstreamRetries)
+		f.setAuth(config.APIKeys, config.APIKeyRate, config.Headers)
+		return f, nil
+	case BackendFixture:
+		if config.FixtureFile == "" {
+			return nil, errors.New("fixture后端需要指定数据文件")
+		}
+		return LoadFixture(config.FixtureFile)
+	case BackendSimulator:
+		opts := simulator.DefaultOptions()
+		opts.Token, opts.TokenDecimals = config.ContractAddress, config.Decimals
+		node, err := simulator.New(opts)
+		if err != nil {
+			return nil, err
+		}
+		node.Start()
+		return node, nil
+	}
+	return nil, fmt.Errorf("不支持的链后端: %s", config.Backend)
+}
+
+// 节点返回的错误信息
+type walletError struct {
+	Error string `json:"Error"`
+}
+
+// HTTP节点，TronGrid和全节点共用
+type node struct {
+	full     *pool    // /wallet 接口节点，主节点及备用节点
+	solidity *pool    // /walletsolidity 接口节点，为空时使用full
+	event    *pool    // /v1 接口节点，为空时使用full
+	keys     *keyring // TronGrid API Key，为空时不携带
+	headers  map[string]string
+	timeout  time.Duration   // 单次请求超时
+	retries  int             // 失败后的最大重试次数
+	ctx      context.Context // 请求上下文，为空时不受调用方取消和超时影响
+	client   *http.Client
+}
+
+func newNode(baseURL string) node {
+	return node{
+		full:    newPool([]string{baseURL}),
+		timeout: requestTimeout,
+		retries: defaultRetries,
+		client:  &http.Client{},
+	}
+}
+
+// 设置独立的固化节点和事件服务地址
+func (n *node) setEndpoints(solidity, event string) {
+	n.solidity, n.event = n.endpoint(solidity), n.endpoint(event)
+}
+
+// 为空或与主节点相同时返回nil，与全节点共用节点组和健康状态
+func (n *node) endpoint(u string) *pool {
+	u = strings.TrimRight(u, "/")
+	if u == "" || u == n.full.primary() {
+		return nil
+	}
+	return newPool([]string{u})
+}
+
+// 设置备用全节点，主节点失败或熔断时切换
+func (n *node) setFallbacks(urls []string) {
+	n.full = newPool(append([]string{n.full.primary()}, urls...))
+}
+
+// 设置单次请求超时(秒)和失败重试次数，超时为0时使用默认值
+func (n *node) setPolicy(timeout, retries int) {
+	if timeout > 0 {
+		n.timeout = time.Duration(timeout) * time.Second
+	}
+	n.retries = retries
+}
+
+// 设置TronGrid API Key和附加请求头
+func (n *node) setAuth(keys []string, rate int, headers map[string]string) {
+	n.keys = newKeyring(keys, rate)
+	n.headers = headers
+}
+
+// 支持API Key使用统计的链客户端
+type KeyReporter interface {
+	APIKeyStats() []types.APIKeyStats
+}
+
+// 各API Key的使用统计，未配置Key时返回nil
+func (n node) APIKeyStats() []types.APIKeyStats {
+	if n.keys == nil {
+		return nil
+	}
+	return n.keys.stats()
+}
+
+// 支持TronGrid事件服务的链客户端
+type EventReader interface {
+	ContractEvents(address string, query url.Values) (*types.GridEventsResponse, error)
+}
+
+// 支持节点健康统计的链客户端
+type UpstreamReporter interface {
+	UpstreamStats() []types.UpstreamStats
+}
+
+// 各节点的健康度和熔断状态
+func (n node) UpstreamStats() []types.UpstreamStats {
+	list := n.full.stats("full")
+	if n.solidity != nil {
+		list = append(list, n.solidity.stats("solidity")...)
+	}
+	if n.event != nil {
+		list = append(list, n.event.stats("event")...)
+	}
+	return list
+}
+
+func (n node) context() context.Context {
+	if n.ctx == nil {
+		return context.Background()
+	}
+	return n.ctx
+}
+
+// 请求节点并读取响应：按健康度选择节点，失败时退避后切换到其他节点重试。
+// 只读请求均可重试，广播交易仅在未建立连接时重试，避免重复提交
+func (n node) do(method string, endpoint *pool, path string, body []byte) ([]byte, int, error) {
+	ctx := n.context()
+	idempotent := method == http.MethodGet || !strings.Contains(path, "/broadcast")
+	tried := make(map[*upstream]bool)
+	var lastErr error
+
+	// 已选中但尚未记录结果的节点，返回时清除其试探标记，否则熔断中的节点再也不会被选中
+	var pending *upstream
+	defer func() {
+		if pending != nil {
+			endpoint.release(pending)
+		}
+	}()
+	report := func(up *upstream, start time.Time, ok bool) {
+		endpoint.report(up, time.Since(start), ok)
+		pending = nil
+	}
+
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, 0, lastErr
+			case <-timer.C:
+			}
+		}
+
+		up, err := endpoint.pick(tried)
+		if err != nil {
+			if lastErr != nil {
+				return nil, 0, fmt.Errorf("%v (%v)", err, lastErr)
+			}
+			return nil, 0, err
+		}
+		tried[up] = true
+		pending = up
+
+		start := time.Now()
+		data, status, err := n.send(ctx, method, up.url+path, body)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// 调用方取消或超时，不计入节点健康度
+			return nil, 0, err
+		case err != nil:
+			report(up, start, false)
+			lastErr = err
+			if !idempotent && !notSent(err) {
+				return nil, 0, err
+			}
+		case status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || !isJSON(data):
+			report(up, start, false)
+			lastErr = statusError(status, data)
+			if !idempotent {
+				return nil, status, lastErr
+			}
+		default:
+			report(up, start, true)
+			return data, status, nil
+		}
+	}
+	return nil, 0, lastErr
+}
+
+// 向单个节点发送请求。配置了多个API Key时轮换使用，某个Key被限流(429)时换下一个Key重试
+func (n node) send(ctx context.Context, method, u string, body []byte) ([]byte, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	attempts := 1
+	if n.keys != nil {
+		attempts = len(n.keys.keys)
+	}
+	for i := 0; ; i++ {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, 0, err
+		}
+		if method == http.MethodPost {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for name, value := range n.headers {
+			req.Header.Set(name, value)
+		}
+		var key *apiKey
+		if n.keys != nil {
+			if key, err = n.keys.acquire(ctx, keyWait); err != nil {
+				return nil, 0, err
+			}
+			req.Header.Set(APIKeyHeader, key.value)
+		}
+
+		resp, err := n.client.Do(req)
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && key != nil {
+			n.keys.throttle(key, resp)
+			if i+1 < attempts {
+				resp.Body.Close()
+				continue
+			}
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("读取响应失败: %v", err)
+		}
+		return data, resp.StatusCode, nil
+	}
+}
+
+// 连接未建立，请求未发出
+func notSent(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// 响应为JSON或为空
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) == 0 || trimmed[0] == '{' || trimmed[0] == '['
+}
+
+// 节点返回错误状态码或非JSON响应时的错误信息
+func statusError(status int, data []byte) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return errors.New("节点请求过于频繁(HTTP 429)，请配置或增加API Key")
+	case status >= http.StatusInternalServerError:
+		return fmt.Errorf("节点服务异常(HTTP %d)", status)
+	}
+	return fmt.Errorf("节点返回非JSON响应(HTTP %d)", status)
+}
+
+// POST调用 /wallet 接口，/walletsolidity 接口发往固化节点
+func (n node) post(path string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	endpoint := n.full
+	if n.solidity != nil && strings.HasPrefix(path, "/walletsolidity/") {
+		endpoint = n.solidity
+	}
+	data, _, err := n.do(http.MethodPost, endpoint, path, body)
+	if err != nil {
+		return fmt.Errorf("请求TRON节点失败: %v", err)
+	}
+	return decodeWallet(data, out)
+}
+
+// 解析 /wallet 接口响应，节点返回Error时转为错误
+func decodeWallet(data []byte, out interface{}) error {
+	// 部分接口返回数组，只有对象才可能包含错误信息
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		var werr walletError
+		if err := json.Unmarshal(data, &werr); err != nil {
+			return fmt.Errorf("解析节点响应失败: %v", err)
+		}
+		if werr.Error != "" {
+			return errors.New(werr.Error)
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析节点响应失败: %v", err)
+	}
+	return nil
+}
+
+// TronGrid /v1 接口的错误响应
+type gridError struct {
+	Success *bool  `json:"success"`
+	Error   string `json:"error"`
+}
+
+// GET调用TronGrid /v1 接口，设置了事件服务时发往事件服务
+func (n node) get(path string, query url.Values, out interface{}) error {
+	endpoint := n.full
+	if n.event != nil {
+		endpoint = n.event
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	data, status, err := n.do(http.MethodGet, endpoint, path, nil)
+	if err != nil {
+		return fmt.Errorf("请求TronGrid失败: %v", err)
+	}
+	return decodeGrid(data, status, out)
+}
+
+// 解析TronGrid /v1 接口响应，success为false时转为错误
+func decodeGrid(data []byte, status int, out interface{}) error {
+	var gerr gridError
+	if err := json.Unmarshal(data, &gerr); err != nil {
+		return fmt.Errorf("解析TronGrid响应失败(HTTP %d): %v", status, err)
+	}
+	if gerr.Success != nil && !*gerr.Success {
+		if gerr.Error == "" {
+			gerr.Error = fmt.Sprintf("HTTP %d", status)
+		}
+		return errors.New(gerr.Error)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析TronGrid响应失败: %v", err)
+	}
+	return nil
+}
+
+// 绑定请求上下文的链客户端
+type contextual interface {
+	withContext(ctx context.Context) types.ChainClient
+}
+
+// 返回绑定ctx的链客户端，调用方取消或超时时中止对节点的请求和重试。
+// 不支持的后端(fixture、模拟节点)原样返回
+func WithContext(client types.ChainClient, ctx context.Context) types.ChainClient {
+	if c, ok := client.(contextual); ok {
+		return c.withContext(ctx)
+	}
+	return client
+}
+
+// 通过 /wallet/getaccount 查询余额
+func walletBalance(c types.ChainClient, address string) (int64, error) {
+	var account struct {
+		Address string `json:"address"`
+		Balance int64  `json:"balance"`
+	}
+	payload := map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}
+	if err := c.Wallet("/wallet/getaccount", payload, &account); err != nil {
+		return 0, err
+	}
+	return account.Balance, nil
+}
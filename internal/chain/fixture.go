@@ -0,0 +1,199 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sync"
+
+	"tron-api-go/internal/types"
+)
+
+// 本地JSON数据后端，不访问网络，用于测试和演示。数据文件格式：
+//
+//	{
+//	  "wallet": {
+//	    "/wallet/getnowblock": {...},
+//	    "/wallet/triggerconstantcontract": [
+//	      {"match": {"function_selector": "decimals()"}, "response": {...}},
+//	      {"response": {...}}
+//	    ]
+//	  },
+//	  "accounts": {
+//	    "T...": {"balance": 1000000, "transactions": [...], "trc20": [...]}
+//	  }
+//	}
+//
+// wallet中的值为节点响应；为规则列表时按顺序取第一个match与请求参数一致的规则，不带match的规则匹配所有请求
+type Fixture struct {
+	mu       sync.RWMutex
+	rules    map[string][]FixtureRule
+	accounts map[string]FixtureAccount
+}
+
+// 按请求参数匹配的响应
+type FixtureRule struct {
+	Match    map[string]interface{} `json:"match,omitempty"`
+	Response json.RawMessage        `json:"response"`
+}
+
+// 账户数据
+type FixtureAccount struct {
+	Balance      int64                     `json:"balance"`
+	Transactions []types.GridTransaction   `json:"transactions"`
+	Trc20        []types.GridTrc20Transfer `json:"trc20"`
+}
+
+type fixtureFile struct {
+	Wallet   map[string]json.RawMessage `json:"wallet"`
+	Accounts map[string]FixtureAccount  `json:"accounts"`
+}
+
+func NewFixture() *Fixture {
+	return &Fixture{
+		rules:    make(map[string][]FixtureRule),
+		accounts: make(map[string]FixtureAccount),
+	}
+}
+
+// 从JSON文件加载
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取fixture文件失败: %v", err)
+	}
+	var file fixtureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析fixture文件失败: %v", err)
+	}
+
+	f := NewFixture()
+	for path, raw := range file.Wallet {
+		var rules []FixtureRule
+		if json.Unmarshal(raw, &rules) == nil && isRuleList(rules) {
+			f.rules[path] = rules
+			continue
+		}
+		f.rules[path] = []FixtureRule{{Response: raw}}
+	}
+	for address, account := range file.Accounts {
+		f.accounts[address] = account
+	}
+	return f, nil
+}
+
+// 数组中每项都带response时视为规则列表，否则是节点返回的数组
+func isRuleList(rules []FixtureRule) bool {
+	if len(rules) == 0 {
+		return false
+	}
+	for _, r := range rules {
+		if len(r.Response) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 添加接口响应，match为空时匹配所有请求
+func (f *Fixture) Handle(path string, match map[string]interface{}, response interface{}) error {
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	m, _ := normalize(match).(map[string]interface{})
+	f.mu.Lock()
+	f.rules[path] = append(f.rules[path], FixtureRule{Match: m, Response: raw})
+	f.mu.Unlock()
+	return nil
+}
+
+// 设置账户数据
+func (f *Fixture) SetAccount(address string, account FixtureAccount) {
+	f.mu.Lock()
+	f.accounts[address] = account
+	f.mu.Unlock()
+}
+
+func (f *Fixture) Name() string {
+	return BackendFixture
+}
+
+func (f *Fixture) Wallet(path string, payload interface{}, out interface{}) error {
+	params, _ := normalize(payload).(map[string]interface{})
+
+	f.mu.RLock()
+	rules := f.rules[path]
+	f.mu.RUnlock()
+	for _, r := range rules {
+		if matches(r.Match, params) {
+			return decodeWallet(r.Response, out)
+		}
+	}
+
+	// 未定义getaccount时使用accounts中的余额，未列出的账户与节点一样返回空对象(未激活)
+	if path == "/wallet/getaccount" {
+		address, _ := params["address"].(string)
+		raw := []byte("{}")
+		if account, ok := f.account(address); ok {
+			raw, _ = json.Marshal(map[string]interface{}{"address": address, "balance": account.Balance})
+		}
+		return decodeWallet(raw, out)
+	}
+	return fmt.Errorf("fixture未定义接口 %s 的响应", path)
+}
+
+func (f *Fixture) account(address string) (FixtureAccount, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	account, ok := f.accounts[address]
+	return account, ok
+}
+
+func (f *Fixture) Balance(address string) (int64, error) {
+	return walletBalance(f, address)
+}
+
+// 账户交易记录一次全部返回，不分页
+func (f *Fixture) AccountTransactions(address string, query url.Values) (*types.GridTransactionsResponse, error) {
+	account, _ := f.account(address)
+	data := account.Transactions
+	if data == nil {
+		data = []types.GridTransaction{}
+	}
+	return &types.GridTransactionsResponse{Data: data}, nil
+}
+
+func (f *Fixture) AccountTrc20Transfers(address string, query url.Values) (*types.GridTrc20Response, error) {
+	account, _ := f.account(address)
+	data := account.Trc20
+	if data == nil {
+		data = []types.GridTrc20Transfer{}
+	}
+	return &types.GridTrc20Response{Data: data}, nil
+}
+
+// 转为JSON解码后的通用结构，便于与规则比较
+func normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	json.Unmarshal(raw, &out)
+	return out
+}
+
+func matches(match, params map[string]interface{}) bool {
+	for k, v := range match {
+		if !reflect.DeepEqual(params[k], v) {
+			return false
+		}
+	}
+	return true
+}
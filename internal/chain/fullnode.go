@@ -0,0 +1,43 @@
+package chain
+
+import (
+	"context"
+	"net/url"
+
+	"tron-api-go/internal/types"
+)
+
+// java-tron全节点：只有 /wallet 接口，不支持按账户查询交易记录
+type FullNode struct {
+	node
+}
+
+func NewFullNode(baseURL string) *FullNode {
+	return &FullNode{node: newNode(baseURL)}
+}
+
+func (f *FullNode) Name() string {
+	return BackendFullNode
+}
+
+func (f *FullNode) withContext(ctx context.Context) types.ChainClient {
+	c := *f
+	c.ctx = ctx
+	return &c
+}
+
+func (f *FullNode) Wallet(path string, payload interface{}, out interface{}) error {
+	return f.post(path, payload, out)
+}
+
+func (f *FullNode) Balance(address string) (int64, error) {
+	return walletBalance(f, address)
+}
+
+func (f *FullNode) AccountTransactions(address string, query url.Values) (*types.GridTransactionsResponse, error) {
+	return nil, ErrUnsupported
+}
+
+func (f *FullNode) AccountTrc20Transfers(address string, query url.Values) (*types.GridTrc20Response, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/types"
+)
+
+// TronGrid API Key 请求头
+const APIKeyHeader = "TRON-PRO-API-KEY"
+
+// 收到429且未返回Retry-After时暂停使用该Key的时长
+const throttleCooldown = 10 * time.Second
+
+// TronGrid API Key 轮换：按顺序轮流使用，按秒统计各Key的请求数，
+// 达到速率上限或被限流(429)的Key暂时跳过
+type keyring struct {
+	mu   sync.Mutex
+	keys []*apiKey
+	next int
+	rate int // 每个Key每秒请求数上限，0为不限制
+}
+
+type apiKey struct {
+	value     string
+	window    int64     // 当前计数的秒
+	count     int       // 当前秒内的请求数
+	requests  int64     // 累计请求数
+	throttled int64     // 累计被限流次数
+	cooldown  time.Time // 被限流后暂停使用到该时间
+}
+
+func newKeyring(keys []string, rate int) *keyring {
+	if len(keys) == 0 {
+		return nil
+	}
+	k := &keyring{rate: rate}
+	for _, key := range keys {
+		k.keys = append(k.keys, &apiKey{value: key})
+	}
+	return k
+}
+
+// 取下一个可用的Key并记录一次请求。所有Key都达到速率上限或暂停时等待最早可用的Key，
+// 等待超过maxWait时仍使用最早可用的Key，由节点决定是否限流；等待期间ctx取消时返回错误
+func (k *keyring) acquire(ctx context.Context, maxWait time.Duration) (*apiKey, error) {
+	deadline := time.Now().Add(maxWait)
+	for {
+		k.mu.Lock()
+		now := time.Now()
+		key, wait := k.pick(now)
+		if wait == 0 || now.Add(wait).After(deadline) {
+			k.use(key, now)
+			k.mu.Unlock()
+			return key, nil
+		}
+		k.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// 轮询选择可用的Key，无可用Key时返回最早可用的Key及需要等待的时长，调用方需持有锁
+func (k *keyring) pick(now time.Time) (*apiKey, time.Duration) {
+	var soonest *apiKey
+	var soonestWait time.Duration
+	for i := 0; i < len(k.keys); i++ {
+		key := k.keys[(k.next+i)%len(k.keys)]
+		wait := k.waitFor(key, now)
+		if wait == 0 {
+			k.next = (k.next + i + 1) % len(k.keys)
+			return key, 0
+		}
+		if soonest == nil || wait < soonestWait {
+			soonest, soonestWait = key, wait
+		}
+	}
+	return soonest, soonestWait
+}
+
+func (k *keyring) waitFor(key *apiKey, now time.Time) time.Duration {
+	if now.Before(key.cooldown) {
+		return key.cooldown.Sub(now)
+	}
+	if k.rate > 0 && key.window == now.Unix() && key.count >= k.rate {
+		return time.Unix(now.Unix()+1, 0).Sub(now)
+	}
+	return 0
+}
+
+// 记录一次请求，调用方需持有锁
+func (k *keyring) use(key *apiKey, now time.Time) {
+	if key.window != now.Unix() {
+		key.window, key.count = now.Unix(), 0
+	}
+	key.count++
+	key.requests++
+}
+
+// 记录Key被限流，按Retry-After暂停使用
+func (k *keyring) throttle(key *apiKey, resp *http.Response) {
+	cooldown := throttleCooldown
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		cooldown = time.Duration(seconds) * time.Second
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key.throttled++
+	key.cooldown = time.Now().Add(cooldown)
+}
+
+// 各Key的使用统计，Key脱敏显示
+func (k *keyring) stats() []types.APIKeyStats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	list := make([]types.APIKeyStats, 0, len(k.keys))
+	for _, key := range k.keys {
+		s := types.APIKeyStats{
+			Key:       maskKey(key.value),
+			Requests:  key.requests,
+			Throttled: key.throttled,
+		}
+		if key.window == now.Unix() {
+			s.CurrentRate = key.count
+		}
+		if now.Before(key.cooldown) {
+			s.CooldownUntil = key.cooldown.Unix()
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
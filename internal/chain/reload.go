@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"context"
+	"net/url"
+	"sync/atomic"
+
+	"tron-api-go/internal/types"
+)
+
+// 可在运行时替换底层后端的链客户端，配置热加载时替换为新的后端，
+// 进行中的请求继续使用原后端完成
+type Reloadable struct {
+	current atomic.Value // holder
+}
+
+type holder struct {
+	client types.ChainClient
+}
+
+func NewReloadable(client types.ChainClient) *Reloadable {
+	r := &Reloadable{}
+	r.Swap(client)
+	return r
+}
+
+// 替换底层后端
+func (r *Reloadable) Swap(client types.ChainClient) {
+	r.current.Store(holder{client})
+}
+
+// 当前使用的后端
+func (r *Reloadable) Client() types.ChainClient {
+	return r.current.Load().(holder).client
+}
+
+func (r *Reloadable) Name() string {
+	return r.Client().Name()
+}
+
+func (r *Reloadable) Wallet(path string, payload interface{}, out interface{}) error {
+	return r.Client().Wallet(path, payload, out)
+}
+
+func (r *Reloadable) Balance(address string) (int64, error) {
+	return r.Client().Balance(address)
+}
+
+func (r *Reloadable) AccountTransactions(address string, query url.Values) (*types.GridTransactionsResponse, error) {
+	return r.Client().AccountTransactions(address, query)
+}
+
+func (r *Reloadable) AccountTrc20Transfers(address string, query url.Values) (*types.GridTrc20Response, error) {
+	return r.Client().AccountTrc20Transfers(address, query)
+}
+
+// 当前后端的合约事件查询，后端不支持时返回ErrUnsupported
+func (r *Reloadable) ContractEvents(address string, query url.Values) (*types.GridEventsResponse, error) {
+	if e, ok := r.Client().(EventReader); ok {
+		return e.ContractEvents(address, query)
+	}
+	return nil, ErrUnsupported
+}
+
+// 当前后端的API Key使用统计
+func (r *Reloadable) APIKeyStats() []types.APIKeyStats {
+	if k, ok := r.Client().(KeyReporter); ok {
+		return k.APIKeyStats()
+	}
+	return nil
+}
+
+// 当前后端的节点健康统计
+func (r *Reloadable) UpstreamStats() []types.UpstreamStats {
+	if u, ok := r.Client().(UpstreamReporter); ok {
+		return u.UpstreamStats()
+	}
+	return nil
+}
+
+// 绑定请求上下文的当前后端，请求过程中热加载不影响该请求
+func (r *Reloadable) withContext(ctx context.Context) types.ChainClient {
+	return WithContext(r.Client(), ctx)
+}
@@ -0,0 +1,68 @@
+package chain
+
+import (
+	"context"
+	"net/url"
+
+	"tron-api-go/internal/types"
+)
+
+// TronGrid：同时提供 /wallet 节点接口和 /v1 账户索引接口
+type TronGrid struct {
+	node
+}
+
+func NewTronGrid(baseURL string) *TronGrid {
+	return &TronGrid{node: newNode(baseURL)}
+}
+
+func (g *TronGrid) Name() string {
+	return BackendTronGrid
+}
+
+func (g *TronGrid) withContext(ctx context.Context) types.ChainClient {
+	c := *g
+	c.ctx = ctx
+	return &c
+}
+
+func (g *TronGrid) Wallet(path string, payload interface{}, out interface{}) error {
+	return g.post(path, payload, out)
+}
+
+// 通过 /v1/accounts 查询余额
+func (g *TronGrid) Balance(address string) (int64, error) {
+	var resp types.TronAPIResponse
+	if err := g.get("/v1/accounts/"+address, nil, &resp); err != nil {
+		return 0, err
+	}
+	if len(resp.Data) == 0 || resp.Data[0].Balance == nil {
+		return 0, nil
+	}
+	return resp.Data[0].Balance.Int64(), nil
+}
+
+func (g *TronGrid) AccountTransactions(address string, query url.Values) (*types.GridTransactionsResponse, error) {
+	var resp types.GridTransactionsResponse
+	if err := g.get("/v1/accounts/"+address+"/transactions", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (g *TronGrid) AccountTrc20Transfers(address string, query url.Values) (*types.GridTrc20Response, error) {
+	var resp types.GridTrc20Response
+	if err := g.get("/v1/accounts/"+address+"/transactions/trc20", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// 通过 /v1/contracts/{address}/events 查询合约事件，配置了事件服务地址时请求事件服务
+func (g *TronGrid) ContractEvents(address string, query url.Values) (*types.GridEventsResponse, error) {
+	var resp types.GridEventsResponse
+	if err := g.get("/v1/contracts/"+address+"/events", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
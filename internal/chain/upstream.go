@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/types"
+)
+
+// 熔断参数：连续失败达到阈值后暂停使用该节点，到期后放行一个试探请求
+const (
+	breakerThreshold = 5
+	breakerCooldown  = 30 * time.Second
+)
+
+// 重试退避的基准时长，第n次重试等待 base*2^(n-1)，并加入±50%的随机抖动
+const retryBackoff = 200 * time.Millisecond
+
+// 健康度随时间向完全健康恢复的时间尺度，使恢复后的主节点能重新被选中
+const healthRecovery = time.Minute
+
+// 所有节点都处于熔断状态
+var ErrAllUpstreamsDown = errors.New("所有节点均不可用(熔断中)，请稍后重试")
+
+// 同一类接口的一组节点地址，按健康度选择，失败时切换到其他节点
+type pool struct {
+	mu        sync.Mutex
+	upstreams []*upstream
+}
+
+// 单个节点的健康状态
+type upstream struct {
+	url      string
+	health   float64       // 最近请求成功率的指数加权平均，1为完全健康
+	latency  time.Duration // 最近请求耗时的指数加权平均
+	failures int           // 连续失败次数
+	openedAt time.Time     // 熔断开始时间，零值表示未熔断
+	probing  bool          // 熔断到期后正在试探
+	updated  time.Time     // 健康度最近更新时间
+	requests int64
+	errors   int64
+}
+
+func newPool(urls []string) *pool {
+	p := &pool{}
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		u = strings.TrimRight(u, "/")
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		p.upstreams = append(p.upstreams, &upstream{url: u, health: 1})
+	}
+	return p
+}
+
+// 主节点地址
+func (p *pool) primary() string {
+	if p == nil || len(p.upstreams) == 0 {
+		return ""
+	}
+	return p.upstreams[0].url
+}
+
+// 选择健康度最高且未熔断的节点，优先选择本次请求未尝试过的节点
+func (p *pool) pick(tried map[*upstream]bool) (*upstream, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var best *upstream
+	for _, u := range p.upstreams {
+		u.recover(now)
+		if !u.available(now) {
+			continue
+		}
+		if best == nil || better(u, best, tried) {
+			best = u
+		}
+	}
+	if best == nil {
+		return nil, ErrAllUpstreamsDown
+	}
+	if !best.openedAt.IsZero() {
+		best.probing = true
+	}
+	best.requests++
+	return best, nil
+}
+
+// a是否比b更适合本次请求：未尝试过的优先，其次健康度高的，再次耗时短的
+func better(a, b *upstream, tried map[*upstream]bool) bool {
+	if tried[a] != tried[b] {
+		return !tried[a]
+	}
+	if a.health != b.health {
+		return a.health > b.health
+	}
+	return a.latency < b.latency
+}
+
+// 按空闲时长恢复健康度
+func (u *upstream) recover(now time.Time) {
+	if !u.updated.IsZero() && u.health < 1 {
+		frac := float64(now.Sub(u.updated)) / float64(healthRecovery)
+		if frac > 1 {
+			frac = 1
+		}
+		u.health += (1 - u.health) * frac
+	}
+	u.updated = now
+}
+
+// 未熔断，或熔断已到期且没有进行中的试探请求
+func (u *upstream) available(now time.Time) bool {
+	if u.openedAt.IsZero() {
+		return true
+	}
+	return !u.probing && now.Sub(u.openedAt) >= breakerCooldown
+}
+
+// 记录请求结果，更新健康度和熔断状态
+func (p *pool) report(u *upstream, elapsed time.Duration, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	const weight = 0.2
+	u.recover(time.Now())
+	if u.latency == 0 {
+		u.latency = elapsed
+	} else {
+		u.latency = time.Duration(float64(u.latency)*(1-weight) + float64(elapsed)*weight)
+	}
+	u.probing = false
+	if ok {
+		u.health = u.health*(1-weight) + weight
+		u.failures = 0
+		u.openedAt = time.Time{}
+		return
+	}
+	u.health *= 1 - weight
+	u.failures++
+	u.errors++
+	if u.failures >= breakerThreshold {
+		u.openedAt = time.Now()
+	}
+}
+
+// 放弃一次未记录结果的请求(调用方取消等)，只清除试探标记，不影响健康度
+func (p *pool) release(u *upstream) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	u.probing = false
+}
+
+func (p *pool) stats(role string) []types.UpstreamStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list := make([]types.UpstreamStats, 0, len(p.upstreams))
+	for _, u := range p.upstreams {
+		state := "closed"
+		if !u.openedAt.IsZero() {
+			state = "open"
+			if u.available(time.Now()) || u.probing {
+				state = "half-open"
+			}
+		}
+		list = append(list, types.UpstreamStats{
+			Role:      role,
+			URL:       u.url,
+			Health:    float64(int(u.health*1000)) / 1000,
+			LatencyMs: u.latency.Milliseconds(),
+			Requests:  u.requests,
+			Errors:    u.errors,
+			Breaker:   state,
+		})
+	}
+	return list
+}
+
+// 第attempt次重试前的等待时长
+func backoff(attempt int) time.Duration {
+	d := retryBackoff << (attempt - 1)
+	return time.Duration(float64(d) * (0.5 + rand.Float64()))
+}
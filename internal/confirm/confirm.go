@@ -0,0 +1,262 @@
+package confirm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 交易确认状态
+const (
+	StatusBroadcast  = "broadcast"
+	StatusInBlock    = "in_block"
+	StatusSolidified = "solidified"
+	StatusFailed     = "failed"
+	StatusExpired    = "expired"
+	StatusNotFound   = "not_found"
+)
+
+const (
+	// 未上链交易的重新广播间隔
+	rebroadcastInterval = 10 * time.Second
+	// 交易过期后再等待两个区块，避免刚打包的交易被误判为过期
+	expireMargin = 6 * time.Second
+	// 长轮询查询间隔
+	pollInterval = 3 * time.Second
+)
+
+// 节点返回的重复交易错误码
+const dupTransaction = "DUP_TRANSACTION_ERROR"
+
+// 已广播交易
+type entry struct {
+	tx            *types.Transaction
+	expiration    int64
+	lastBroadcast time.Time
+	rebroadcasts  int
+}
+
+// 交易确认跟踪器：记录本服务广播的交易，在过期前自动重新广播，直到交易上链
+type Tracker struct {
+	config *types.Config
+
+	mu  sync.Mutex
+	txs map[string]*entry
+}
+
+// 创建跟踪器并启动后台重播协程
+func NewTracker(config *types.Config) *Tracker {
+	t := &Tracker{
+		config: config,
+		txs:    make(map[string]*entry),
+	}
+	go t.loop()
+	return t
+}
+
+// 记录已广播的签名交易
+func (t *Tracker) Track(tx *types.Transaction) {
+	if tx == nil || tx.TxID == "" || len(tx.Signature) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.txs[tx.TxID]; ok {
+		return
+	}
+	t.txs[tx.TxID] = &entry{
+		tx:            tx,
+		expiration:    utils.TransactionExpiration(tx),
+		lastBroadcast: time.Now(),
+	}
+}
+
+// 广播签名交易并开始跟踪，节点已有该交易时视为成功
+func (t *Tracker) Broadcast(tx *types.Transaction) error {
+	result, err := utils.BroadcastTransaction(t.config, tx)
+	if err != nil && (result == nil || result.Code != dupTransaction) {
+		return err
+	}
+	t.Track(tx)
+	return nil
+}
+
+func (t *Tracker) get(txID string) *entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.txs[txID]; ok {
+		copied := *e
+		return &copied
+	}
+	return nil
+}
+
+func (t *Tracker) forget(txID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.txs, txID)
+}
+
+// 交易是否已过期(含等待余量)
+func Expired(expiration int64) bool {
+	return expiration > 0 && time.Now().After(time.UnixMilli(expiration).Add(expireMargin))
+}
+
+func (t *Tracker) loop() {
+	ticker := time.NewTicker(rebroadcastInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.rebroadcast()
+	}
+}
+
+// 重新广播尚未上链且未过期的交易，已上链或已过期的交易停止跟踪
+func (t *Tracker) rebroadcast() {
+	t.mu.Lock()
+	pending := make([]string, 0, len(t.txs))
+	for txID := range t.txs {
+		pending = append(pending, txID)
+	}
+	t.mu.Unlock()
+
+	for _, txID := range pending {
+		e := t.get(txID)
+		if e == nil {
+			continue
+		}
+		if Expired(e.expiration) {
+			t.forget(txID)
+			continue
+		}
+		if info, err := utils.GetTransactionInfo(t.config, txID); err == nil && info != nil {
+			t.forget(txID)
+			continue
+		}
+		if time.Since(e.lastBroadcast) < rebroadcastInterval {
+			continue
+		}
+
+		// 节点已有该交易时会返回重复交易错误，忽略即可
+		utils.BroadcastTransaction(t.config, e.tx)
+
+		t.mu.Lock()
+		if cur, ok := t.txs[txID]; ok {
+			cur.lastBroadcast = time.Now()
+			cur.rebroadcasts++
+		}
+		t.mu.Unlock()
+	}
+}
+
+// 查询交易确认状态
+func (t *Tracker) Status(txID string) (*types.TransactionStatus, error) {
+	st := &types.TransactionStatus{TxID: txID}
+
+	info, err := utils.GetTransactionInfo(t.config, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	if info != nil {
+		t.forget(txID)
+
+		st.BlockNumber = info.BlockNumber
+		st.BlockTime = info.BlockTimeStamp
+		st.Fee = info.Fee
+		st.Result = info.Receipt.Result
+		st.Message = info.ResMessage
+
+		head, err := utils.GetNowBlockNumber(t.config, false)
+		if err != nil {
+			return nil, err
+		}
+		solid, err := utils.GetNowBlockNumber(t.config, true)
+		if err != nil {
+			return nil, err
+		}
+		st.HeadBlock = head
+		st.SolidBlock = solid
+		if head >= info.BlockNumber {
+			st.Confirmations = head - info.BlockNumber + 1
+		}
+
+		switch {
+		case info.Result == "FAILED" || (info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS"):
+			st.Status = StatusFailed
+		case solid >= info.BlockNumber:
+			// 以固化节点的查询结果为准，防止分叉区块中的交易被误判为已固化
+			solidInfo, err := utils.GetSolidTransactionInfo(t.config, txID)
+			if err != nil {
+				return nil, err
+			}
+			if solidInfo != nil {
+				st.Status = StatusSolidified
+			} else {
+				st.Status = StatusInBlock
+			}
+		default:
+			st.Status = StatusInBlock
+		}
+		return st, nil
+	}
+
+	// 尚未上链：优先使用本服务广播时记录的交易，否则查询节点交易池
+	var tx *types.Transaction
+	if e := t.get(txID); e != nil {
+		tx = e.tx
+		st.Rebroadcasts = e.rebroadcasts
+	} else if pending, err := utils.GetPendingTransaction(t.config, txID); err == nil {
+		tx = pending
+	}
+	if tx == nil {
+		st.Status = StatusNotFound
+		return st, nil
+	}
+
+	st.Expiration = utils.TransactionExpiration(tx)
+	if Expired(st.Expiration) {
+		st.Status = StatusExpired
+	} else {
+		st.Status = StatusBroadcast
+	}
+	return st, nil
+}
+
+// 是否已达到等待的目标状态(失败和过期为最终状态)
+func reached(st *types.TransactionStatus, until string) bool {
+	switch st.Status {
+	case StatusFailed, StatusExpired, StatusSolidified:
+		return true
+	case StatusInBlock:
+		return until == StatusInBlock
+	}
+	return false
+}
+
+// 等待交易达到目标状态(in_block 或 solidified)，超时后返回当前状态
+func (t *Tracker) Wait(ctx context.Context, txID, until string, timeout time.Duration) (*types.TransactionStatus, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		st, err := t.Status(txID)
+		if err == nil && reached(st, until) {
+			return st, nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, err
+			}
+			st.TimedOut = true
+			return st, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
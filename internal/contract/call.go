@@ -0,0 +1,198 @@
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 合约函数调用
+type Call struct {
+	Contract  tron.Address
+	Owner     string // 调用地址
+	Method    *abi.Entry
+	Args      []interface{}
+	CallValue int64 // 随调用转入合约的TRX(sun)
+}
+
+// 只读调用使用的默认地址(全零地址)
+var zeroAddress = tron.AddressFromEVM(make([]byte, 20)).Base58()
+
+// 解析要调用的函数。function为完整签名时直接使用，ABI中有同签名的函数时补充参数名和返回值；
+// 为函数名时从ABI中查找，存在重载时按参数数量区分。returns不为空时覆盖返回值定义
+func (r *Registry) Method(contract, function, supplied, returns string, argCount int) (*abi.Entry, error) {
+	function = strings.TrimSpace(function)
+	if function == "" {
+		return nil, errors.New("函数不能为空")
+	}
+
+	var method *abi.Entry
+	if strings.Contains(function, "(") {
+		parsed, err := abi.ParseSignature(function)
+		if err != nil {
+			return nil, err
+		}
+		method = parsed
+
+		// 签名中已包含返回值时无需查询ABI
+		if len(parsed.Outputs) == 0 && returns == "" {
+			if known, err := r.Resolve(contract, supplied); err == nil {
+				if list := known.Functions(parsed.Signature()); len(list) > 0 {
+					method = list[0]
+				}
+			} else if supplied != "" {
+				return nil, err
+			}
+		}
+	} else {
+		known, err := r.Resolve(contract, supplied)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []*abi.Entry
+		for _, e := range known.Functions(function) {
+			if argCount < 0 || len(e.Inputs) == argCount {
+				candidates = append(candidates, e)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			return nil, fmt.Errorf("ABI中未找到函数%s，请传入完整签名，如 %s(address,uint256)", function, function)
+		case 1:
+			method = candidates[0]
+		default:
+			return nil, fmt.Errorf("函数%s存在多个重载，请传入完整签名", function)
+		}
+	}
+
+	if returns != "" {
+		outputs, err := abi.ParseArguments(returns)
+		if err != nil {
+			return nil, fmt.Errorf("返回值类型错误: %v", err)
+		}
+		copied := *method
+		copied.Outputs = outputs
+		method = &copied
+	}
+	return method, nil
+}
+
+// 解析JSON参数：列表按顺序对应，对象按参数名对应
+func ParseArgs(method *abi.Entry, raw json.RawMessage) ([]interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return []interface{}{}, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.New("args格式错误，需要JSON数组或对象")
+	}
+
+	switch args := v.(type) {
+	case []interface{}:
+		return args, nil
+	case map[string]interface{}:
+		return abi.Ordered(method.Inputs, args)
+	}
+	return nil, errors.New("args格式错误，需要JSON数组或对象")
+}
+
+// 参数个数，用于区分重载函数，无法判断时返回-1
+func ArgCount(raw json.RawMessage) int {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		return len(list)
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) == nil {
+		return len(obj)
+	}
+	return -1
+}
+
+// 编码调用数据
+func (call *Call) parameter() (string, error) {
+	return call.Method.EncodeInputs(call.Args)
+}
+
+// 只读调用(triggerconstantcontract)并解码返回值
+func Constant(config *types.Config, call *Call) (*types.ContractCallResult, error) {
+	parameter, err := call.parameter()
+	if err != nil {
+		return nil, err
+	}
+
+	owner := call.Owner
+	if owner == "" {
+		owner = zeroAddress
+	}
+	resp, err := utils.CallConstantContract(config, map[string]interface{}{
+		"owner_address":     owner,
+		"contract_address":  call.Contract.Base58(),
+		"function_selector": call.Method.Signature(),
+		"parameter":         parameter,
+		"call_value":        call.CallValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.ContractCallResult{
+		Contract:   call.Contract.Base58(),
+		Function:   call.Method.Signature(),
+		Selector:   call.Method.Selector(),
+		Result:     resp.Result,
+		EnergyUsed: resp.EnergyUsed,
+		Reverted:   resp.Reverted,
+	}
+
+	data, err := hex.DecodeString(resp.Result)
+	if err != nil {
+		return nil, errors.New("节点返回数据格式错误")
+	}
+	if resp.Reverted {
+		result.RevertReason = abi.RevertReason(data)
+		if result.RevertReason == "" {
+			result.RevertReason = resp.Message
+		}
+		return result, nil
+	}
+	if len(call.Method.Outputs) > 0 {
+		if result.Outputs, err = call.Method.DecodeOutputs(data); err != nil {
+			return nil, fmt.Errorf("解码返回值失败: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// 构建合约调用交易(triggersmartcontract)，feeLimit为0时使用配置
+func Transaction(config *types.Config, call *Call, feeLimit int64, permissionID int) (*types.Transaction, error) {
+	parameter, err := call.parameter()
+	if err != nil {
+		return nil, err
+	}
+	if feeLimit == 0 {
+		feeLimit = config.FeeLimit
+	}
+
+	return utils.BuildContractTransaction(config, map[string]interface{}{
+		"owner_address":     call.Owner,
+		"contract_address":  call.Contract.Base58(),
+		"function_selector": call.Method.Signature(),
+		"parameter":         parameter,
+		"fee_limit":         feeLimit,
+		"call_value":        call.CallValue,
+	}, permissionID)
+}
@@ -0,0 +1,92 @@
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 部署者每次调用默认最多承担的能量
+const DefaultOriginEnergyLimit = 10000000
+
+// 合约部署参数
+type Deployment struct {
+	Owner                      string
+	Name                       string
+	ABI                        string // ABI JSON，原样提交给节点
+	Bytecode                   string
+	Args                       json.RawMessage // 构造函数参数
+	CallValue                  int64
+	FeeLimit                   int64 // 为0时使用配置
+	ConsumeUserResourcePercent int
+	OriginEnergyLimit          int64
+}
+
+// 构建部署交易：校验ABI，编码构造函数参数并追加到字节码之后，返回交易及合约地址
+func DeployTransaction(config *types.Config, d *Deployment, permissionID int) (*types.Transaction, string, error) {
+	bytecode := strings.TrimPrefix(strings.TrimSpace(d.Bytecode), "0x")
+	if bytecode == "" {
+		return nil, "", errors.New("字节码不能为空")
+	}
+	if _, err := hex.DecodeString(bytecode); err != nil {
+		return nil, "", errors.New("字节码必须为十六进制")
+	}
+	if d.ConsumeUserResourcePercent < 0 || d.ConsumeUserResourcePercent > 100 {
+		return nil, "", errors.New("consumeUserResourcePercent必须在0到100之间")
+	}
+	if d.OriginEnergyLimit <= 0 {
+		return nil, "", errors.New("originEnergyLimit必须大于0")
+	}
+
+	abiJSON := "[]"
+	if strings.TrimSpace(d.ABI) != "" {
+		parsed, err := abi.Parse([]byte(d.ABI))
+		if err != nil {
+			return nil, "", err
+		}
+		// 节点要求ABI为数组，其他格式按解析结果重新生成
+		abiJSON = strings.TrimSpace(d.ABI)
+		if !strings.HasPrefix(abiJSON, "[") {
+			b, _ := json.Marshal(parsed)
+			abiJSON = string(b)
+		}
+
+		if ctor := parsed.Constructor(); ctor != nil {
+			args, err := ParseArgs(ctor, d.Args)
+			if err != nil {
+				return nil, "", err
+			}
+			parameter, err := ctor.EncodeInputs(args)
+			if err != nil {
+				return nil, "", fmt.Errorf("构造函数参数错误: %v", err)
+			}
+			bytecode += parameter
+		} else if ArgCount(d.Args) != 0 {
+			return nil, "", errors.New("ABI中没有构造函数，不能传入构造参数")
+		}
+	} else if ArgCount(d.Args) != 0 {
+		return nil, "", errors.New("传入构造参数时需要提供ABI")
+	}
+
+	feeLimit := d.FeeLimit
+	if feeLimit == 0 {
+		feeLimit = config.FeeLimit
+	}
+
+	return utils.DeployContract(config, map[string]interface{}{
+		"owner_address":                 d.Owner,
+		"name":                          d.Name,
+		"abi":                           abiJSON,
+		"bytecode":                      bytecode,
+		"call_value":                    d.CallValue,
+		"fee_limit":                     feeLimit,
+		"consume_user_resource_percent": d.ConsumeUserResourcePercent,
+		"origin_energy_limit":           d.OriginEnergyLimit,
+	}, permissionID)
+}
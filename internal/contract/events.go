@@ -0,0 +1,291 @@
+package contract
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+const (
+	// 未指定起始区块时默认查询的区块数
+	DefaultEventBlocks = 20
+	// 单次查询最多扫描的区块数，超出时通过nextBlock分页
+	MaxEventBlocks = 200
+	// 通过事件服务查询时单次最多返回的交易数，超出时在区块边界截断并通过nextBlock分页
+	MaxEventTransactions = 200
+	// 并发查询区块的协程数
+	eventWorkers = 8
+	// 事件服务每页返回的事件数
+	eventPageSize = 200
+)
+
+// 合约事件查询条件
+type EventQuery struct {
+	Contract  tron.Address
+	Event     string  // 事件名、签名或主题哈希，为空时返回全部事件
+	ABI       abi.ABI // 用于解码的ABI
+	FromBlock int64
+	ToBlock   int64
+}
+
+// 查询合约事件并按ABI解码。配置了事件服务时由事件服务定位含事件的交易，
+// 否则逐块查询交易日志，单次最多扫描MaxEventBlocks个区块
+func Events(config *types.Config, q EventQuery) (*types.ContractEventsResult, error) {
+	var filter abi.ABI
+	if q.Event != "" {
+		for _, e := range q.ABI.Events(q.Event) {
+			filter = append(filter, *e)
+		}
+		if len(filter) == 0 {
+			return nil, fmt.Errorf("ABI中未找到事件%s", q.Event)
+		}
+	}
+
+	if q.ToBlock == 0 {
+		head, err := utils.GetNowBlockNumber(config, false)
+		if err != nil {
+			return nil, fmt.Errorf("查询最新区块失败: %v", err)
+		}
+		q.ToBlock = head
+	}
+	if q.FromBlock == 0 {
+		q.FromBlock = q.ToBlock - DefaultEventBlocks + 1
+	}
+	if q.FromBlock < 1 {
+		q.FromBlock = 1
+	}
+	if q.FromBlock > q.ToBlock {
+		return nil, fmt.Errorf("起始区块%d大于结束区块%d", q.FromBlock, q.ToBlock)
+	}
+
+	result := &types.ContractEventsResult{
+		Contract:  q.Contract.Base58(),
+		FromBlock: q.FromBlock,
+		ToBlock:   q.ToBlock,
+		Events:    []types.ContractEvent{},
+	}
+	if reader, ok := utils.Chain(config).(chain.EventReader); ok && config.EventServerURL != "" {
+		err := serverEvents(config, reader, q, filter, result)
+		if !errors.Is(err, chain.ErrUnsupported) {
+			if err != nil {
+				return nil, err
+			}
+			result.Count = len(result.Events)
+			return result, nil
+		}
+		result.Events = []types.ContractEvent{}
+	}
+
+	if q.ToBlock-q.FromBlock+1 > MaxEventBlocks {
+		result.ToBlock = q.FromBlock + MaxEventBlocks - 1
+		result.NextBlock = result.ToBlock + 1
+	}
+
+	infos, err := fetchInfos(config, result.FromBlock, result.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	for _, blockInfos := range infos {
+		for _, info := range blockInfos {
+			appendEvents(result, q, filter, info)
+		}
+	}
+	result.Count = len(result.Events)
+	return result, nil
+}
+
+// 按区块时间分页查询事件服务，收集区间内含该合约事件的交易，再查询交易日志按ABI解码。
+// 交易数超过MaxEventTransactions时在区块边界截断，保证同一区块的事件不会跨页
+func serverEvents(config *types.Config, reader chain.EventReader, q EventQuery, filter abi.ABI, result *types.ContractEventsResult) error {
+	var bounds [2]int64
+	for i, num := range []int64{q.FromBlock, q.ToBlock} {
+		block, err := utils.GetBlockByNum(config, num)
+		if err != nil {
+			return fmt.Errorf("查询区块%d失败: %v", num, err)
+		}
+		if block == nil {
+			return fmt.Errorf("区块%d不存在", num)
+		}
+		bounds[i] = block.BlockHeader.RawData.Timestamp
+	}
+
+	query := url.Values{}
+	query.Set("order_by", "block_timestamp,asc")
+	query.Set("limit", strconv.Itoa(eventPageSize))
+	query.Set("min_block_timestamp", strconv.FormatInt(bounds[0], 10))
+	query.Set("max_block_timestamp", strconv.FormatInt(bounds[1], 10))
+	if len(filter) > 0 {
+		query.Set("event_name", filter[0].Name)
+	}
+
+	var txIDs []string
+	seen := map[string]bool{}
+	last := int64(0)
+pages:
+	for {
+		resp, err := reader.ContractEvents(result.Contract, query)
+		if err != nil {
+			if errors.Is(err, chain.ErrUnsupported) {
+				return err
+			}
+			return fmt.Errorf("查询事件服务失败: %v", err)
+		}
+		for _, e := range resp.Data {
+			if e.BlockNumber < q.FromBlock || e.BlockNumber > q.ToBlock || seen[e.TransactionID] {
+				continue
+			}
+			if len(txIDs) >= MaxEventTransactions && e.BlockNumber != last {
+				result.ToBlock, result.NextBlock = last, last+1
+				break pages
+			}
+			seen[e.TransactionID] = true
+			txIDs = append(txIDs, e.TransactionID)
+			last = e.BlockNumber
+		}
+		if resp.Meta.Fingerprint == "" || len(resp.Data) == 0 {
+			break
+		}
+		query.Set("fingerprint", resp.Meta.Fingerprint)
+	}
+
+	infos, err := fetchTxInfos(config, txIDs)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info != nil {
+			appendEvents(result, q, filter, *info)
+		}
+	}
+	return nil
+}
+
+// 解码交易中属于查询合约的日志，追加到结果
+func appendEvents(result *types.ContractEventsResult, q EventQuery, filter abi.ABI, info types.TransactionInfo) {
+	if info.Result == "FAILED" {
+		return
+	}
+	address := hex.EncodeToString(q.Contract.EVMBytes())
+	for i, log := range info.Log {
+		if !strings.EqualFold(logAddress(log.Address), address) {
+			continue
+		}
+		event, ok := decodeEvent(q.ABI, filter, log)
+		if !ok {
+			continue
+		}
+		event.TxID = info.ID
+		event.BlockNumber = info.BlockNumber
+		event.BlockTimeStamp = info.BlockTimeStamp
+		event.LogIndex = i
+		event.Contract = result.Contract
+		result.Events = append(result.Events, event)
+	}
+}
+
+// 日志中的合约地址通常为20字节十六进制，部分节点带41前缀
+func logAddress(s string) string {
+	if len(s) == 42 {
+		return s[2:]
+	}
+	return s
+}
+
+// 解码单条日志。指定了事件时只返回匹配的事件，否则无法解码的日志返回原始数据
+func decodeEvent(full, filter abi.ABI, log types.TransactionLog) (types.ContractEvent, bool) {
+	raw := types.ContractEvent{Topics: log.Topics, Data: log.Data}
+
+	search := full
+	if filter != nil {
+		search = filter
+	}
+	e := search.EventByTopics(log.Topics)
+	if e == nil {
+		return raw, filter == nil
+	}
+
+	data, err := hex.DecodeString(log.Data)
+	if err != nil {
+		return raw, filter == nil
+	}
+	fields, err := abi.DecodeLog(e, log.Topics, data)
+	if err != nil {
+		return raw, filter == nil
+	}
+	return types.ContractEvent{
+		Event:     e.Name,
+		Signature: e.Signature(),
+		Fields:    fields,
+	}, true
+}
+
+// 并发查询区间内每个区块的交易日志，按区块顺序返回
+func fetchInfos(config *types.Config, from, to int64) ([][]types.TransactionInfo, error) {
+	n := int(to - from + 1)
+	infos := make([][]types.TransactionInfo, n)
+	errs := make([]error, n)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < eventWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				infos[i], errs[i] = utils.GetTransactionInfoByBlockNum(config, from+int64(i))
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("查询区块%d日志失败: %v", from+int64(i), err)
+		}
+	}
+	return infos, nil
+}
+
+// 并发查询交易的执行结果和事件日志，按传入顺序返回，未上链的交易为nil
+func fetchTxInfos(config *types.Config, txIDs []string) ([]*types.TransactionInfo, error) {
+	infos := make([]*types.TransactionInfo, len(txIDs))
+	errs := make([]error, len(txIDs))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < eventWorkers && w < len(txIDs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				infos[i], errs[i] = utils.GetTransactionInfo(config, txIDs[i])
+			}
+		}()
+	}
+	for i := range txIDs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("查询交易%s日志失败: %v", txIDs[i], err)
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,75 @@
+package contract
+
+import (
+	"sync"
+	"time"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 链上ABI缓存时间，合约可通过ClearABI清除ABI，因此不永久缓存
+const abiCacheTTL = 10 * time.Minute
+
+type cachedABI struct {
+	abi     abi.ABI
+	fetched time.Time
+}
+
+// 合约ABI登记表：缓存合约在链上登记的ABI
+type Registry struct {
+	config *types.Config
+
+	mu    sync.Mutex
+	cache map[string]cachedABI
+}
+
+// 创建合约ABI登记表
+func NewRegistry(config *types.Config) *Registry {
+	return &Registry{
+		config: config,
+		cache:  make(map[string]cachedABI),
+	}
+}
+
+// 查询合约在链上登记的ABI，未上传ABI时返回空列表
+func (r *Registry) ABI(contract string) (abi.ABI, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[contract]
+	r.mu.Unlock()
+	if ok && time.Since(cached.fetched) < abiCacheTTL {
+		return cached.abi, nil
+	}
+
+	raw, err := utils.GetContractABI(r.config, contract)
+	if err != nil {
+		return nil, err
+	}
+	var parsed abi.ABI
+	if raw != nil {
+		if parsed, err = abi.Parse(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[contract] = cachedABI{abi: parsed, fetched: time.Now()}
+	r.mu.Unlock()
+	return parsed, nil
+}
+
+// 解码用的ABI：优先使用调用方提供的ABI，否则使用链上登记的ABI，并补充TRC20/TRC721标准事件
+func (r *Registry) Resolve(contract, supplied string) (abi.ABI, error) {
+	if supplied != "" {
+		return abi.Parse([]byte(supplied))
+	}
+
+	onChain, err := r.ABI(contract)
+	if err != nil {
+		return nil, err
+	}
+	resolved := make(abi.ABI, 0, len(onChain)+len(abi.Standard))
+	resolved = append(resolved, onChain...)
+	return append(resolved, abi.Standard...), nil
+}
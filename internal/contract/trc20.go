@@ -0,0 +1,47 @@
+package contract
+
+import (
+	"math/big"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// TRC20授权相关方法
+var (
+	TRC20Approve           = mustSignature("approve(address spender, uint256 value) returns (bool)")
+	TRC20Allowance         = mustSignature("allowance(address owner, address spender) returns (uint256)")
+	TRC20IncreaseAllowance = mustSignature("increaseAllowance(address spender, uint256 addedValue) returns (bool)")
+	TRC20DecreaseAllowance = mustSignature("decreaseAllowance(address spender, uint256 subtractedValue) returns (bool)")
+	TRC20TransferFrom      = mustSignature("transferFrom(address from, address to, uint256 value) returns (bool)")
+)
+
+// uint256最大值，授权该数额表示无限授权
+var MaxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+func mustSignature(s string) *abi.Entry {
+	e, err := abi.ParseSignature(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// 查询授权额度(代币最小单位)
+func Allowance(config *types.Config, token tron.Address, owner, spender string) (*big.Int, error) {
+	return outputInt(config, &Call{
+		Contract: token,
+		Method:   TRC20Allowance,
+		Args:     []interface{}{owner, spender},
+	})
+}
+
+// 按合约上传的ABI检查是否存在某个方法，未上传ABI时无法判断，返回true
+func (r *Registry) Supports(contract string, method *abi.Entry) (bool, error) {
+	known, err := r.ABI(contract)
+	if err != nil {
+		return false, err
+	}
+	return len(known) == 0 || len(known.Functions(method.Signature())) > 0, nil
+}
@@ -0,0 +1,189 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// TRC721相关方法
+var (
+	TRC721OwnerOf             = mustSignature("ownerOf(uint256 tokenId) returns (address)")
+	TRC721BalanceOf           = mustSignature("balanceOf(address owner) returns (uint256)")
+	TRC721TokenURI            = mustSignature("tokenURI(uint256 tokenId) returns (string)")
+	TRC721TokenOfOwnerByIndex = mustSignature("tokenOfOwnerByIndex(address owner, uint256 index) returns (uint256)")
+	TRC721SafeTransferFrom    = mustSignature("safeTransferFrom(address from, address to, uint256 tokenId)")
+	TRC721SafeTransferData    = mustSignature("safeTransferFrom(address from, address to, uint256 tokenId, bytes data)")
+)
+
+// 列出NFT时单次最多返回的数量
+const (
+	DefaultTokenLimit = 20
+	MaxTokenLimit     = 100
+	tokenWorkers      = 8
+)
+
+// 只读调用并返回第一个返回值，回滚时返回错误
+func output(config *types.Config, call *Call) (interface{}, error) {
+	result, err := Constant(config, call)
+	if err != nil {
+		return nil, err
+	}
+	if result.Reverted {
+		return nil, errors.New("合约执行回滚: " + result.RevertReason)
+	}
+	return result.Outputs["0"], nil
+}
+
+// 只读调用并将第一个返回值解析为整数
+func outputInt(config *types.Config, call *Call) (*big.Int, error) {
+	v, err := output(config, call)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := v.(string)
+	n, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return nil, errors.New("合约返回数据格式错误")
+	}
+	return n, nil
+}
+
+// 解析tokenId：十进制或0x开头的十六进制，返回十进制字符串
+func ParseTokenID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("tokenId不能为空")
+	}
+	n, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		n, ok = n.SetString(s[2:], 16)
+	} else {
+		n, ok = n.SetString(s, 10)
+	}
+	if !ok || n.Sign() < 0 || n.Cmp(MaxUint256) > 0 {
+		return "", errors.New("tokenId格式错误")
+	}
+	return n.String(), nil
+}
+
+// 查询NFT持有地址，token不存在时合约会回滚
+func OwnerOf(config *types.Config, nft tron.Address, tokenID string) (string, error) {
+	v, err := output(config, &Call{Contract: nft, Method: TRC721OwnerOf, Args: []interface{}{tokenID}})
+	if err != nil {
+		return "", err
+	}
+	owner, _ := v.(string)
+	if owner == "" || owner == zeroAddress {
+		return "", errors.New("token不存在")
+	}
+	return owner, nil
+}
+
+// 查询地址持有的NFT数量
+func NFTBalance(config *types.Config, nft tron.Address, owner string) (*big.Int, error) {
+	return outputInt(config, &Call{Contract: nft, Method: TRC721BalanceOf, Args: []interface{}{owner}})
+}
+
+// 查询NFT元数据地址
+func TokenURI(config *types.Config, nft tron.Address, tokenID string) (string, error) {
+	v, err := output(config, &Call{Contract: nft, Method: TRC721TokenURI, Args: []interface{}{tokenID}})
+	if err != nil {
+		return "", err
+	}
+	uri, _ := v.(string)
+	return uri, nil
+}
+
+// 按序号查询地址持有的tokenId，合约需实现TRC721Enumerable
+func TokenOfOwnerByIndex(config *types.Config, nft tron.Address, owner string, index int64) (string, error) {
+	n, err := outputInt(config, &Call{
+		Contract: nft,
+		Method:   TRC721TokenOfOwnerByIndex,
+		Args:     []interface{}{owner, big.NewInt(index).String()},
+	})
+	if err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
+
+// 列出地址持有的NFT(从offset开始最多limit个)，withURI为true时同时查询tokenURI
+func OwnedTokens(config *types.Config, nft tron.Address, owner string, offset, limit int64, withURI bool) (*types.Trc721TokenList, error) {
+	balance, err := NFTBalance(config, nft, owner)
+	if err != nil {
+		return nil, fmt.Errorf("查询NFT数量失败: %v", err)
+	}
+
+	list := &types.Trc721TokenList{
+		Address:  owner,
+		Contract: nft.Base58(),
+		Balance:  balance.String(),
+		Offset:   offset,
+		Tokens:   []types.Trc721Token{},
+	}
+	if !balance.IsInt64() || offset >= balance.Int64() {
+		return list, nil
+	}
+	n := balance.Int64() - offset
+	if n > limit {
+		n = limit
+		list.NextOffset = offset + limit
+	}
+
+	tokens := make([]types.Trc721Token, n)
+	errs := make([]error, n)
+	jobs := make(chan int64)
+	var wg sync.WaitGroup
+	for w := int64(0); w < tokenWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				id, err := TokenOfOwnerByIndex(config, nft, owner, offset+i)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				tokens[i] = types.Trc721Token{TokenID: id}
+				if withURI {
+					// 部分合约未实现tokenURI，查询失败时留空
+					tokens[i].TokenURI, _ = TokenURI(config, nft, id)
+				}
+			}
+		}()
+	}
+	for i := int64(0); i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("查询第%d个NFT失败(合约可能未实现tokenOfOwnerByIndex): %v", offset+int64(i), err)
+		}
+	}
+	list.Tokens = tokens
+	return list, nil
+}
+
+// 构造safeTransferFrom调用，data不为空时使用带data参数的重载
+func SafeTransfer(nft tron.Address, operator, from, to, tokenID, data string) *Call {
+	call := &Call{
+		Contract: nft,
+		Owner:    operator,
+		Method:   TRC721SafeTransferFrom,
+		Args:     []interface{}{from, to, tokenID},
+	}
+	if data != "" {
+		call.Method = TRC721SafeTransferData
+		call.Args = append(call.Args, data)
+	}
+	return call
+}
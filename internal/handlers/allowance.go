@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/contract"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 读取代币合约(默认USDT)及其精度
+func (s *Service) readToken(c *gin.Context) (tron.Address, int, error) {
+	contractStr := param(c, "contract")
+	if contractStr == "" {
+		contractStr = s.Config.ContractAddress
+	}
+	token, err := tron.ParseAddress(contractStr)
+	if err != nil {
+		return token, 0, errors.New("合约地址格式错误")
+	}
+	decimals, err := utils.GetTokenDecimals(s.Config, token.Base58())
+	if err != nil {
+		return token, 0, err
+	}
+	return token, decimals, nil
+}
+
+// 读取地址参数并转为Base58格式
+func readAddress(c *gin.Context, label string, names ...string) (string, error) {
+	v := param(c, names...)
+	if v == "" {
+		return "", fmt.Errorf("%s不能为空", label)
+	}
+	addr, err := tron.ParseAddress(v)
+	if err != nil {
+		return "", fmt.Errorf("%s格式错误", label)
+	}
+	return addr.Base58(), nil
+}
+
+// 解析授权金额：max或unlimited表示无限授权(uint256最大值)，允许为0以取消授权
+func parseApproveAmount(s string, decimals int) (amount.Amount, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return amount.Amount{}, errors.New("授权金额不能为空")
+	case "max", "unlimited":
+		return amount.New(contract.MaxUint256, decimals), nil
+	}
+
+	value, err := amount.Parse(s, decimals)
+	if err != nil {
+		return amount.Amount{}, errors.New("授权金额无效: " + err.Error())
+	}
+	if value.Sign() < 0 {
+		return amount.Amount{}, errors.New("授权金额不能为负数")
+	}
+	return value, nil
+}
+
+// 签名并广播合约调用交易，输出结果中附带金额
+func (s *Service) sendTokenCall(c *gin.Context, sg *signer, call *contract.Call, value amount.Amount, msg string) {
+	tx, err := contract.Transaction(s.Config, call, 0, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, sg.key, sg.multiSign)
+	if err != nil {
+		respondErrorData(c, "交易失败: "+err.Error(), types.TransactionResponse{TxID: tx.TxID, TxId: tx.TxID})
+		return
+	}
+	setTransferAmount(result, value)
+	respondTransfer(c, msg, result, sg.multiSign)
+}
+
+// 授权spender从当前账户转出TRC20代币(approve)
+func (s *Service) ApproveTrc20Handler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	spender, err := readAddress(c, "被授权地址", "spender")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	token, decimals, err := s.readToken(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	value, err := parseApproveAmount(param(c, "amount"), decimals)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	s.sendTokenCall(c, sg, &contract.Call{
+		Contract: token,
+		Owner:    sg.owner,
+		Method:   contract.TRC20Approve,
+		Args:     []interface{}{spender, value.UnitsString()},
+	}, value, "授权成功")
+}
+
+// 查询授权额度(allowance)
+func (s *Service) GetTrc20AllowanceHandler(c *gin.Context) {
+	owner, err := readAddress(c, "授权地址", "owner", "address")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	spender, err := readAddress(c, "被授权地址", "spender")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	token, decimals, err := s.readToken(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	units, err := contract.Allowance(s.Config, token, owner, spender)
+	if err != nil {
+		respondError(c, "查询授权额度失败: "+err.Error())
+		return
+	}
+
+	value := amount.New(units, decimals)
+	respondSuccess(c, "授权额度查询成功", types.AllowanceResponse{
+		Owner:          owner,
+		Spender:        spender,
+		Token:          token.Base58(),
+		Allowance:      value.String(),
+		AllowanceUnits: value.UnitsString(),
+		Decimals:       decimals,
+		Unlimited:      units.Cmp(contract.MaxUint256) == 0,
+	})
+}
+
+// 增加授权额度(increaseAllowance)，仅部分合约支持
+func (s *Service) IncreaseAllowanceHandler(c *gin.Context) {
+	s.adjustAllowance(c, contract.TRC20IncreaseAllowance, "授权额度已增加")
+}
+
+// 减少授权额度(decreaseAllowance)，仅部分合约支持
+func (s *Service) DecreaseAllowanceHandler(c *gin.Context) {
+	s.adjustAllowance(c, contract.TRC20DecreaseAllowance, "授权额度已减少")
+}
+
+func (s *Service) adjustAllowance(c *gin.Context, method *abi.Entry, msg string) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	spender, err := readAddress(c, "被授权地址", "spender")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	token, decimals, err := s.readToken(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	value, err := parsePositiveAmount(param(c, "amount"), decimals)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	supported, err := s.Contracts.Supports(token.Base58(), method)
+	if err != nil {
+		respondError(c, "查询合约ABI失败: "+err.Error())
+		return
+	}
+	if !supported {
+		respondError(c, fmt.Sprintf("合约不支持%s，请使用approve", method.Name))
+		return
+	}
+
+	call := &contract.Call{
+		Contract: token,
+		Owner:    sg.owner,
+		Method:   method,
+		Args:     []interface{}{spender, value.UnitsString()},
+	}
+
+	// 先模拟执行：合约未实现该方法或额度不足时会回滚，避免广播后失败扣除手续费
+	sim, err := contract.Constant(s.Config, call)
+	if err != nil {
+		respondError(c, "模拟执行失败: "+err.Error())
+		return
+	}
+	if sim.Reverted {
+		respondError(c, fmt.Sprintf("合约执行回滚(合约可能不支持%s): %s", method.Name, sim.RevertReason))
+		return
+	}
+
+	s.sendTokenCall(c, sg, call, value, msg)
+}
+
+// 使用授权额度从from转出TRC20代币到to(transferFrom)，key为被授权地址的私钥
+func (s *Service) TransferFromTrc20Handler(c *gin.Context) {
+	keyHex := param(c, "key", "privateKey")
+	if keyHex == "" {
+		respondError(c, "私钥不能为空")
+		return
+	}
+	key, err := tron.ParsePrivateKey(keyHex)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	permissionID, multiSign, err := parsePermissionID(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	// 被授权地址默认为私钥对应地址，多签时由spender指定
+	sg := &signer{key: key, owner: key.Address().Base58(), permissionID: permissionID, multiSign: multiSign}
+	if param(c, "spender") != "" {
+		if sg.owner, err = readAddress(c, "被授权地址", "spender"); err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		if sg.owner != key.Address().Base58() && !multiSign {
+			respondError(c, "被授权地址与私钥地址不一致，代多签账户签名时需传入permissionId")
+			return
+		}
+	}
+
+	from, err := readAddress(c, "转出地址", "from")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	to, err := readAddress(c, "接收地址", "to")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	token, decimals, err := s.readToken(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	value, err := parsePositiveAmount(param(c, "amount"), decimals)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	// 广播前检查授权额度和余额，避免交易失败扣除手续费
+	allowance, err := contract.Allowance(s.Config, token, from, sg.owner)
+	if err != nil {
+		respondError(c, "查询授权额度失败: "+err.Error())
+		return
+	}
+	if allowance.Cmp(value.Units()) < 0 {
+		respondError(c, fmt.Sprintf("授权额度不足：剩余%s", amount.New(allowance, decimals)))
+		return
+	}
+	balance, err := utils.GetTrc20BalanceRaw(s.Config, from, token.Base58())
+	if err != nil {
+		respondError(c, "查询余额失败: "+err.Error())
+		return
+	}
+	if balance.Cmp(value.Units()) < 0 {
+		respondError(c, fmt.Sprintf("转出地址余额不足：剩余%s", amount.New(balance, decimals)))
+		return
+	}
+
+	s.sendTokenCall(c, sg, &contract.Call{
+		Contract: token,
+		Owner:    sg.owner,
+		Method:   contract.TRC20TransferFrom,
+		Args:     []interface{}{from, to, value.UnitsString()},
+	}, value, "TRC20授权转账成功")
+}
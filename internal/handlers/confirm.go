@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/network"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 等待交易的默认和最大超时时间
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 120 * time.Second
+)
+
+// 查询交易详情，并附带确认状态和确认数
+func (s *Service) GetTransactionHandler(c *gin.Context) {
+	txID := param(c, "txID", "txid")
+	if txID == "" {
+		respondError(c, "交易ID不能为空")
+		return
+	}
+
+	st, err := s.Tracker.Status(txID)
+	if err != nil {
+		respondError(c, "查询交易状态失败: "+err.Error())
+		return
+	}
+
+	tx, err := utils.GetTransactionByID(s.Config, txID)
+	if err != nil {
+		respondError(c, "查询交易失败: "+err.Error())
+		return
+	}
+	if tx == nil {
+		if st.Status == confirm.StatusNotFound {
+			respondErrorData(c, "交易不存在", st)
+			return
+		}
+		tx = map[string]interface{}{"txID": txID}
+	}
+
+	tx["status"] = st.Status
+	tx["confirmations"] = st.Confirmations
+	tx["confirmation"] = st
+	if link := network.ExplorerLink(s.Config, txID); link != "" {
+		tx["explorer"] = link
+	}
+	respondSuccess(c, "交易查询成功", tx)
+}
+
+// 长轮询等待交易上链(until=in_block)或固化(until=solidified)
+// 传入已签名交易时会先广播，并在过期前自动重新广播
+func (s *Service) WaitForTransactionHandler(c *gin.Context) {
+	txID := param(c, "txID", "txid")
+
+	if param(c, "transaction") != "" || (txID == "" && strings.HasPrefix(c.ContentType(), "application/json")) {
+		tx, err := readTransaction(c)
+		if err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		if txID != "" && txID != tx.TxID {
+			respondError(c, "交易ID与交易数据不一致")
+			return
+		}
+		if err := s.Tracker.Broadcast(tx); err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		txID = tx.TxID
+	}
+	if txID == "" {
+		respondError(c, "交易ID不能为空")
+		return
+	}
+
+	until := param(c, "until")
+	switch until {
+	case "":
+		until = confirm.StatusInBlock
+	case confirm.StatusInBlock, confirm.StatusSolidified:
+	default:
+		respondError(c, "until参数只能为in_block或solidified")
+		return
+	}
+
+	timeout := defaultWaitTimeout
+	if v := param(c, "timeout"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			respondError(c, "timeout必须为正整数(秒)")
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+		if timeout > maxWaitTimeout {
+			timeout = maxWaitTimeout
+		}
+	}
+
+	st, err := s.Tracker.Wait(c.Request.Context(), txID, until, timeout)
+	if err != nil {
+		respondError(c, "查询交易状态失败: "+err.Error())
+		return
+	}
+
+	switch {
+	case st.TimedOut:
+		respondErrorData(c, "等待超时，交易当前状态: "+st.Status, st)
+	case st.Status == confirm.StatusFailed:
+		respondErrorData(c, "交易执行失败", st)
+	case st.Status == confirm.StatusExpired:
+		respondErrorData(c, "交易已过期未上链", st)
+	default:
+		respondSuccess(c, "交易已确认", st)
+	}
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/contract"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 查询合约事件日志：contract默认为配置的代币合约，event可为事件名、签名或主题哈希，
+// abi为空时使用合约在链上登记的ABI及TRC20/TRC721标准事件解码
+func (s *Service) GetContractEventsHandler(c *gin.Context) {
+	contractStr := param(c, "contract", "contractAddress")
+	if contractStr == "" {
+		contractStr = s.Config.ContractAddress
+	}
+	addr, err := tron.ParseAddress(contractStr)
+	if err != nil {
+		respondError(c, "合约地址格式错误")
+		return
+	}
+
+	query := contract.EventQuery{Contract: addr, Event: param(c, "event")}
+	for name, target := range map[string]*int64{"fromBlock": &query.FromBlock, "toBlock": &query.ToBlock} {
+		v := param(c, name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			respondError(c, fmt.Sprintf("%s必须为正整数", name))
+			return
+		}
+		*target = n
+	}
+
+	query.ABI, err = s.Contracts.Resolve(addr.Base58(), param(c, "abi"))
+	if err != nil {
+		respondError(c, "获取合约ABI失败: "+err.Error())
+		return
+	}
+
+	result, err := contract.Events(s.Config, query)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "合约事件查询成功", result)
+}
+
+// 读取合约调用请求，支持JSON请求体或表单参数(args、abi为JSON字符串)
+func readContractCall(c *gin.Context) (*types.ContractCallRequest, error) {
+	var req types.ContractCallRequest
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, errors.New("请求数据格式错误")
+		}
+	}
+
+	fill := func(target *string, names ...string) {
+		if *target == "" {
+			*target = param(c, names...)
+		}
+	}
+	fill(&req.Contract, "contract", "contractAddress")
+	fill(&req.Function, "function", "method")
+	fill(&req.Returns, "returns")
+	fill(&req.From, "from", "owner")
+	fill(&req.Key, "key", "privateKey")
+	fill(&req.CallValue, "callValue")
+	fill(&req.FeeLimit, "feeLimit")
+	if len(req.Args) == 0 {
+		if v := param(c, "args"); v != "" {
+			req.Args = json.RawMessage(v)
+		}
+	}
+	if len(req.ABI) == 0 {
+		if v := param(c, "abi"); v != "" {
+			req.ABI = json.RawMessage(v)
+		}
+	}
+	if req.PermissionID == nil {
+		if v := param(c, "permissionId", "Permission_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil || id < 0 {
+				return nil, errors.New("permissionId格式错误")
+			}
+			req.PermissionID = &id
+		}
+	}
+
+	if req.Contract == "" || req.Function == "" {
+		return nil, errors.New("参数不完整：需要合约地址和函数")
+	}
+	return &req, nil
+}
+
+// JSON请求体中的ABI可以是数组，也可以是JSON字符串
+func abiString(raw json.RawMessage) (string, error) {
+	supplied := string(raw)
+	if strings.HasPrefix(supplied, "\"") {
+		if err := json.Unmarshal(raw, &supplied); err != nil {
+			return "", errors.New("ABI格式错误")
+		}
+	}
+	return supplied, nil
+}
+
+// 解析调用的合约、函数和参数
+func (s *Service) prepareCall(req *types.ContractCallRequest) (*contract.Call, error) {
+	addr, err := tron.ParseAddress(req.Contract)
+	if err != nil {
+		return nil, errors.New("合约地址格式错误")
+	}
+
+	supplied, err := abiString(req.ABI)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := s.Contracts.Method(addr.Base58(), req.Function, supplied, req.Returns, contract.ArgCount(req.Args))
+	if err != nil {
+		return nil, err
+	}
+	args, err := contract.ParseArgs(method, req.Args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != len(method.Inputs) {
+		return nil, fmt.Errorf("函数%s需要%d个参数，实际%d个", method.Signature(), len(method.Inputs), len(args))
+	}
+
+	call := &contract.Call{Contract: addr, Method: method, Args: args}
+	if req.From != "" {
+		from, err := tron.ParseAddress(req.From)
+		if err != nil {
+			return nil, errors.New("调用地址格式错误")
+		}
+		call.Owner = from.Base58()
+	}
+	if req.CallValue != "" {
+		if call.CallValue, err = parseTrxAmount(req.CallValue); err != nil {
+			return nil, errors.New("callValue无效")
+		}
+	}
+	return call, nil
+}
+
+// 调用合约只读方法(triggerconstantcontract)并解码返回值
+func (s *Service) CallContractHandler(c *gin.Context) {
+	req, err := readContractCall(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	call, err := s.prepareCall(req)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	result, err := contract.Constant(s.Config, call)
+	if err != nil {
+		respondError(c, "合约调用失败: "+err.Error())
+		return
+	}
+	if result.Reverted {
+		respondErrorData(c, "合约执行回滚: "+result.RevertReason, result)
+		return
+	}
+	respondSuccess(c, "合约调用成功", result)
+}
+
+// 调用合约状态变更方法：构建交易、签名并广播
+func (s *Service) TriggerContractHandler(c *gin.Context) {
+	req, err := readContractCall(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	if req.Key == "" {
+		respondError(c, "私钥不能为空")
+		return
+	}
+	sg, err := newSigner(req.Key, req.From, req.PermissionID)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	call, err := s.prepareCall(req)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	call.Owner = sg.owner
+
+	var feeLimit int64
+	if req.FeeLimit != "" {
+		if feeLimit, err = parseTrxAmount(req.FeeLimit); err != nil {
+			respondError(c, "feeLimit无效")
+			return
+		}
+	}
+
+	tx, err := contract.Transaction(s.Config, call, feeLimit, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "合约调用交易已广播")
+}
+
+// 部署合约时默认等待上链的时间
+const defaultDeployTimeout = 60 * time.Second
+
+// 读取合约部署请求，支持JSON请求体或表单参数
+func readDeployRequest(c *gin.Context) (*types.DeployContractRequest, error) {
+	var req types.DeployContractRequest
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, errors.New("请求数据格式错误")
+		}
+	}
+
+	fill := func(target *string, names ...string) {
+		if *target == "" {
+			*target = param(c, names...)
+		}
+	}
+	fill(&req.Name, "name")
+	fill(&req.Bytecode, "bytecode")
+	fill(&req.From, "from", "owner")
+	fill(&req.Key, "key", "privateKey")
+	fill(&req.CallValue, "callValue")
+	fill(&req.FeeLimit, "feeLimit")
+	for target, name := range map[*json.RawMessage]string{&req.ABI: "abi", &req.Args: "args"} {
+		if len(*target) == 0 {
+			if v := param(c, name); v != "" {
+				*target = json.RawMessage(v)
+			}
+		}
+	}
+
+	ints := []struct {
+		name   string
+		target **int
+	}{{"permissionId", &req.PermissionID}, {"consumeUserResourcePercent", &req.ConsumeUserResourcePercent}}
+	for _, f := range ints {
+		if *f.target != nil {
+			continue
+		}
+		if v := param(c, f.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s格式错误", f.name)
+			}
+			*f.target = &n
+		}
+	}
+	if req.OriginEnergyLimit == nil {
+		if v := param(c, "originEnergyLimit"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n <= 0 {
+				return nil, errors.New("originEnergyLimit必须为正整数")
+			}
+			req.OriginEnergyLimit = &n
+		}
+	}
+	if req.Timeout == 0 {
+		if v := param(c, "timeout"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, errors.New("timeout必须为正整数(秒)")
+			}
+			req.Timeout = n
+		}
+	}
+
+	if req.Bytecode == "" || req.Key == "" {
+		return nil, errors.New("参数不完整：需要字节码和私钥")
+	}
+	return &req, nil
+}
+
+// 部署智能合约：构建CreateSmartContract交易，签名广播后等待上链并返回合约地址
+func (s *Service) DeployContractHandler(c *gin.Context) {
+	req, err := readDeployRequest(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	sg, err := newSigner(req.Key, req.From, req.PermissionID)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	abiJSON, err := abiString(req.ABI)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	deployment := &contract.Deployment{
+		Owner:                      sg.owner,
+		Name:                       req.Name,
+		ABI:                        abiJSON,
+		Bytecode:                   req.Bytecode,
+		Args:                       req.Args,
+		ConsumeUserResourcePercent: 100,
+		OriginEnergyLimit:          contract.DefaultOriginEnergyLimit,
+	}
+	if req.ConsumeUserResourcePercent != nil {
+		deployment.ConsumeUserResourcePercent = *req.ConsumeUserResourcePercent
+	}
+	if req.OriginEnergyLimit != nil {
+		deployment.OriginEnergyLimit = *req.OriginEnergyLimit
+	}
+	if req.CallValue != "" {
+		if deployment.CallValue, err = parseTrxAmount(req.CallValue); err != nil {
+			respondError(c, "callValue无效")
+			return
+		}
+	}
+	if req.FeeLimit != "" {
+		if deployment.FeeLimit, err = parseTrxAmount(req.FeeLimit); err != nil {
+			respondError(c, "feeLimit无效")
+			return
+		}
+	}
+
+	timeout := defaultDeployTimeout
+	if req.Timeout > 0 {
+		timeout = time.Duration(req.Timeout) * time.Second
+		if timeout > maxWaitTimeout {
+			timeout = maxWaitTimeout
+		}
+	}
+
+	tx, address, err := contract.DeployTransaction(s.Config, deployment, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, sg.key, sg.multiSign)
+	if err != nil {
+		respondErrorData(c, "部署失败: "+err.Error(), types.DeployContractResponse{TxID: tx.TxID, ContractAddress: address})
+		return
+	}
+
+	resp := types.DeployContractResponse{
+		TxID:            tx.TxID,
+		ContractAddress: address,
+		Broadcast:       result.Broadcast,
+		SignWeight:      result.SignWeight,
+		Transaction:     result.Transaction,
+	}
+	if !result.Broadcast {
+		respondSuccess(c, "签名成功，等待其他签名", resp)
+		return
+	}
+
+	st, err := s.Tracker.Wait(c.Request.Context(), tx.TxID, confirm.StatusInBlock, timeout)
+	if err != nil {
+		respondErrorData(c, "交易已广播，查询状态失败: "+err.Error(), resp)
+		return
+	}
+	resp.Status = st
+
+	switch {
+	case st.TimedOut:
+		respondErrorData(c, "交易已广播，等待上链超时，请稍后根据txID查询", resp)
+	case st.Status == confirm.StatusFailed:
+		respondErrorData(c, "合约部署失败: "+st.Result, resp)
+	case st.Status == confirm.StatusExpired:
+		respondErrorData(c, "交易已过期未上链", resp)
+	default:
+		respondSuccess(c, "合约部署成功", resp)
+	}
+}
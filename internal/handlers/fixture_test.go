@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// 测试用的随机账户
+func newTestKey(t *testing.T) *tron.PrivateKey {
+	t.Helper()
+	key, err := tron.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// 按节点格式构建TRX转账交易(/wallet/createtransaction的响应)
+func transferTx(t *testing.T, owner, to tron.Address, sun int64, permissionID int) map[string]interface{} {
+	t.Helper()
+	var value tron.Encoder
+	value.Bytes(1, owner[:])
+	value.Bytes(2, to[:])
+	value.Varint(3, uint64(sun))
+
+	now := time.Now().UnixMilli()
+	raw := tron.RawData{
+		RefBlockBytes: []byte{0x12, 0x34},
+		RefBlockHash:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Expiration:    now + 60000,
+		Timestamp:     now,
+		Contracts: []tron.RawContract{{
+			Type:         tron.ContractTypes["TransferContract"],
+			TypeURL:      "type.googleapis.com/protocol.TransferContract",
+			Value:        value.Result(),
+			PermissionID: permissionID,
+		}},
+	}
+	rawHex := hex.EncodeToString(raw.Marshal())
+	txID, err := tron.TxIDFromRawData(rawHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]interface{}{"visible": true, "txID": txID, "raw_data_hex": rawHex}
+}
+
+// ABI编码的uint256返回值
+func uint256Result(v int64) string {
+	return fmt.Sprintf("%064x", v)
+}
+
+func TestTrxBalanceFixture(t *testing.T) {
+	funded, empty := newTestKey(t).Address(), newTestKey(t).Address()
+	f := chain.NewFixture()
+	f.SetAccount(funded.Base58(), chain.FixtureAccount{Balance: 1500000})
+	s := newTestService(t, f)
+
+	tests := []struct {
+		name    string
+		address string
+		want    tron.Address
+		balance string
+	}{
+		{"funded", funded.Base58(), funded, "1.500000"},
+		{"hex address", funded.Hex(), funded, "1.500000"},
+		{"not activated", empty.Base58(), empty, "0.000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data types.BalanceResponse
+			mustSucceed(t, call(t, s, (*Service).GetTrxBalanceHandler, url.Values{"address": {tt.address}}), &data)
+			if data.Address != tt.want.Base58() || data.Balance != tt.balance || data.Token != "TRX" {
+				t.Fatalf("balance = %+v, want %s TRX for %s", data, tt.balance, tt.want.Base58())
+			}
+		})
+	}
+
+	mustFail(t, call(t, s, (*Service).GetTrxBalanceHandler, url.Values{"address": {"TInvalid"}}), "地址格式错误")
+}
+
+func TestTrc20BalanceFixture(t *testing.T) {
+	holder := newTestKey(t).Address()
+	token := newTestKey(t).Address() // 非默认合约，需查询精度
+	f := chain.NewFixture()
+	f.Handle("/wallet/triggerconstantcontract", map[string]interface{}{"function_selector": "decimals()"},
+		map[string]interface{}{"result": map[string]interface{}{"result": true}, "constant_result": []string{uint256Result(18)}})
+	f.Handle("/wallet/triggerconstantcontract", map[string]interface{}{"function_selector": "balanceOf(address)"},
+		map[string]interface{}{"result": map[string]interface{}{"result": true}, "constant_result": []string{uint256Result(2500000000000000000)}})
+	s := newTestService(t, f)
+
+	var data types.BalanceResponse
+	mustSucceed(t, call(t, s, (*Service).GetTrc20BalanceHandler, url.Values{
+		"address":  {holder.Base58()},
+		"contract": {token.Base58()},
+	}), &data)
+	if data.Balance != "2.500000000000000000" || data.Decimals != 18 || data.Token != token.Base58() {
+		t.Fatalf("balance = %+v", data)
+	}
+}
+
+func TestSendTrxFixture(t *testing.T) {
+	key := newTestKey(t)
+	owner, to, other := key.Address(), newTestKey(t).Address(), newTestKey(t).Address()
+	accepted := map[string]interface{}{"result": true}
+
+	tests := []struct {
+		name      string
+		form      url.Values
+		built     map[string]interface{} // 节点构建的交易，nil表示不应请求节点
+		broadcast map[string]interface{}
+		wantErr   string // 为空表示应成功
+	}{
+		{
+			name:      "success",
+			built:     transferTx(t, owner, to, 1500000, 0),
+			broadcast: accepted,
+		},
+		{
+			name:      "node changes recipient",
+			built:     transferTx(t, owner, other, 1500000, 0),
+			broadcast: accepted,
+			wantErr:   "节点返回的交易与请求不一致",
+		},
+		{
+			name:      "node changes amount",
+			built:     transferTx(t, owner, to, 15000000, 0),
+			broadcast: accepted,
+			wantErr:   "节点返回的交易与请求不一致",
+		},
+		{
+			name:      "node adds permission",
+			built:     transferTx(t, owner, to, 1500000, 2),
+			broadcast: accepted,
+			wantErr:   "节点返回的交易与请求不一致",
+		},
+		{
+			name:  "broadcast rejected",
+			built: transferTx(t, owner, to, 1500000, 0),
+			broadcast: map[string]interface{}{
+				"result":  false,
+				"code":    "SIGERROR",
+				"message": hex.EncodeToString([]byte("validate signature error")),
+			},
+			wantErr: "SIGERROR",
+		},
+		{
+			name:    "from differs without permissionId",
+			form:    url.Values{"from": {other.Base58()}},
+			wantErr: "发送地址与私钥地址不一致",
+		},
+		{
+			name:    "excess precision",
+			form:    url.Values{"amount": {"1.0000001"}},
+			wantErr: "金额小数位数不能超过6位",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := chain.NewFixture()
+			if tt.built != nil {
+				f.Handle("/wallet/createtransaction", map[string]interface{}{
+					"owner_address": owner.Base58(),
+					"to_address":    to.Base58(),
+					"amount":        1500000,
+				}, tt.built)
+				f.Handle("/wallet/broadcasttransaction", nil, tt.broadcast)
+			}
+			s := newTestService(t, f)
+
+			form := url.Values{"to": {to.Base58()}, "amount": {"1.5"}, "key": {key.Hex()}}
+			for k, v := range tt.form {
+				form[k] = v
+			}
+			resp := call(t, s, (*Service).SendTrxHandler, form)
+			if tt.wantErr != "" {
+				mustFail(t, resp, tt.wantErr)
+				return
+			}
+
+			var data types.TransactionResponse
+			mustSucceed(t, resp, &data)
+			if !data.Result || data.TxID != tt.built["txID"] || data.AmountUnits != "1500000" {
+				t.Fatalf("data = %+v", data)
+			}
+		})
+	}
+}
@@ -1,27 +1,160 @@
 package handlers
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/blocks"
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/contract"
+	"tron-api-go/internal/idempotency"
+	"tron-api-go/internal/payout"
+	"tron-api-go/internal/queue"
+	"tron-api-go/internal/stream"
+	"tron-api-go/internal/sweep"
+	"tron-api-go/internal/tron"
 	"tron-api-go/internal/types"
 	"tron-api-go/internal/utils"
+	"tron-api-go/internal/watch"
 
 	"github.com/gin-gonic/gin"
 )
 
 // 处理器服务结构体
 type Service struct {
-	Config *types.Config
+	Config      *types.Config
+	Chain       types.ChainClient
+	Sweeper     *sweep.Manager
+	Payouts     *payout.Manager
+	Idempotency *idempotency.Store
+	Tracker     *confirm.Tracker
+	Queue       *queue.Manager
+	Watcher     *watch.Watcher
+	Streams     *stream.Hub
+	Contracts   *contract.Registry
+
+	// 最新的配置快照，热加载时整体替换而不修改已发布的配置；请求开始时复制快照，
+	// 后台组件持有创建时的配置，节点地址等变化随链客户端替换生效
+	current *atomic.Pointer[types.Config]
 }
 
 // 创建新的处理器服务
 func NewService(config *types.Config) *Service {
+	// 未指定链后端时按TronAPIURL使用TronGrid，后续组件共用同一个客户端
+	if config.Chain == nil {
+		config.Chain = utils.Chain(config)
+	}
+
+	current := new(atomic.Pointer[types.Config])
+	current.Store(config)
 	return &Service{
-		Config: config,
+		current:     current,
+		Config:      config,
+		Chain:       config.Chain,
+		Sweeper:     sweep.NewManager(config),
+		Payouts:     payout.NewManager(config),
+		Idempotency: idempotency.NewStore(config),
+		Tracker:     confirm.NewTracker(config),
+		Queue:       queue.NewManager(config),
+		Watcher:     watch.NewWatcher(config),
+		Streams:     stream.NewHub(config),
+		Contracts:   contract.NewRegistry(config),
+	}
+}
+
+// 绑定请求上下文的服务副本：链客户端随请求取消或超时中止，后台组件仍共用原服务的实例
+func (s *Service) withContext(ctx context.Context) *Service {
+	config := *s.current.Load()
+	config.Chain = chain.WithContext(s.Chain, ctx)
+	scoped := *s
+	scoped.Config, scoped.Chain = &config, config.Chain
+	return &scoped
+}
+
+// 读取请求参数，依次尝试Query和PostForm中的各个参数名
+func param(c *gin.Context, names ...string) string {
+	for _, name := range names {
+		if v := c.Query(name); v != "" {
+			return v
+		}
+	}
+	for _, name := range names {
+		if v := c.PostForm(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// 返回失败响应
+func respondError(c *gin.Context, msg string) {
+	c.JSON(http.StatusOK, types.APIResponse{
+		Code: 0,
+		Msg:  msg,
+		Data: nil,
+		Time: time.Now().Unix(),
+	})
+}
+
+// 返回带数据的失败响应(如逐行校验结果)
+func respondErrorData(c *gin.Context, msg string, data interface{}) {
+	c.JSON(http.StatusOK, types.APIResponse{
+		Code: 0,
+		Msg:  msg,
+		Data: data,
+		Time: time.Now().Unix(),
+	})
+}
+
+// 返回成功响应
+func respondSuccess(c *gin.Context, msg string, data interface{}) {
+	c.JSON(http.StatusOK, types.APIResponse{
+		Code: 1,
+		Msg:  msg,
+		Data: data,
+		Time: time.Now().Unix(),
+	})
+}
+
+// 按精度解析大于0的金额，小数位数超过精度时返回错误
+func parsePositiveAmount(amountStr string, decimals int) (amount.Amount, error) {
+	value, err := amount.Parse(amountStr, decimals)
+	if err != nil {
+		return amount.Amount{}, errors.New("转账金额无效: " + err.Error())
+	}
+	if value.Sign() <= 0 {
+		return amount.Amount{}, errors.New("转账金额必须大于0")
+	}
+	return value, nil
+}
+
+// 解析TRX金额并转换为SUN (1 TRX = 1,000,000 SUN)
+func parseTrxAmount(amountStr string) (int64, error) {
+	value, err := parsePositiveAmount(amountStr, amount.TrxDecimals)
+	if err != nil {
+		return 0, err
+	}
+	sun, ok := value.Int64()
+	if !ok {
+		return 0, errors.New("转账金额过大")
+	}
+	return sun, nil
+}
+
+// 生成余额响应
+func balanceResponse(address, token string, balance amount.Amount) types.BalanceResponse {
+	return types.BalanceResponse{
+		Balance:      balance.String(),
+		BalanceUnits: balance.UnitsString(),
+		Decimals:     balance.Decimals(),
+		Token:        token,
+		Address:      address,
 	}
 }
 
@@ -58,15 +191,29 @@ func (s *Service) DocsHandler(c *gin.Context) {
 
 // API状态检查
 func (s *Service) StatusHandler(c *gin.Context) {
+	data := map[string]interface{}{
+		"version":   "3.0-Go",
+		"language":  "Go",
+		"backend":   s.Chain.Name(),
+		"network":   s.Config.Network,
+		"timestamp": time.Now().Unix(),
+		"date":      time.Now().Format("2006-01-02 15:04:05"),
+	}
+	// 配置了TronGrid API Key时返回各Key的使用统计
+	if k, ok := s.Chain.(chain.KeyReporter); ok {
+		if stats := k.APIKeyStats(); len(stats) > 0 {
+			data["api_keys"] = stats
+		}
+	}
+	if u, ok := s.Chain.(chain.UpstreamReporter); ok {
+		if stats := u.UpstreamStats(); len(stats) > 0 {
+			data["upstreams"] = stats
+		}
+	}
 	response := types.APIResponse{
 		Code: 1,
 		Msg:  "TRON API服务运行正常",
-		Data: map[string]interface{}{
-			"version":   "3.0-Go",
-			"language":  "Go",
-			"timestamp": time.Now().Unix(),
-			"date":      time.Now().Format("2006-01-02 15:04:05"),
-		},
+		Data: data,
 		Time: time.Now().Unix(),
 	}
 
@@ -95,17 +242,94 @@ func (s *Service) GetApiListHandler(c *gin.Context) {
 			"sendTrc20": "TRC20代币转账",
 			"sendTrc10": "TRC10代币转账",
 		},
+		"TRC20授权": map[string]string{
+			"approveTrc20":           "授权其他地址转出代币",
+			"getTrc20Allowance":      "查询授权额度",
+			"increaseTrc20Allowance": "增加授权额度(合约需支持)",
+			"decreaseTrc20Allowance": "减少授权额度(合约需支持)",
+			"transferFromTrc20":      "使用授权额度转出代币",
+		},
+		"TRC721 NFT": map[string]string{
+			"getTrc721Owner":               "查询NFT持有地址",
+			"getTrc721Balance":             "查询地址持有的NFT数量",
+			"getTrc721TokenURI":            "查询NFT元数据地址",
+			"getTrc721TokenOfOwnerByIndex": "按序号查询地址持有的tokenId",
+			"listTrc721Tokens":             "列出地址持有的NFT",
+			"sendTrc721":                   "转移NFT(safeTransferFrom)",
+		},
+		"批量付款": map[string]string{
+			"batchPayout":       "批量付款(CSV或JSON，支持dryRun校验)",
+			"getBatchPayout":    "查询付款批次及每行状态",
+			"listBatchPayouts":  "查询付款批次列表",
+			"resumeBatchPayout": "提交私钥继续已中断的批次",
+		},
+		"账户权限": map[string]string{
+			"getAccountPermission":    "查询账户权限",
+			"updateAccountPermission": "更新账户权限(支持预览)",
+		},
+		"质押资源": map[string]string{
+			"freezeBalanceV2":        "质押TRX获取能量或带宽",
+			"unfreezeBalanceV2":      "解锁质押的TRX",
+			"withdrawExpireUnfreeze": "提取已到期的解锁金额",
+			"cancelAllUnfreezeV2":    "取消全部未到期的解锁",
+			"getUnfreezeInfo":        "查询质押及待提取解锁",
+		},
+		"资源代理": map[string]string{
+			"delegateResource":       "代理能量或带宽给其他地址",
+			"unDelegateResource":     "取消资源代理",
+			"getDelegatedResource":   "查询地址的资源代理情况",
+			"getCanDelegatedMaxSize": "查询可代理的资源上限",
+		},
+		"资金归集": map[string]string{
+			"createSweepJob": "创建归集任务(支持dryRun预览)",
+			"getSweepJob":    "查询归集任务及每个地址结果",
+			"listSweepJobs":  "查询归集任务列表",
+			"pauseSweepJob":  "暂停归集任务",
+			"resumeSweepJob": "继续归集任务",
+		},
+		"发送队列": map[string]string{
+			"queue":        "查询发送队列任务列表",
+			"queue/get":    "查询队列任务详情",
+			"queue/cancel": "取消队列任务",
+			"queue/resume": "重新提供私钥继续队列任务",
+		},
+		"充值监控": map[string]string{
+			"watch":            "查询充值监控状态和地址",
+			"watch/add":        "注册充值监控地址和Webhook",
+			"watch/remove":     "删除充值监控地址",
+			"watch/deliveries": "查询Webhook投递记录",
+			"watch/redeliver":  "重新投递失败的通知",
+		},
+		"多签交易": map[string]string{
+			"addSignature":         "为多签交易追加签名，达到阈值后自动广播",
+			"getSignWeight":        "查询交易签名权重",
+			"getApprovedList":      "查询已签名地址列表",
+			"broadcastTransaction": "广播已签名交易",
+		},
 		"交易查询": map[string]string{
-			"getTransaction":             "查询交易详情",
+			"getTransaction":             "查询交易详情及确认状态",
+			"waitForTransaction":         "等待交易上链或固化",
 			"getTrc20TransactionReceipt": "查询TRC20交易回执",
+			"getTransactionHistory":      "查询地址的转账记录(分页)",
+		},
+		"智能合约": map[string]string{
+			"getContractEvents": "查询合约事件日志并按ABI解码",
+			"callContract":      "调用合约只读方法并解码返回值",
+			"triggerContract":   "调用合约方法(签名并广播交易)",
+			"deployContract":    "部署合约并返回合约地址",
 		},
 		"区块链信息": map[string]string{
 			"getBlockHeight":   "获取区块高度",
 			"getBlockByNumber": "根据区块号查询区块",
 		},
+		"实时推送": map[string]string{
+			"stream/blocks":    "推送新区块(SSE/WebSocket)",
+			"stream/transfers": "推送转账，可按地址和合约筛选(SSE/WebSocket)",
+		},
 		"工具接口": map[string]string{
-			"status":     "API状态检查",
-			"getApiList": "获取接口列表",
+			"status":      "API状态检查",
+			"getApiList":  "获取接口列表",
+			"getNetworks": "查询可用网络(所有接口可用network参数指定网络)",
 		},
 	}
 
@@ -122,8 +346,7 @@ func (s *Service) GetApiListHandler(c *gin.Context) {
 // 生成TRON地址
 func (s *Service) CreateAddressHandler(c *gin.Context) {
 	// 生成随机私钥
-	privateKeyBytes := make([]byte, 32)
-	_, err := rand.Read(privateKeyBytes)
+	key, err := tron.GeneratePrivateKey()
 	if err != nil {
 		c.JSON(http.StatusOK, types.APIResponse{
 			Code: 0,
@@ -134,19 +357,15 @@ func (s *Service) CreateAddressHandler(c *gin.Context) {
 		return
 	}
 
-	privateKeyHex := hex.EncodeToString(privateKeyBytes)
-
-	// 生成模拟的TRON地址
-	tronAddress := utils.GenerateTronAddress()
-	addressHex := "41" + tronAddress[1:]
+	address := key.Address()
 
 	response := types.APIResponse{
 		Code: 1,
 		Msg:  "地址生成成功",
 		Data: types.AddressResponse{
-			PrivateKey: privateKeyHex,
-			Address:    tronAddress,
-			HexAddress: addressHex,
+			PrivateKey: key.Hex(),
+			Address:    address.Base58(),
+			HexAddress: address.Hex(),
 		},
 		Time: time.Now().Unix(),
 	}
@@ -156,17 +375,38 @@ func (s *Service) CreateAddressHandler(c *gin.Context) {
 
 // 通过助记词生成地址
 func (s *Service) GenerateAddressWithMnemonicHandler(c *gin.Context) {
-	// 生成模拟助记词
-	mnemonic := utils.GenerateMnemonic()
+	// 由 crypto/rand 生成BIP39助记词，默认12个单词(128位熵)，words=24时为256位熵
+	bits := 128
+	switch param(c, "words") {
+	case "", "12":
+	case "24":
+		bits = 256
+	default:
+		respondError(c, "words 只支持12或24")
+		return
+	}
+	mnemonic, err := tron.NewMnemonic(bits)
+	if err != nil {
+		respondError(c, "助记词生成失败："+err.Error())
+		return
+	}
 
-	// 生成随机私钥
-	privateKeyBytes := make([]byte, 32)
-	rand.Read(privateKeyBytes)
-	privateKeyHex := hex.EncodeToString(privateKeyBytes)
+	// 按BIP44路径派生第一个地址
+	key, err := tron.DeriveKeyFromMnemonic(mnemonic, 0)
+	if err != nil {
+		c.JSON(http.StatusOK, types.APIResponse{
+			Code: 0,
+			Msg:  "地址生成失败：" + err.Error(),
+			Data: nil,
+			Time: time.Now().Unix(),
+		})
+		return
+	}
 
-	// 生成模拟的TRON地址
-	tronAddress := utils.GenerateTronAddress()
-	addressHex := "41" + tronAddress[1:]
+	address := key.Address()
+	privateKeyHex := key.Hex()
+	tronAddress := address.Base58()
+	addressHex := address.Hex()
 
 	response := types.APIResponse{
 		Code: 1,
@@ -207,7 +447,8 @@ func (s *Service) GetAddressByKeyHandler(c *gin.Context) {
 	}
 
 	// 验证私钥格式
-	if len(privateKeyHex) != 64 {
+	key, err := tron.ParsePrivateKey(privateKeyHex)
+	if err != nil {
 		c.JSON(http.StatusOK, types.APIResponse{
 			Code: 0,
 			Msg:  "私钥格式错误",
@@ -217,9 +458,10 @@ func (s *Service) GetAddressByKeyHandler(c *gin.Context) {
 		return
 	}
 
-	// 生成模拟的TRON地址
-	tronAddress := utils.GenerateTronAddress()
-	addressHex := "41" + tronAddress[1:]
+	// 由私钥推导TRON地址
+	address := key.Address()
+	tronAddress := address.Base58()
+	addressHex := address.Hex()
 
 	response := types.APIResponse{
 		Code: 1,
@@ -252,12 +494,27 @@ func (s *Service) MnemonicToAddressHandler(c *gin.Context) {
 		return
 	}
 
-	// 生成随机私钥和地址
-	privateKeyBytes := make([]byte, 32)
-	rand.Read(privateKeyBytes)
-	privateKeyHex := hex.EncodeToString(privateKeyBytes)
+	index := 0
+	if indexStr := param(c, "index"); indexStr != "" {
+		if i, err := strconv.Atoi(indexStr); err == nil && i >= 0 {
+			index = i
+		}
+	}
+
+	// 按BIP44路径 m/44'/195'/0'/0/index 派生私钥和地址
+	key, err := tron.DeriveKeyFromMnemonic(mnemonic, uint32(index))
+	if err != nil {
+		c.JSON(http.StatusOK, types.APIResponse{
+			Code: 0,
+			Msg:  "转换失败：" + err.Error(),
+			Data: nil,
+			Time: time.Now().Unix(),
+		})
+		return
+	}
 
-	tronAddress := utils.GenerateTronAddress()
+	privateKeyHex := key.Hex()
+	tronAddress := key.Address().Base58()
 
 	response := types.APIResponse{
 		Code: 1,
@@ -300,7 +557,7 @@ func (s *Service) MnemonicToAddressBatchHandler(c *gin.Context) {
 
 	offset := 0
 	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
 			offset = o
 		}
 	}
@@ -319,17 +576,34 @@ func (s *Service) MnemonicToAddressBatchHandler(c *gin.Context) {
 
 	var addresses []map[string]interface{}
 
-	for i := 0; i < num; i++ {
-		privateKeyBytes := make([]byte, 32)
-		rand.Read(privateKeyBytes)
-		privateKeyHex := hex.EncodeToString(privateKeyBytes)
+	deriver, err := tron.NewMnemonicDeriver(mnemonic)
+	if err != nil {
+		c.JSON(http.StatusOK, types.APIResponse{
+			Code: 0,
+			Msg:  "生成失败：" + err.Error(),
+			Data: nil,
+			Time: time.Now().Unix(),
+		})
+		return
+	}
 
-		tronAddress := utils.GenerateTronAddress()
+	for i := 0; i < num; i++ {
+		// 按BIP44路径 m/44'/195'/0'/0/offset 派生
+		key, err := deriver.Derive(uint32(offset + i))
+		if err != nil {
+			c.JSON(http.StatusOK, types.APIResponse{
+				Code: 0,
+				Msg:  "生成失败：" + err.Error(),
+				Data: nil,
+				Time: time.Now().Unix(),
+			})
+			return
+		}
 
 		addresses = append(addresses, map[string]interface{}{
 			"offset":     offset + i,
-			"address":    tronAddress,
-			"privateKey": privateKeyHex,
+			"address":    key.Address().Base58(),
+			"privateKey": key.Hex(),
 		})
 	}
 
@@ -361,7 +635,8 @@ func (s *Service) PrivateKeyToAddressHandler(c *gin.Context) {
 	}
 
 	// 验证私钥格式
-	if len(privateKeyHex) != 64 {
+	key, err := tron.ParsePrivateKey(privateKeyHex)
+	if err != nil {
 		c.JSON(http.StatusOK, types.APIResponse{
 			Code: 0,
 			Msg:  "私钥格式错误",
@@ -371,9 +646,10 @@ func (s *Service) PrivateKeyToAddressHandler(c *gin.Context) {
 		return
 	}
 
-	// 生成模拟的TRON地址
-	tronAddress := utils.GenerateTronAddress()
-	addressHex := "41" + tronAddress[1:]
+	// 由私钥推导TRON地址
+	address := key.Address()
+	tronAddress := address.Base58()
+	addressHex := address.Hex()
 
 	response := types.APIResponse{
 		Code: 1,
@@ -390,484 +666,458 @@ func (s *Service) PrivateKeyToAddressHandler(c *gin.Context) {
 
 // 查询TRX余额
 func (s *Service) GetTrxBalanceHandler(c *gin.Context) {
-	address := c.Query("address")
-	if address == "" {
-		address = c.PostForm("address")
-	}
-
+	address := param(c, "address")
 	if address == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "地址不能为空",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+		respondError(c, "地址不能为空")
 		return
 	}
 
-	// 调用TRON API查询真实余额
-	balance, err := utils.GetTronBalance(address, s.Config)
+	addr, err := tron.ParseAddress(address)
 	if err != nil {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "查询余额失败: " + err.Error(),
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+		respondError(c, "地址格式错误")
 		return
 	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRX余额查询成功",
-		Data: balance,
-		Time: time.Now().Unix(),
+	// 通过链后端查询真实余额
+	sun, err := s.Chain.Balance(addr.Base58())
+	if err != nil {
+		respondError(c, "查询余额失败: "+err.Error())
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respondSuccess(c, "TRX余额查询成功", balanceResponse(addr.Base58(), "TRX", amount.Sun(sun)))
 }
 
 // 查询TRC20余额
 func (s *Service) GetTrc20BalanceHandler(c *gin.Context) {
-	address := c.Query("address")
-	if address == "" {
-		address = c.PostForm("address")
-	}
-
-	contract := c.Query("contract")
-	if contract == "" {
-		contract = c.PostForm("contract")
-	}
+	address := param(c, "address")
+	contract := param(c, "contract")
 	if contract == "" {
 		contract = s.Config.ContractAddress // 默认USDT合约地址
 	}
 
 	if address == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "地址不能为空",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+		respondError(c, "地址不能为空")
 		return
 	}
 
-	// 调用TRON API查询真实TRC20余额
-	balance, err := utils.GetTrc20Balance(address, contract)
+	addr, err := tron.ParseAddress(address)
 	if err != nil {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "查询余额失败: " + err.Error(),
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+		respondError(c, "地址格式错误")
+		return
+	}
+	contractAddr, err := tron.ParseAddress(contract)
+	if err != nil {
+		respondError(c, "合约地址格式错误")
 		return
 	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRC20余额查询成功",
-		Data: balance,
-		Time: time.Now().Unix(),
+	// 调用合约balanceOf查询真实TRC20余额
+	balance, err := utils.GetTrc20Balance(s.Config, addr.Base58(), contractAddr.Base58())
+	if err != nil {
+		respondError(c, "查询余额失败: "+err.Error())
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respondSuccess(c, "TRC20余额查询成功", balanceResponse(addr.Base58(), contractAddr.Base58(), balance))
 }
 
-// 查询TRC10信息
+// 查询TRC10代币信息，传入address时同时返回该地址的余额
 func (s *Service) GetTrc10InfoHandler(c *gin.Context) {
-	address := c.Query("address")
-	tokenId := c.Query("tokenId")
-
-	if address == "" {
-		address = c.PostForm("address")
-	}
-	if tokenId == "" {
-		tokenId = c.PostForm("tokenId")
-	}
-
-	if address == "" {
-		address = "TTAUj1qkSVK2LuZBResGu2xXb1ZAguGsnu"
-	}
+	tokenId := param(c, "tokenId")
 	if tokenId == "" {
 		tokenId = "1002992"
 	}
 
-	// 模拟返回TRC10信息
-	data := map[string]interface{}{
-		"trxBalance":   1000000,
-		"tokenBalance": 100,
-		"tokenInfo": map[string]interface{}{
-			"id":   tokenId,
-			"name": "TestToken",
-		},
-	}
-
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRC10信息查询成功",
-		Data: data,
-		Time: time.Now().Unix(),
+	data, err := s.trc10Balance(param(c, "address"), tokenId)
+	if err != nil {
+		respondError(c, "查询TRC10信息失败: "+err.Error())
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respondSuccess(c, "TRC10信息查询成功", data)
 }
 
 // 查询TRC10余额
 func (s *Service) GetTrc10BalanceHandler(c *gin.Context) {
-	address := c.Query("address")
-	tokenId := c.Query("tokenId")
+	address := param(c, "address")
+	tokenId := param(c, "tokenId")
 
 	if address == "" {
-		address = c.PostForm("address")
+		respondError(c, "地址不能为空")
+		return
 	}
 	if tokenId == "" {
-		tokenId = c.PostForm("tokenId")
+		tokenId = "1002992"
 	}
 
-	if address == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "地址不能为空",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	data, err := s.trc10Balance(address, tokenId)
+	if err != nil {
+		respondError(c, "查询TRC10余额失败: "+err.Error())
 		return
 	}
 
-	if tokenId == "" {
-		tokenId = "1002992"
+	respondSuccess(c, "TRC10余额查询成功", data)
+}
+
+// 查询TRC10代币信息及地址的TRC10和TRX余额(address为空时只返回代币信息)
+func (s *Service) trc10Balance(address, tokenID string) (*types.Trc10BalanceResponse, error) {
+	token, err := utils.GetTrc10Token(s.Config, tokenID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 模拟返回TRC10余额
-	data := map[string]interface{}{
-		"trxBalance":   1000000,
-		"tokenBalance": 100,
-		"tokenInfo": map[string]interface{}{
-			"id":   tokenId,
-			"name": "TestToken",
-		},
+	data := &types.Trc10BalanceResponse{TokenInfo: token}
+	if address == "" {
+		return data, nil
 	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Data: data,
-		Time: time.Now().Unix(),
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		return nil, errors.New("地址格式错误")
+	}
+	units, err := utils.GetTrc10BalanceRaw(s.Config, addr.Base58(), tokenID)
+	if err != nil {
+		return nil, err
+	}
+	sun, err := utils.GetAccountBalance(s.Config, addr.Base58())
+	if err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, response)
+	tokenBalance := amount.FromInt64(units, token.Precision)
+	trxBalance := amount.Sun(sun)
+	data.Address = addr.Base58()
+	data.TokenBalance = tokenBalance.String()
+	data.TokenUnits = tokenBalance.UnitsString()
+	data.TrxBalance = trxBalance.String()
+	data.TrxBalanceUnits = trxBalance.UnitsString()
+	return data, nil
 }
 
 // TRX转账
 func (s *Service) SendTrxHandler(c *gin.Context) {
-	to := c.Query("to")
-	amountStr := c.Query("amount")
-	key := c.Query("key")
-	message := c.Query("message")
+	to := param(c, "to")
+	amountStr := param(c, "amount")
+	keyHex := param(c, "key")
 
-	if to == "" {
-		to = c.PostForm("to")
+	if to == "" || amountStr == "" || keyHex == "" {
+		respondError(c, "参数不完整：需要接收地址、私钥和转账金额")
+		return
 	}
-	if amountStr == "" {
-		amountStr = c.PostForm("amount")
+
+	sun, err := parseTrxAmount(amountStr)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
-	if key == "" {
-		key = c.PostForm("key")
+
+	key, err := tron.ParsePrivateKey(keyHex)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
-	if message == "" {
-		message = c.PostForm("message")
+
+	toAddr, err := tron.ParseAddress(to)
+	if err != nil {
+		respondError(c, "接收地址格式错误")
+		return
 	}
 
-	if to == "" || amountStr == "" || key == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "参数不完整：需要接收地址、私钥和转账金额",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	permissionID, multiSign, err := parsePermissionID(c)
+	if err != nil {
+		respondError(c, err.Error())
 		return
 	}
 
-	amount, err := strconv.ParseFloat(amountStr, 64)
-	if err != nil || amount <= 0 {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "转账金额无效",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	owner, err := resolveOwner(c, key, multiSign)
+	if err != nil {
+		respondError(c, err.Error())
 		return
 	}
 
-	// 模拟转账（实际应该调用TRON API）
-	txId := utils.GenerateTxId()
+	if queueRequested(c) {
+		s.enqueueTransfer(c, &queue.Transfer{
+			TokenType:    queue.TokenTRX,
+			From:         owner,
+			To:           toAddr.Base58(),
+			Amount:       amount.Sun(sun),
+			PermissionID: permissionID,
+		}, key)
+		return
+	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRX转账成功",
-		Data: types.TransactionResponse{
-			Result: true,
-			TxID:   txId,
-			TxId:   txId,
-		},
-		Time: time.Now().Unix(),
+	tx, err := utils.CreateTrxTransaction(s.Config, owner, toAddr.Base58(), sun, permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, key, multiSign)
+	if err != nil {
+		respondErrorData(c, "TRX转账失败: "+err.Error(), types.TransactionResponse{TxID: tx.TxID, TxId: tx.TxID})
+		return
+	}
+	setTransferAmount(result, amount.Sun(sun))
+
+	respondTransfer(c, "TRX转账成功", result, multiSign)
 }
 
 // TRC20转账
 func (s *Service) SendTrc20Handler(c *gin.Context) {
-	to := c.Query("to")
-	amount := c.Query("amount")
-	key := c.Query("key")
+	to := param(c, "to")
+	amountStr := param(c, "amount")
+	keyHex := param(c, "key")
+	contract := param(c, "contract")
 
-	if to == "" {
-		to = c.PostForm("to")
-	}
-	if amount == "" {
-		amount = c.PostForm("amount")
-		if amount == "" {
-			amount = "1.000001"
-		}
+	if contract == "" {
+		contract = s.Config.ContractAddress // 默认USDT合约地址
 	}
-	if key == "" {
-		key = c.PostForm("key")
+
+	if to == "" || amountStr == "" || keyHex == "" {
+		respondError(c, "参数不完整：需要接收地址、私钥和转账金额")
+		return
 	}
 
-	if to == "" || key == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "参数不完整：需要接收地址和私钥",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	contractAddr, err := tron.ParseAddress(contract)
+	if err != nil {
+		respondError(c, "合约地址格式错误")
 		return
 	}
 
-	// 模拟转账（实际应该调用TRON API）
-	txId := utils.GenerateTxId()
+	// 按代币精度解析金额
+	decimals, err := utils.GetTokenDecimals(s.Config, contractAddr.Base58())
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	value, err := parsePositiveAmount(amountStr, decimals)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRC20转账成功",
-		Data: types.TransactionResponse{
-			Result: true,
-			TxID:   txId,
-			TxId:   txId,
-		},
-		Time: time.Now().Unix(),
+	key, err := tron.ParsePrivateKey(keyHex)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
-}
+	toAddr, err := tron.ParseAddress(to)
+	if err != nil {
+		respondError(c, "接收地址格式错误")
+		return
+	}
 
-// TRC10转账
-func (s *Service) SendTrc10Handler(c *gin.Context) {
-	to := c.Query("to")
-	amountStr := c.Query("amount")
-	key := c.Query("key")
-	tokenId := c.Query("tokenId")
-
-	if to == "" {
-		to = c.PostForm("to")
-	}
-	if amountStr == "" {
-		amountStr = c.PostForm("amount")
-		if amountStr == "" {
-			amountStr = "1"
-		}
+	permissionID, multiSign, err := parsePermissionID(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
-	if key == "" {
-		key = c.PostForm("key")
+
+	owner, err := resolveOwner(c, key, multiSign)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
-	if tokenId == "" {
-		tokenId = c.PostForm("tokenId")
-		if tokenId == "" {
-			tokenId = "1002992"
-		}
+
+	if queueRequested(c) {
+		s.enqueueTransfer(c, &queue.Transfer{
+			TokenType:    queue.TokenTRC20,
+			Token:        contractAddr.Base58(),
+			From:         owner,
+			To:           toAddr.Base58(),
+			Amount:       value,
+			PermissionID: permissionID,
+		}, key)
+		return
 	}
 
-	if to == "" || key == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "参数不完整：需要私钥和接收地址",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	parameter, err := tron.EncodeTransferParams(toAddr, value.Units())
+	if err != nil {
+		respondError(c, err.Error())
 		return
 	}
 
-	// 模拟转账（实际应该调用TRON API）
-	txId := utils.GenerateTxId()
+	tx, err := utils.TriggerSmartContract(s.Config, owner, contractAddr.Base58(), "transfer(address,uint256)", parameter, 0, permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRC10转账成功",
-		Data: types.TransactionResponse{
-			Result: true,
-			TxID:   txId,
-			TxId:   txId,
-		},
-		Time: time.Now().Unix(),
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, key, multiSign)
+	if err != nil {
+		respondErrorData(c, "TRC20转账失败: "+err.Error(), types.TransactionResponse{TxID: tx.TxID, TxId: tx.TxID})
+		return
 	}
+	setTransferAmount(result, value)
 
-	c.JSON(http.StatusOK, response)
+	respondTransfer(c, "TRC20转账成功", result, multiSign)
 }
 
-// 查询交易详情
-func (s *Service) GetTransactionHandler(c *gin.Context) {
-	txID := c.Query("txID")
-	if txID == "" {
-		txID = c.Query("txid") // 兼容小写txid
+// TRC10转账
+func (s *Service) SendTrc10Handler(c *gin.Context) {
+	to := param(c, "to")
+	amountStr := param(c, "amount")
+	keyHex := param(c, "key")
+	tokenId := param(c, "tokenId")
+
+	if to == "" || amountStr == "" || keyHex == "" || tokenId == "" {
+		respondError(c, "参数不完整：需要接收地址、私钥、转账金额和代币ID")
+		return
 	}
-	if txID == "" {
-		txID = c.PostForm("txID")
+
+	// TRC10金额为代币最小单位
+	units, err := parsePositiveAmount(amountStr, 0)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
-	if txID == "" {
-		txID = c.PostForm("txid") // 兼容小写txid
+	amountUnits, ok := units.Int64()
+	if !ok {
+		respondError(c, "转账金额过大")
+		return
 	}
 
-	if txID == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "交易ID不能为空",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	token, err := utils.GetTrc10Token(s.Config, tokenId)
+	if err != nil {
+		respondError(c, err.Error())
 		return
 	}
 
-	// 模拟返回交易信息
-	data := map[string]interface{}{
-		"ret": []map[string]string{
-			{"contractRet": "SUCCESS"},
-		},
-		"txID": txID,
-		"raw_data": map[string]interface{}{
-			"contract": []map[string]interface{}{
-				{
-					"parameter": map[string]interface{}{
-						"value": map[string]interface{}{
-							"amount":        1100000,
-							"owner_address": "41bc9bd6d0db7bf6e20874459c7481d00d3825117f",
-							"to_address":    "4134382df086a72fb18b9faa253a839a4a95f41b25",
-						},
-					},
-				},
-			},
-		},
+	key, err := tron.ParsePrivateKey(keyHex)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "交易查询成功",
-		Data: data,
-		Time: time.Now().Unix(),
+	toAddr, err := tron.ParseAddress(to)
+	if err != nil {
+		respondError(c, "接收地址格式错误")
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
-}
+	permissionID, multiSign, err := parsePermissionID(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
 
-// 查询TRC20交易回执
-func (s *Service) GetTrc20TransactionReceiptHandler(c *gin.Context) {
-	txID := c.Query("txID")
-	if txID == "" {
-		txID = c.Query("txid") // 兼容小写txid
+	owner, err := resolveOwner(c, key, multiSign)
+	if err != nil {
+		respondError(c, err.Error())
+		return
 	}
-	if txID == "" {
-		txID = c.PostForm("txID")
+
+	if queueRequested(c) {
+		s.enqueueTransfer(c, &queue.Transfer{
+			TokenType:    queue.TokenTRC10,
+			Token:        tokenId,
+			From:         owner,
+			To:           toAddr.Base58(),
+			Amount:       amount.FromInt64(amountUnits, token.Precision),
+			PermissionID: permissionID,
+		}, key)
+		return
 	}
-	if txID == "" {
-		txID = c.PostForm("txid") // 兼容小写txid
+
+	tx, err := utils.CreateTrc10Transaction(s.Config, owner, toAddr.Base58(), tokenId, amountUnits, permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, key, multiSign)
+	if err != nil {
+		respondErrorData(c, "TRC10转账失败: "+err.Error(), types.TransactionResponse{TxID: tx.TxID, TxId: tx.TxID})
+		return
 	}
+	setTransferAmount(result, amount.FromInt64(amountUnits, token.Precision))
+
+	respondTransfer(c, "TRC10转账成功", result, multiSign)
+}
 
+// 查询TRC20交易回执，并附带确认状态
+func (s *Service) GetTrc20TransactionReceiptHandler(c *gin.Context) {
+	txID := param(c, "txID", "txid")
 	if txID == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "交易ID不能为空",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+		respondError(c, "交易ID不能为空")
 		return
 	}
 
-	// 模拟返回交易回执
-	data := map[string]interface{}{
-		"receipt": map[string]interface{}{
-			"result":       "SUCCESS",
-			"energy_usage": 13345,
-		},
+	st, err := s.Tracker.Status(txID)
+	if err != nil {
+		respondError(c, "查询交易状态失败: "+err.Error())
+		return
+	}
+	switch st.Status {
+	case confirm.StatusNotFound:
+		respondErrorData(c, "交易不存在", st)
+		return
+	case confirm.StatusBroadcast, confirm.StatusExpired:
+		respondErrorData(c, "交易尚未上链，暂无回执", st)
+		return
 	}
 
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "TRC20交易回执查询成功",
-		Data: data,
-		Time: time.Now().Unix(),
+	info, err := utils.GetTransactionInfo(s.Config, txID)
+	if err != nil {
+		respondError(c, "查询交易回执失败: "+err.Error())
+		return
+	}
+	if info == nil {
+		// 查询状态后交易所在区块被回滚
+		respondErrorData(c, "交易尚未上链，暂无回执", st)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	data := map[string]interface{}{
+		"txID":          txID,
+		"blockNumber":   info.BlockNumber,
+		"fee":           info.Fee,
+		"receipt":       info.Receipt,
+		"log":           info.Log,
+		"status":        st.Status,
+		"confirmations": st.Confirmations,
+		"confirmation":  st,
+	}
+	respondSuccess(c, "TRC20交易回执查询成功", data)
 }
 
-// 获取区块高度
+// 获取区块高度，solid=true时返回已固化的区块高度
 func (s *Service) GetBlockHeightHandler(c *gin.Context) {
-	// 模拟返回区块高度
-	blockHeight := 58763421
-
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "区块高度查询成功",
-		Data: blockHeight,
-		Time: time.Now().Unix(),
+	solid, _ := strconv.ParseBool(param(c, "solid"))
+	height, err := utils.GetNowBlockNumber(s.Config, solid)
+	if err != nil {
+		respondError(c, "查询区块高度失败: "+err.Error())
+		return
 	}
-
-	c.JSON(http.StatusOK, response)
+	respondSuccess(c, "区块高度查询成功", height)
 }
 
-// 根据区块号查询区块
+// 根据区块号查询区块，返回区块头及其中的TRX、TRC10和TRC20转账
 func (s *Service) GetBlockByNumberHandler(c *gin.Context) {
-	blockID := c.Query("blockID")
-	if blockID == "" {
-		blockID = c.Query("blockNumber") // 兼容blockNumber参数
-	}
-	if blockID == "" {
-		blockID = c.PostForm("blockID")
-	}
-	if blockID == "" {
-		blockID = c.PostForm("blockNumber") // 兼容blockNumber参数
+	v := param(c, "blockID", "blockNumber") // 兼容blockNumber参数
+	if v == "" {
+		respondError(c, "区块号不能为空")
+		return
 	}
-
-	if blockID == "" {
-		c.JSON(http.StatusOK, types.APIResponse{
-			Code: 0,
-			Msg:  "区块号不能为空",
-			Data: nil,
-			Time: time.Now().Unix(),
-		})
+	num, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || num < 0 {
+		respondError(c, "区块号必须为非负整数")
 		return
 	}
 
-	// 模拟返回区块信息
-	data := map[string]interface{}{
-		"blockID": "00000000038f7a52d9cb8e4f2a1d6b9c3e5f8a2d4b7c9e1f3a6b8",
-		"block_header": map[string]interface{}{
-			"raw_data": map[string]interface{}{
-				"number":          58763421,
-				"txTrieRoot":      "...",
-				"witness_address": "...",
-				"parentHash":      "...",
-				"timestamp":       1756395200000,
-			},
-		},
+	block, err := blocks.Fetch(s.Config, num)
+	if err != nil {
+		respondError(c, "查询区块失败: "+err.Error())
+		return
 	}
-
-	response := types.APIResponse{
-		Code: 1,
-		Msg:  "区块信息查询成功",
-		Data: data,
-		Time: time.Now().Unix(),
+	if block == nil {
+		respondError(c, "区块不存在")
+		return
 	}
-
-	c.JSON(http.StatusOK, response)
+	respondSuccess(c, "区块信息查询成功", block)
 }
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"tron-api-go/internal/settings"
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// 接口响应，data保留原始JSON由各测试解析
+type apiResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// 使用指定链后端的处理器服务，任务数据保存在临时目录
+func newTestService(t *testing.T, client types.ChainClient) *Service {
+	t.Helper()
+	config := settings.Default()
+	config.DataDir = t.TempDir()
+	config.QueueWorkers = 1
+	config.Chain = client
+	return NewService(config)
+}
+
+// 以表单参数调用处理函数
+func call(t *testing.T, s *Service, h func(*Service, *gin.Context), form url.Values) apiResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h(s, c)
+
+	var resp apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应不是JSON: %s", w.Body.String())
+	}
+	return resp
+}
+
+// 调用成功并解析data
+func mustSucceed(t *testing.T, resp apiResponse, data interface{}) {
+	t.Helper()
+	if resp.Code != 1 {
+		t.Fatalf("code = %d, msg = %s, data = %s", resp.Code, resp.Msg, resp.Data)
+	}
+	if data != nil {
+		if err := json.Unmarshal(resp.Data, data); err != nil {
+			t.Fatalf("解析data失败: %v, data = %s", err, resp.Data)
+		}
+	}
+}
+
+// 调用失败且错误信息包含msg
+func mustFail(t *testing.T, resp apiResponse, msg string) {
+	t.Helper()
+	if resp.Code != 0 {
+		t.Fatalf("code = %d, want 0, msg = %s, data = %s", resp.Code, resp.Msg, resp.Data)
+	}
+	if !strings.Contains(resp.Msg, msg) {
+		t.Fatalf("msg = %q, want containing %q", resp.Msg, msg)
+	}
+}
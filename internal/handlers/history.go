@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"tron-api-go/internal/history"
+	"tron-api-go/internal/tron"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 查询账户转账记录(TRX、TRC10或TRC20)，通过cursor翻页
+func (s *Service) GetTransactionHistoryHandler(c *gin.Context) {
+	q := history.Query{
+		Type:   strings.ToLower(strings.TrimSpace(param(c, "type"))),
+		Cursor: param(c, "cursor"),
+	}
+
+	// 使用cursor时查询条件以cursor中记录的为准，address可省略
+	if q.Cursor == "" || param(c, "address") != "" {
+		address, err := readAddress(c, "地址", "address")
+		if err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		q.Address = address
+	}
+
+	if q.Cursor == "" {
+		q.Contract = strings.TrimSpace(param(c, "contract"))
+		if q.Type == history.TypeTRC20 && q.Contract != "" {
+			addr, err := tron.ParseAddress(q.Contract)
+			if err != nil {
+				respondError(c, "合约地址格式错误")
+				return
+			}
+			q.Contract = addr.Base58()
+		}
+
+		var err error
+		if q.From, err = history.ParseTime(param(c, "from"), false); err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		if q.To, err = history.ParseTime(param(c, "to"), true); err != nil {
+			respondError(c, err.Error())
+			return
+		}
+	}
+
+	if v := param(c, "limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(c, "limit必须为正整数")
+			return
+		}
+		q.Limit = n
+	}
+
+	result, err := history.Fetch(s.Config, q)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "交易记录查询成功", result)
+}
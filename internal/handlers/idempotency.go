@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 幂等键在请求上下文中的名称
+const idempotencyKeyCtx = "idempotencyKey"
+
+// 幂等键最大长度
+const maxIdempotencyKeyLen = 128
+
+// 等待相同幂等键的请求处理完成的最长时间
+const idempotencyWait = 60 * time.Second
+
+// 记录响应内容，用于保存幂等请求的结果
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// 计算请求指纹：请求路径、全部参数(不含幂等键)、JSON或CSV请求体及上传文件的哈希
+func requestFingerprint(c *gin.Context) string {
+	// 读取请求体后放回，供处理函数解析
+	var body []byte
+	contentType := c.ContentType()
+	if (strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/")) && c.Request.Body != nil {
+		body, _ = io.ReadAll(c.Request.Body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	c.Request.ParseMultipartForm(32 << 20)
+
+	names := make([]string, 0, len(c.Request.Form))
+	for name := range c.Request.Form {
+		if name != "requestId" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.URL.Path))
+	for _, name := range names {
+		h.Write([]byte{0})
+		h.Write([]byte(name + "=" + strings.Join(c.Request.Form[name], ",")))
+	}
+	if len(body) > 0 {
+		h.Write([]byte{0})
+		h.Write(body)
+	}
+	if form := c.Request.MultipartForm; form != nil {
+		files := make([]string, 0, len(form.File))
+		for name := range form.File {
+			files = append(files, name)
+		}
+		sort.Strings(files)
+		for _, name := range files {
+			for _, fh := range form.File[name] {
+				h.Write([]byte{0})
+				h.Write([]byte(name + "=" + fh.Filename + "\n"))
+				if f, err := fh.Open(); err == nil {
+					io.Copy(h, f)
+					f.Close()
+				}
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 为转账接口增加幂等支持：相同的 Idempotency-Key 请求头或 requestId 参数只执行一次，
+// 重复请求(包括首次请求仍在处理中时)返回首次请求的结果
+func (s *Service) Idempotent(next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+		if key == "" {
+			key = param(c, "requestId")
+		}
+		if key == "" {
+			next(c)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			respondError(c, "幂等键长度不能超过128个字符")
+			return
+		}
+
+		fingerprint := requestFingerprint(c)
+		for {
+			rec, wait, err := s.Idempotency.Begin(key, fingerprint)
+			if err != nil {
+				respondError(c, err.Error())
+				return
+			}
+			if wait != nil {
+				select {
+				case <-wait:
+					continue
+				case <-time.After(idempotencyWait):
+					respondError(c, "相同幂等键的请求正在处理中，请稍后重试")
+					return
+				case <-c.Request.Context().Done():
+					return
+				}
+			}
+			if rec != nil {
+				s.replayIdempotent(c, rec)
+				return
+			}
+			break
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Set(idempotencyKeyCtx, key)
+		defer func() {
+			s.Idempotency.Finish(key, recorder.body.Bytes())
+		}()
+
+		next(c)
+	}
+}
+
+// 返回已处理请求的结果
+func (s *Service) replayIdempotent(c *gin.Context, rec *types.IdempotencyRecord) {
+	c.Header("Idempotent-Replayed", "true")
+
+	if len(rec.Response) > 0 {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", rec.Response)
+		return
+	}
+
+	// 服务重启前已创建任务但未返回结果，返回任务的当前状态
+	if rec.JobID != "" {
+		if batch, err := s.Payouts.Get(rec.JobID); err == nil {
+			respondSuccess(c, "付款批次已创建", batch)
+			return
+		}
+		if job, err := s.Sweeper.Get(rec.JobID); err == nil {
+			respondSuccess(c, "归集任务已创建", job)
+			return
+		}
+		respondErrorData(c, "请求已提交但结果未知，请根据任务ID查询", gin.H{"id": rec.JobID})
+		return
+	}
+
+	// 服务重启前已生成交易但未返回结果，查询链上状态
+	info, err := utils.GetTransactionInfo(s.Config, rec.TxID)
+	data := types.TransactionResponse{TxID: rec.TxID, TxId: rec.TxID}
+	if err == nil && info != nil && info.Result != "FAILED" {
+		data.Result = true
+		respondSuccess(c, "交易已上链", data)
+		return
+	}
+	respondErrorData(c, "请求已提交但结果未知，请根据txID查询交易状态", data)
+}
+
+// 记录幂等请求生成的交易ID，需在广播前调用
+func (s *Service) recordTxID(c *gin.Context, txID string) {
+	if key := c.GetString(idempotencyKeyCtx); key != "" {
+		s.Idempotency.SetTxID(key, txID)
+	}
+}
+
+// 记录幂等请求创建的任务ID，重试时不再重复创建任务
+func (s *Service) recordJobID(c *gin.Context, jobID string) {
+	if key := c.GetString(idempotencyKeyCtx); key != "" {
+		s.Idempotency.SetJobID(key, jobID)
+	}
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 解析permissionId参数，未传入时返回0(Owner权限)
+func parsePermissionID(c *gin.Context) (int, bool, error) {
+	raw := param(c, "permissionId", "Permission_id")
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.Atoi(raw)
+	if err != nil || id < 0 {
+		return 0, true, errors.New("permissionId格式错误")
+	}
+	return id, true, nil
+}
+
+// 解析交易发起地址：多签时由from指定，否则使用私钥对应地址。
+// 未传入permissionId时from必须与私钥地址一致，避免误以他人地址构建交易
+func resolveOwner(c *gin.Context, key *tron.PrivateKey, multiSign bool) (string, error) {
+	return ownerAddress(key, param(c, "from", "owner"), multiSign)
+}
+
+// 校验交易发起地址，from为空时使用私钥对应地址
+func ownerAddress(key *tron.PrivateKey, from string, multiSign bool) (string, error) {
+	self := key.Address().Base58()
+	if from == "" {
+		return self, nil
+	}
+
+	addr, err := tron.ParseAddress(from)
+	if err != nil {
+		return "", errors.New("发送地址格式错误")
+	}
+	if addr.Base58() != self && !multiSign {
+		return "", errors.New("发送地址与私钥地址不一致，代多签账户签名时需传入permissionId")
+	}
+	return addr.Base58(), nil
+}
+
+// 交易签名参数
+type signer struct {
+	key          *tron.PrivateKey
+	owner        string
+	permissionID int
+	multiSign    bool
+}
+
+// 解析私钥、发起地址(from)及权限(permissionId)参数
+func readSigner(c *gin.Context) (*signer, error) {
+	keyHex := param(c, "key", "privateKey")
+	if keyHex == "" {
+		return nil, errors.New("私钥不能为空")
+	}
+
+	permissionID, multiSign, err := parsePermissionID(c)
+	if err != nil {
+		return nil, err
+	}
+	var id *int
+	if multiSign {
+		id = &permissionID
+	}
+	return newSigner(keyHex, param(c, "from", "owner"), id)
+}
+
+// 由私钥、发起地址和权限ID构建签名参数，供以JSON请求体传参的接口使用
+func newSigner(keyHex, from string, permissionID *int) (*signer, error) {
+	key, err := tron.ParsePrivateKey(keyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	sg := &signer{key: key}
+	if permissionID != nil {
+		if *permissionID < 0 {
+			return nil, errors.New("permissionId格式错误")
+		}
+		sg.permissionID, sg.multiSign = *permissionID, true
+	}
+	if sg.owner, err = ownerAddress(key, from, sg.multiSign); err != nil {
+		return nil, err
+	}
+	return sg, nil
+}
+
+// 读取请求中的交易，支持JSON请求体或transaction参数
+func readTransaction(c *gin.Context) (*types.Transaction, error) {
+	var tx types.Transaction
+
+	raw := param(c, "transaction")
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &tx); err != nil {
+			return nil, errors.New("交易数据格式错误")
+		}
+	} else if strings.HasPrefix(c.ContentType(), "application/json") {
+		data, err := c.GetRawData()
+		if err != nil {
+			return nil, errors.New("读取请求数据失败")
+		}
+
+		var wrapper struct {
+			Transaction *types.Transaction `json:"transaction"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, errors.New("交易数据格式错误")
+		}
+		if wrapper.Transaction != nil {
+			tx = *wrapper.Transaction
+		} else if err := json.Unmarshal(data, &tx); err != nil {
+			return nil, errors.New("交易数据格式错误")
+		}
+	}
+
+	if tx.TxID == "" || tx.RawDataHex == "" {
+		return nil, errors.New("交易数据不能为空")
+	}
+	return &tx, nil
+}
+
+// 签名交易，满足权限阈值后广播；多签未集齐时返回部分签名的交易
+func (s *Service) signAndBroadcast(tx *types.Transaction, key *tron.PrivateKey, multiSign bool) (*types.MultiSignResponse, error) {
+	if err := utils.SignTransaction(tx, key); err != nil {
+		return nil, err
+	}
+
+	resp := &types.MultiSignResponse{TxID: tx.TxID}
+
+	if multiSign {
+		weight, err := utils.GetSignWeight(s.Config, tx)
+		if err != nil {
+			return nil, err
+		}
+		resp.SignWeight = weight
+		if !weight.Enough {
+			resp.Transaction = tx
+			return resp, nil
+		}
+	}
+
+	if _, err := utils.BroadcastTransaction(s.Config, tx); err != nil {
+		return nil, err
+	}
+	s.Tracker.Track(tx)
+
+	resp.Result = true
+	resp.Broadcast = true
+	return resp, nil
+}
+
+// 输出转账结果，多签未完成时返回待签名交易
+func respondTransfer(c *gin.Context, msg string, result *types.MultiSignResponse, multiSign bool) {
+	if multiSign {
+		if !result.Broadcast {
+			msg = "签名成功，等待其他签名"
+		}
+		respondSuccess(c, msg, result)
+		return
+	}
+
+	respondSuccess(c, msg, types.TransactionResponse{
+		Result:      result.Result,
+		TxID:        result.TxID,
+		TxId:        result.TxID,
+		Amount:      result.Amount,
+		AmountUnits: result.AmountUnits,
+	})
+}
+
+// 在转账结果中附加转账金额
+func setTransferAmount(result *types.MultiSignResponse, value amount.Amount) {
+	result.Amount = value.String()
+	result.AmountUnits = value.UnitsString()
+}
+
+// 为部分签名的交易追加签名
+func (s *Service) AddSignatureHandler(c *gin.Context) {
+	tx, err := readTransaction(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	key, err := tron.ParsePrivateKey(param(c, "key", "privateKey"))
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	if err := utils.SignTransaction(tx, key); err != nil {
+		respondError(c, "签名失败: "+err.Error())
+		return
+	}
+
+	weight, err := utils.GetSignWeight(s.Config, tx)
+	if err != nil {
+		respondError(c, "查询签名权重失败: "+err.Error())
+		return
+	}
+
+	resp := types.MultiSignResponse{
+		TxID:        tx.TxID,
+		SignWeight:  weight,
+		Transaction: tx,
+	}
+	if !weight.Enough {
+		respondSuccess(c, "签名成功，等待其他签名", resp)
+		return
+	}
+
+	// 签名权重达到阈值后直接广播，广播失败时返回已签名交易以便重新广播
+	if _, err := utils.BroadcastTransaction(s.Config, tx); err != nil {
+		respondErrorData(c, "签名成功，广播失败: "+err.Error(), resp)
+		return
+	}
+	s.Tracker.Track(tx)
+
+	resp.Result = true
+	resp.Broadcast = true
+	resp.Transaction = nil
+	respondSuccess(c, "签名成功，交易已广播", resp)
+}
+
+// 查询交易签名权重
+func (s *Service) GetSignWeightHandler(c *gin.Context) {
+	tx, err := readTransaction(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	weight, err := utils.GetSignWeight(s.Config, tx)
+	if err != nil {
+		respondError(c, "查询签名权重失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "签名权重查询成功", weight)
+}
+
+// 查询已签名地址列表
+func (s *Service) GetApprovedListHandler(c *gin.Context) {
+	tx, err := readTransaction(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	list, err := utils.GetApprovedList(s.Config, tx)
+	if err != nil {
+		respondError(c, "查询签名列表失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "签名列表查询成功", map[string]interface{}{
+		"txID":          tx.TxID,
+		"approved_list": list,
+	})
+}
+
+// 广播交易，签名权重未达到阈值时拒绝广播
+func (s *Service) BroadcastTransactionHandler(c *gin.Context) {
+	tx, err := readTransaction(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	weight, err := utils.GetSignWeight(s.Config, tx)
+	if err != nil {
+		respondError(c, "查询签名权重失败: "+err.Error())
+		return
+	}
+	if !weight.Enough {
+		c.JSON(http.StatusOK, types.APIResponse{
+			Code: 0,
+			Msg:  "签名权重不足，无法广播",
+			Data: types.MultiSignResponse{TxID: tx.TxID, SignWeight: weight, Transaction: tx},
+			Time: time.Now().Unix(),
+		})
+		return
+	}
+
+	s.recordTxID(c, tx.TxID)
+	if _, err := utils.BroadcastTransaction(s.Config, tx); err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	s.Tracker.Track(tx)
+
+	respondSuccess(c, "交易广播成功", types.MultiSignResponse{
+		Result:     true,
+		TxID:       tx.TxID,
+		Broadcast:  true,
+		SignWeight: weight,
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/network"
+	"tron-api-go/internal/settings"
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 按请求的network参数选择处理器服务。默认网络使用进程配置，其他网络在首次请求时创建，
+// 各网络的链客户端、交易确认跟踪、发送队列和任务数据互相独立
+type Networks struct {
+	Default *Service
+
+	config   *types.Config
+	mu       sync.Mutex
+	services map[string]*Service
+
+	// 热加载时在写锁内同时替换链客户端和配置快照，请求开始时在读锁内取得二者，保证彼此一致
+	reload sync.RWMutex
+}
+
+func NewNetworks(config *types.Config) *Networks {
+	def := NewService(config)
+	return &Networks{
+		Default:  def,
+		config:   config,
+		services: map[string]*Service{config.Network: def},
+	}
+}
+
+// 请求对应网络的处理器服务，未传network参数时使用默认网络
+func (n *Networks) Service(c *gin.Context) (*Service, error) {
+	name := strings.ToLower(strings.TrimSpace(param(c, "network")))
+	if name == "" {
+		return n.Default, nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if s, ok := n.services[name]; ok {
+		return s, nil
+	}
+	config, err := network.Derive(n.config, name)
+	if err != nil {
+		return nil, err
+	}
+	config.Chain = chain.NewReloadable(config.Chain)
+	s := NewService(config)
+	n.services[name] = s
+	return s, nil
+}
+
+// 在请求对应网络的处理器服务上执行处理函数，处理函数中对节点的请求随客户端断开而取消
+func (n *Networks) Handle(h func(*Service, *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, err := n.Service(c)
+		if err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		n.reload.RLock()
+		scoped := s.withContext(c.Request.Context())
+		n.reload.RUnlock()
+		h(scoped, c)
+	}
+}
+
+// 应用热加载的配置：按新配置重建各网络的链客户端，并发布更新了节点地址等配置项的新配置快照。
+// 已发布的配置不会被修改，后台组件读取配置时无需加锁。全部网络重建成功后才替换，任一网络失败时保持原配置
+func (n *Networks) Reload(next *types.Config) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	type update struct {
+		service  *Service
+		live     *chain.Reloadable
+		client   types.ChainClient
+		snapshot *types.Config
+	}
+	var updates []update
+	for name, s := range n.services {
+		live, ok := s.Chain.(*chain.Reloadable)
+		if !ok {
+			continue
+		}
+
+		var target *types.Config
+		if s == n.Default {
+			config := *s.current.Load()
+			settings.ApplyReloadable(&config, next)
+			client, err := chain.New(&config)
+			if err != nil {
+				return err
+			}
+			config.Chain = client
+			target = &config
+		} else {
+			config, err := network.Derive(next, name)
+			if err != nil {
+				return fmt.Errorf("网络 %s: %v", name, err)
+			}
+			target = config
+		}
+		// 新快照沿用服务的可替换链客户端，其余配置项与当前快照一致
+		snapshot := *s.current.Load()
+		settings.ApplyReloadable(&snapshot, target)
+		updates = append(updates, update{service: s, live: live, client: target.Chain, snapshot: &snapshot})
+	}
+
+	n.reload.Lock()
+	defer n.reload.Unlock()
+	for _, u := range updates {
+		u.live.Swap(u.client)
+		u.service.current.Store(u.snapshot)
+		if u.service == n.Default {
+			n.config = u.snapshot
+		}
+	}
+	return nil
+}
+
+// 同Handle，并按对应网络的幂等记录去重
+func (n *Networks) Idempotent(h func(*Service, *gin.Context)) gin.HandlerFunc {
+	return n.Handle(func(s *Service, c *gin.Context) {
+		s.Idempotent(func(c *gin.Context) { h(s, c) })(c)
+	})
+}
+
+// 查询可用网络及当前请求使用的网络
+func (s *Service) GetNetworksHandler(c *gin.Context) {
+	respondSuccess(c, "网络列表查询成功", map[string]interface{}{
+		"current":  network.Current(s.Config),
+		"networks": network.List(s.Config),
+	})
+}
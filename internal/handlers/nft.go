@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"tron-api-go/internal/contract"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 读取TRC721合约地址(必填)
+func readNFTContract(c *gin.Context) (tron.Address, error) {
+	v := param(c, "contract")
+	if v == "" {
+		return tron.Address{}, errors.New("合约地址不能为空")
+	}
+	addr, err := tron.ParseAddress(v)
+	if err != nil {
+		return addr, errors.New("合约地址格式错误")
+	}
+	return addr, nil
+}
+
+// 读取非负整数参数，为空时返回默认值
+func readIndex(c *gin.Context, name string, def int64) (int64, error) {
+	v := param(c, name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New(name + "必须为非负整数")
+	}
+	return n, nil
+}
+
+// 查询NFT持有地址(ownerOf)
+func (s *Service) GetTrc721OwnerHandler(c *gin.Context) {
+	nft, err := readNFTContract(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	tokenID, err := contract.ParseTokenID(param(c, "tokenId"))
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	owner, err := contract.OwnerOf(s.Config, nft, tokenID)
+	if err != nil {
+		respondError(c, "查询NFT持有者失败: "+err.Error())
+		return
+	}
+	respondSuccess(c, "NFT持有者查询成功", types.Trc721Token{
+		Contract: nft.Base58(),
+		TokenID:  tokenID,
+		Owner:    owner,
+	})
+}
+
+// 查询地址持有的NFT数量(balanceOf)
+func (s *Service) GetTrc721BalanceHandler(c *gin.Context) {
+	address, err := readAddress(c, "地址", "address")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	nft, err := readNFTContract(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	balance, err := contract.NFTBalance(s.Config, nft, address)
+	if err != nil {
+		respondError(c, "查询NFT数量失败: "+err.Error())
+		return
+	}
+	respondSuccess(c, "NFT数量查询成功", types.Trc721BalanceResponse{
+		Address:  address,
+		Contract: nft.Base58(),
+		Balance:  balance.String(),
+	})
+}
+
+// 查询NFT元数据地址(tokenURI)
+func (s *Service) GetTrc721TokenURIHandler(c *gin.Context) {
+	nft, err := readNFTContract(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	tokenID, err := contract.ParseTokenID(param(c, "tokenId"))
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	uri, err := contract.TokenURI(s.Config, nft, tokenID)
+	if err != nil {
+		respondError(c, "查询tokenURI失败: "+err.Error())
+		return
+	}
+	respondSuccess(c, "tokenURI查询成功", types.Trc721Token{
+		Contract: nft.Base58(),
+		TokenID:  tokenID,
+		TokenURI: uri,
+	})
+}
+
+// 按序号查询地址持有的tokenId(tokenOfOwnerByIndex)
+func (s *Service) GetTrc721TokenOfOwnerByIndexHandler(c *gin.Context) {
+	address, err := readAddress(c, "地址", "address")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	nft, err := readNFTContract(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	index, err := readIndex(c, "index", 0)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	tokenID, err := contract.TokenOfOwnerByIndex(s.Config, nft, address, index)
+	if err != nil {
+		respondError(c, "查询tokenId失败(合约需实现tokenOfOwnerByIndex): "+err.Error())
+		return
+	}
+	respondSuccess(c, "tokenId查询成功", types.Trc721Token{
+		Contract: nft.Base58(),
+		TokenID:  tokenID,
+		Owner:    address,
+	})
+}
+
+// 列出地址持有的NFT，可选同时返回tokenURI
+func (s *Service) ListTrc721TokensHandler(c *gin.Context) {
+	address, err := readAddress(c, "地址", "address")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	nft, err := readNFTContract(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	offset, err := readIndex(c, "offset", 0)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	limit, err := readIndex(c, "limit", contract.DefaultTokenLimit)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	if limit == 0 || limit > contract.MaxTokenLimit {
+		limit = contract.MaxTokenLimit
+	}
+	withURI, _ := strconv.ParseBool(param(c, "withUri"))
+
+	list, err := contract.OwnedTokens(s.Config, nft, address, offset, limit, withURI)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "NFT列表查询成功", list)
+}
+
+// 转移NFT(safeTransferFrom)，广播前检查当前账户是否持有该NFT
+func (s *Service) SendTrc721Handler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	to, err := readAddress(c, "接收地址", "to")
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	nft, err := readNFTContract(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	tokenID, err := contract.ParseTokenID(param(c, "tokenId"))
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	owner, err := contract.OwnerOf(s.Config, nft, tokenID)
+	if err != nil {
+		respondError(c, "查询NFT持有者失败: "+err.Error())
+		return
+	}
+	if owner != sg.owner {
+		respondError(c, "当前账户不持有该NFT，持有者为"+owner)
+		return
+	}
+
+	call := contract.SafeTransfer(nft, sg.owner, sg.owner, to, tokenID, param(c, "data"))
+	tx, err := contract.Transaction(s.Config, call, 0, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, sg.key, sg.multiSign)
+	if err != nil {
+		respondErrorData(c, "交易失败: "+err.Error(), types.TransactionResponse{TxID: tx.TxID, TxId: tx.TxID})
+		return
+	}
+	respondTransfer(c, "NFT转移成功", result, sg.multiSign)
+}
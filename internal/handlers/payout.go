@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/payout"
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 批量付款最长同步等待时间
+const maxPayoutWait = 300 * time.Second
+
+// 读取批量付款请求，支持CSV文件上传(file)、CSV文本(csv参数或text/csv请求体)及JSON请求体
+func readPayoutRequest(c *gin.Context) (*types.PayoutRequest, error) {
+	req := &types.PayoutRequest{}
+	contentType := c.ContentType()
+
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		if err := c.ShouldBindJSON(req); err != nil {
+			return nil, errors.New("请求数据格式错误，需要JSON格式的付款明细")
+		}
+	case strings.HasPrefix(contentType, "text/csv"), strings.HasPrefix(contentType, "text/plain"):
+		rows, err := payout.ParseCSV(c.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Rows = rows
+	default:
+		if file, err := c.FormFile("file"); err == nil {
+			f, err := file.Open()
+			if err != nil {
+				return nil, errors.New("读取上传文件失败")
+			}
+			defer f.Close()
+
+			rows, err := payout.ParseCSV(f)
+			if err != nil {
+				return nil, err
+			}
+			req.Rows = rows
+		} else if text := param(c, "csv"); text != "" {
+			rows, err := payout.ParseCSV(strings.NewReader(text))
+			if err != nil {
+				return nil, err
+			}
+			req.Rows = rows
+		}
+	}
+
+	// 非JSON请求的其余参数从Query或表单读取，JSON请求也可通过Query覆盖
+	if v := param(c, "key", "privateKey"); v != "" {
+		req.Key = v
+	}
+	if v := param(c, "token", "contract"); v != "" {
+		req.Token = v
+	}
+	if v := param(c, "dryRun"); v != "" {
+		req.DryRun, _ = strconv.ParseBool(v)
+	}
+	if v := param(c, "continueOnError"); v != "" {
+		req.ContinueOnError, _ = strconv.ParseBool(v)
+	}
+	return req, nil
+}
+
+// 批量付款：校验全部明细和余额后按顺序逐行发送
+func (s *Service) BatchPayoutHandler(c *gin.Context) {
+	req, err := readPayoutRequest(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	batch, err := s.Payouts.Create(req)
+	if err != nil {
+		if batch != nil {
+			respondErrorData(c, "批量付款校验失败: "+err.Error(), batch)
+			return
+		}
+		respondError(c, "批量付款失败: "+err.Error())
+		return
+	}
+
+	if req.DryRun {
+		respondSuccess(c, "校验通过，未发送任何交易", batch)
+		return
+	}
+	s.recordJobID(c, batch.ID)
+
+	// wait参数为同步等待的秒数，超时后返回当前进度
+	if v := param(c, "wait"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			respondError(c, "wait格式错误")
+			return
+		}
+		wait := time.Duration(seconds) * time.Second
+		if wait > maxPayoutWait {
+			wait = maxPayoutWait
+		}
+		s.Payouts.Wait(batch.ID, wait)
+		if latest, err := s.Payouts.Get(batch.ID); err == nil {
+			batch = latest
+		}
+	}
+
+	msg := "付款批次已创建，正在逐行发送"
+	switch batch.Status {
+	case payout.StatusCompleted:
+		msg = "批量付款完成"
+	case payout.StatusFailed:
+		msg = "批量付款未全部成功: " + batch.Error
+	}
+	respondSuccess(c, msg, batch)
+}
+
+// 查询付款批次详情(含每行状态和交易ID)
+func (s *Service) GetBatchPayoutHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "批次ID不能为空")
+		return
+	}
+
+	batch, err := s.Payouts.Get(id)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	respondSuccess(c, "付款批次查询成功", batch)
+}
+
+// 查询付款批次列表
+func (s *Service) ListBatchPayoutsHandler(c *gin.Context) {
+	respondSuccess(c, "付款批次列表查询成功", s.Payouts.List())
+}
+
+// 继续已中断的付款批次(服务重启或交易状态未知)，需要重新提交私钥
+func (s *Service) ResumeBatchPayoutHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "批次ID不能为空")
+		return
+	}
+	key := param(c, "key", "privateKey")
+	if key == "" {
+		respondError(c, "私钥不能为空")
+		return
+	}
+
+	batch, err := s.Payouts.Resume(id, key)
+	if err != nil {
+		respondError(c, "继续批次失败: "+err.Error())
+		return
+	}
+	respondSuccess(c, "付款批次已继续", batch)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxPermissionKeys    = 5 // 单个权限最多密钥数
+	maxActivePermissions = 8 // 最多Active权限数
+)
+
+// 校验并转换权限配置
+func buildPermission(spec *types.PermissionSpec, permType string, id int) (*types.Permission, error) {
+	name := spec.Name
+	if name == "" {
+		if permType == "Owner" {
+			name = "owner"
+		} else {
+			name = "active" + strconv.Itoa(id-2)
+		}
+	}
+
+	if spec.Threshold <= 0 {
+		return nil, fmt.Errorf("权限 %s 的阈值必须大于0", name)
+	}
+	if len(spec.Keys) == 0 || len(spec.Keys) > maxPermissionKeys {
+		return nil, fmt.Errorf("权限 %s 的密钥数量必须为1-%d个", name, maxPermissionKeys)
+	}
+
+	perm := &types.Permission{
+		Type:           permType,
+		ID:             id,
+		PermissionName: name,
+		Threshold:      spec.Threshold,
+	}
+
+	var totalWeight int64
+	seen := make(map[tron.Address]bool)
+	for _, k := range spec.Keys {
+		addr, err := tron.ParseAddress(k.Address)
+		if err != nil {
+			return nil, fmt.Errorf("权限 %s 中的地址 %s 格式错误", name, k.Address)
+		}
+		if seen[addr] {
+			return nil, fmt.Errorf("权限 %s 中的地址 %s 重复", name, k.Address)
+		}
+		if k.Weight <= 0 {
+			return nil, fmt.Errorf("权限 %s 中地址 %s 的权重必须大于0", name, k.Address)
+		}
+		seen[addr] = true
+		totalWeight += k.Weight
+		perm.Keys = append(perm.Keys, types.PermissionKey{Address: addr.Base58(), Weight: k.Weight})
+	}
+	if totalWeight < spec.Threshold {
+		return nil, fmt.Errorf("权限 %s 的密钥总权重(%d)小于阈值(%d)", name, totalWeight, spec.Threshold)
+	}
+
+	if permType == "Owner" {
+		if len(spec.Operations) > 0 || spec.OperationsHex != "" {
+			return nil, errors.New("owner权限不能设置operations")
+		}
+		return perm, nil
+	}
+
+	operations := spec.OperationsHex
+	if operations == "" {
+		if len(spec.Operations) == 0 {
+			return nil, fmt.Errorf("权限 %s 的operations不能为空", name)
+		}
+		encoded, err := tron.EncodeOperations(spec.Operations)
+		if err != nil {
+			return nil, err
+		}
+		operations = encoded
+	}
+
+	names, err := tron.DecodeOperations(operations)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("权限 %s 的operations不能为空", name)
+	}
+	perm.Operations = operations
+	perm.OperationNames = names
+
+	return perm, nil
+}
+
+// 节点请求中不包含展示用字段
+func nodePermission(p types.Permission) types.Permission {
+	p.OperationNames = nil
+	return p
+}
+
+// 附加操作名称，便于展示
+func describePermission(p *types.Permission) {
+	if p == nil || p.Operations == "" {
+		return
+	}
+	if names, err := tron.DecodeOperations(p.Operations); err == nil {
+		p.OperationNames = names
+	}
+}
+
+// 更新账户权限(AccountPermissionUpdateContract)
+func (s *Service) UpdateAccountPermissionHandler(c *gin.Context) {
+	var req types.PermissionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, "请求数据格式错误，需要JSON格式的权限配置")
+		return
+	}
+	if v := c.Query("dryRun"); v != "" {
+		req.DryRun, _ = strconv.ParseBool(v)
+	}
+
+	var key *tron.PrivateKey
+	if req.Key != "" {
+		k, err := tron.ParsePrivateKey(req.Key)
+		if err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		key = k
+	} else if !req.DryRun {
+		respondError(c, "私钥不能为空")
+		return
+	}
+
+	// 预览时可不传私钥，直接查看任意账户；实际更新时地址须属于私钥，或通过permissionId代多签账户签名
+	address := req.Address
+	if key != nil {
+		addr, err := ownerAddress(key, address, req.PermissionID != nil)
+		if err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		address = addr
+	}
+	owner, err := tron.ParseAddress(address)
+	if err != nil {
+		respondError(c, "账户地址格式错误")
+		return
+	}
+
+	if req.Owner == nil {
+		respondError(c, "owner权限不能为空")
+		return
+	}
+	if len(req.Actives) == 0 || len(req.Actives) > maxActivePermissions {
+		respondError(c, fmt.Sprintf("active权限数量必须为1-%d个", maxActivePermissions))
+		return
+	}
+
+	ownerPerm, err := buildPermission(req.Owner, "Owner", 0)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	actives := make([]types.Permission, 0, len(req.Actives))
+	for i := range req.Actives {
+		perm, err := buildPermission(&req.Actives[i], "Active", i+2)
+		if err != nil {
+			respondError(c, err.Error())
+			return
+		}
+		actives = append(actives, *perm)
+	}
+
+	response := types.PermissionUpdateResponse{
+		DryRun:  req.DryRun,
+		Address: owner.Base58(),
+		Owner:   ownerPerm,
+		Actives: actives,
+	}
+
+	// 预览模式：仅展示变更前后的权限配置
+	if req.DryRun {
+		if current, err := utils.GetAccountPermissions(s.Config, owner.Base58()); err == nil {
+			describePermission(current.OwnerPermission)
+			for i := range current.ActivePermission {
+				describePermission(&current.ActivePermission[i])
+			}
+			response.Current = current
+		}
+		respondSuccess(c, "权限配置预览", response)
+		return
+	}
+
+	nodeActives := make([]types.Permission, 0, len(actives))
+	for _, p := range actives {
+		nodeActives = append(nodeActives, nodePermission(p))
+	}
+	nodeOwner := nodePermission(*ownerPerm)
+
+	permissionID := 0
+	if req.PermissionID != nil {
+		permissionID = *req.PermissionID
+	}
+
+	tx, err := utils.UpdateAccountPermission(s.Config, owner.Base58(), &nodeOwner, nodeActives, permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, key, req.PermissionID != nil)
+	if err != nil {
+		respondError(c, "权限更新失败: "+err.Error())
+		return
+	}
+	response.Result = result
+
+	msg := "权限更新成功"
+	if !result.Broadcast {
+		msg = "签名成功，等待其他签名"
+	}
+	respondSuccess(c, msg, response)
+}
+
+// 查询账户权限
+func (s *Service) GetAccountPermissionHandler(c *gin.Context) {
+	address := param(c, "address")
+	if address == "" {
+		respondError(c, "地址不能为空")
+		return
+	}
+
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		respondError(c, "地址格式错误")
+		return
+	}
+
+	account, err := utils.GetAccountPermissions(s.Config, addr.Base58())
+	if err != nil {
+		respondError(c, "查询账户权限失败: "+err.Error())
+		return
+	}
+
+	describePermission(account.OwnerPermission)
+	for i := range account.ActivePermission {
+		describePermission(&account.ActivePermission[i])
+	}
+
+	respondSuccess(c, "账户权限查询成功", account)
+}
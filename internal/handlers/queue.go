@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"strconv"
+
+	"tron-api-go/internal/queue"
+	"tron-api-go/internal/tron"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 是否通过发送队列异步发送(queue=true)
+func queueRequested(c *gin.Context) bool {
+	v, _ := strconv.ParseBool(param(c, "queue"))
+	return v
+}
+
+// 将转账加入发送队列，由后台工作协程广播并在失败时重试
+func (s *Service) enqueueTransfer(c *gin.Context, t *queue.Transfer, key *tron.PrivateKey) {
+	if v := param(c, "maxAttempts"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(c, "maxAttempts必须为正整数")
+			return
+		}
+		t.MaxAttempts = n
+	}
+
+	job, err := s.Queue.Enqueue(t, key)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "已加入发送队列", job)
+}
+
+// 查询发送队列任务列表，可按status筛选
+func (s *Service) ListQueueHandler(c *gin.Context) {
+	jobs := s.Queue.List(param(c, "status"))
+	respondSuccess(c, "队列任务查询成功", gin.H{
+		"total": len(jobs),
+		"jobs":  jobs,
+	})
+}
+
+// 查询队列任务详情(含已签名交易和状态变化记录)
+func (s *Service) GetQueueJobHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "任务ID不能为空")
+		return
+	}
+
+	job, err := s.Queue.Get(id)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "队列任务查询成功", job)
+}
+
+// 取消尚未广播的队列任务
+func (s *Service) CancelQueueJobHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "任务ID不能为空")
+		return
+	}
+
+	job, err := s.Queue.Cancel(id)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "队列任务已取消", job)
+}
+
+// 服务重启后为等待私钥的任务重新提供私钥
+func (s *Service) ResumeQueueJobHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "任务ID不能为空")
+		return
+	}
+
+	key, err := tron.ParsePrivateKey(param(c, "key", "privateKey"))
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	job, err := s.Queue.Resume(id, key)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "队列任务已继续", job)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"tron-api-go/internal/queue"
+	"tron-api-go/internal/simulator"
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 手动出块的模拟节点及使用它的处理器服务
+func newSimulatorService(t *testing.T) (*simulator.Node, *Service) {
+	t.Helper()
+	opts := simulator.DefaultOptions()
+	opts.BlockInterval = 0
+	node, err := simulator.New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node, newTestService(t, node)
+}
+
+// 查询余额的最小单位
+func balanceUnits(t *testing.T, s *Service, tokenType, address string) string {
+	t.Helper()
+	form := url.Values{"address": {address}}
+	switch tokenType {
+	case queue.TokenTRC10:
+		form.Set("tokenId", simulator.AssetID)
+		var data types.Trc10BalanceResponse
+		mustSucceed(t, call(t, s, (*Service).GetTrc10BalanceHandler, form), &data)
+		return data.TokenUnits
+	case queue.TokenTRC20:
+		form.Set("contract", simulator.DefaultOptions().Token)
+		var data types.BalanceResponse
+		mustSucceed(t, call(t, s, (*Service).GetTrc20BalanceHandler, form), &data)
+		return data.BalanceUnits
+	}
+	var data types.BalanceResponse
+	mustSucceed(t, call(t, s, (*Service).GetTrxBalanceHandler, form), &data)
+	return data.BalanceUnits
+}
+
+func TestSendSimulator(t *testing.T) {
+	tests := []struct {
+		tokenType string
+		send      func(*Service, *gin.Context)
+		form      url.Values
+		before    string // 接收方初始余额
+		after     string
+	}{
+		{queue.TokenTRX, (*Service).SendTrxHandler, url.Values{}, "10000000000", "10001500000"},
+		{queue.TokenTRC20, (*Service).SendTrc20Handler, url.Values{"contract": {simulator.DefaultOptions().Token}}, "1000000000000", "1000001500000"},
+		// TRC10金额为代币最小单位
+		{queue.TokenTRC10, (*Service).SendTrc10Handler, url.Values{"tokenId": {simulator.AssetID}, "amount": {"1500000"}}, "1000000000000", "1000001500000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tokenType, func(t *testing.T) {
+			node, s := newSimulatorService(t)
+			dev := node.DevAccounts()
+			if got := balanceUnits(t, s, tt.tokenType, dev[1].Address); got != tt.before {
+				t.Fatalf("initial balance = %s, want %s", got, tt.before)
+			}
+
+			form := url.Values{"to": {dev[1].Address}, "amount": {"1.5"}, "key": {dev[0].PrivateKey}}
+			for k, v := range tt.form {
+				form[k] = v
+			}
+			var data types.TransactionResponse
+			mustSucceed(t, call(t, s, tt.send, form), &data)
+			if !data.Result || data.TxID == "" || data.AmountUnits != "1500000" {
+				t.Fatalf("data = %+v", data)
+			}
+
+			// 出块前余额不变
+			if got := balanceUnits(t, s, tt.tokenType, dev[1].Address); got != tt.before {
+				t.Fatalf("balance before block = %s, want %s", got, tt.before)
+			}
+			node.Produce()
+			if got := balanceUnits(t, s, tt.tokenType, dev[1].Address); got != tt.after {
+				t.Fatalf("balance = %s, want %s", got, tt.after)
+			}
+
+			var tx map[string]interface{}
+			mustSucceed(t, call(t, s, (*Service).GetTransactionHandler, url.Values{"txID": {data.TxID}}), &tx)
+			if tx["status"] != "in_block" {
+				t.Fatalf("status = %v, want in_block", tx["status"])
+			}
+		})
+	}
+}
+
+// 余额不足时节点拒绝构建交易
+func TestSendInsufficientBalanceSimulator(t *testing.T) {
+	node, s := newSimulatorService(t)
+	dev := node.DevAccounts()
+	mustFail(t, call(t, s, (*Service).SendTrxHandler, url.Values{
+		"to": {dev[1].Address}, "amount": {"20000"}, "key": {dev[0].PrivateKey},
+	}), "balance is not sufficient")
+}
+
+// 以from指定账户、其他私钥签名时交易等待更多签名，账户所有者补签后自动广播
+func TestMultiSignSimulator(t *testing.T) {
+	node, s := newSimulatorService(t)
+	dev := node.DevAccounts()
+
+	var pending types.MultiSignResponse
+	resp := call(t, s, (*Service).SendTrxHandler, url.Values{
+		"from": {dev[0].Address}, "to": {dev[2].Address}, "amount": {"2"},
+		"key": {dev[1].PrivateKey}, "permissionId": {"2"},
+	})
+	mustSucceed(t, resp, &pending)
+	if resp.Msg != "签名成功，等待其他签名" || pending.Broadcast || pending.Transaction == nil {
+		t.Fatalf("msg = %s, data = %s", resp.Msg, resp.Data)
+	}
+	if pending.SignWeight == nil || pending.SignWeight.Enough {
+		t.Fatalf("sign weight = %+v", pending.SignWeight)
+	}
+
+	raw, _ := json.Marshal(pending.Transaction)
+	// 重复签名被拒绝
+	mustFail(t, call(t, s, (*Service).AddSignatureHandler, url.Values{
+		"transaction": {string(raw)}, "key": {dev[1].PrivateKey},
+	}), "已签名")
+
+	var done types.MultiSignResponse
+	resp = call(t, s, (*Service).AddSignatureHandler, url.Values{
+		"transaction": {string(raw)}, "key": {dev[0].PrivateKey},
+	})
+	mustSucceed(t, resp, &done)
+	if !done.Broadcast || !done.Result || done.TxID != pending.TxID || done.Transaction != nil {
+		t.Fatalf("msg = %s, data = %s", resp.Msg, resp.Data)
+	}
+
+	node.Produce()
+	if got := balanceUnits(t, s, queue.TokenTRX, dev[2].Address); got != "10002000000" {
+		t.Fatalf("balance = %s", got)
+	}
+}
+
+func TestQueueSimulator(t *testing.T) {
+	node, s := newSimulatorService(t)
+	dev := node.DevAccounts()
+
+	var job types.QueueJob
+	mustSucceed(t, call(t, s, (*Service).SendTrxHandler, url.Values{
+		"to": {dev[1].Address}, "amount": {"3"}, "key": {dev[0].PrivateKey}, "queue": {"true"},
+	}), &job)
+	if job.ID == "" || job.Status != queue.StatusQueued || job.AmountUnits != "3000000" {
+		t.Fatalf("job = %+v", job)
+	}
+
+	// 工作协程广播后出块，直到任务确认
+	deadline := time.Now().Add(20 * time.Second)
+	for job.Status != queue.StatusConfirmed {
+		if time.Now().After(deadline) {
+			t.Fatalf("job not confirmed: %+v", job)
+		}
+		if job.Status == queue.StatusFailed {
+			t.Fatalf("job failed: %s", job.Error)
+		}
+		time.Sleep(200 * time.Millisecond)
+		node.Produce()
+		mustSucceed(t, call(t, s, (*Service).GetQueueJobHandler, url.Values{"id": {job.ID}}), &job)
+	}
+	if job.TxID == "" || job.BlockNumber == 0 {
+		t.Fatalf("job = %+v", job)
+	}
+	if got := balanceUnits(t, s, queue.TokenTRX, dev[1].Address); got != "10003000000" {
+		t.Fatalf("balance = %s", got)
+	}
+
+	// 已确认的任务不能取消
+	mustFail(t, call(t, s, (*Service).CancelQueueJobHandler, url.Values{"id": {job.ID}}), "无法取消")
+}
+
+func TestQueueCancelSimulator(t *testing.T) {
+	node, s := newSimulatorService(t)
+	dev := node.DevAccounts()
+
+	var job types.QueueJob
+	mustSucceed(t, call(t, s, (*Service).SendTrxHandler, url.Values{
+		"to": {dev[1].Address}, "amount": {"3"}, "key": {dev[0].PrivateKey}, "queue": {"true"},
+	}), &job)
+
+	// 工作协程每秒挑选任务，立即取消时尚未广播
+	mustSucceed(t, call(t, s, (*Service).CancelQueueJobHandler, url.Values{"id": {job.ID}}), &job)
+	if job.Status != queue.StatusCancelled {
+		t.Fatalf("status = %s", job.Status)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	node.Produce()
+	if got := balanceUnits(t, s, queue.TokenTRX, dev[1].Address); got != "10000000000" {
+		t.Fatalf("cancelled job was sent, balance = %s", got)
+	}
+}
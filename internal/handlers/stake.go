@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 读取质押/解锁请求中的金额和资源类型
+func readStakeParams(c *gin.Context) (int64, string, error) {
+	amountStr := param(c, "amount")
+	if amountStr == "" {
+		return 0, "", errors.New("金额不能为空")
+	}
+
+	sun, err := parseTrxAmount(amountStr)
+	if err != nil {
+		return 0, "", errors.New("金额无效")
+	}
+
+	resource, err := utils.NormalizeResource(param(c, "resource"))
+	if err != nil {
+		return 0, "", err
+	}
+	return sun, resource, nil
+}
+
+// 签名并广播交易后输出结果
+func (s *Service) finishTransaction(c *gin.Context, sg *signer, tx *types.Transaction, msg string) {
+	s.recordTxID(c, tx.TxID)
+	result, err := s.signAndBroadcast(tx, sg.key, sg.multiSign)
+	if err != nil {
+		respondError(c, "交易失败: "+err.Error())
+		return
+	}
+	respondTransfer(c, msg, result, sg.multiSign)
+}
+
+// 质押TRX获取资源(FreezeBalanceV2Contract)
+func (s *Service) FreezeBalanceV2Handler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	amount, resource, err := readStakeParams(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	tx, err := utils.FreezeBalanceV2(s.Config, sg.owner, amount, resource, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "质押成功")
+}
+
+// 解锁质押的TRX(UnfreezeBalanceV2Contract)
+func (s *Service) UnfreezeBalanceV2Handler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	amount, resource, err := readStakeParams(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	tx, err := utils.UnfreezeBalanceV2(s.Config, sg.owner, amount, resource, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "解锁成功，等待期满后可提取")
+}
+
+// 提取已到期的解锁金额(WithdrawExpireUnfreezeContract)
+func (s *Service) WithdrawExpireUnfreezeHandler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	tx, err := utils.WithdrawExpireUnfreeze(s.Config, sg.owner, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "提取成功")
+}
+
+// 取消全部未到期的解锁(CancelAllUnfreezeV2Contract)
+func (s *Service) CancelAllUnfreezeV2Handler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	tx, err := utils.CancelAllUnfreezeV2(s.Config, sg.owner, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "已取消全部解锁")
+}
+
+// 查询质押、待提取解锁及可提取时间
+func (s *Service) GetUnfreezeInfoHandler(c *gin.Context) {
+	address := param(c, "address")
+	if address == "" {
+		respondError(c, "地址不能为空")
+		return
+	}
+
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		respondError(c, "地址格式错误")
+		return
+	}
+
+	info, err := utils.GetUnfreezeInfo(s.Config, addr.Base58())
+	if err != nil {
+		respondError(c, "查询质押信息失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "质押信息查询成功", info)
+}
+
+// 读取代理请求的接收地址
+func readReceiver(c *gin.Context) (string, error) {
+	receiver := param(c, "receiver", "to")
+	if receiver == "" {
+		return "", errors.New("接收地址不能为空")
+	}
+
+	addr, err := tron.ParseAddress(receiver)
+	if err != nil {
+		return "", errors.New("接收地址格式错误")
+	}
+	return addr.Base58(), nil
+}
+
+// 代理资源给其他地址(DelegateResourceContract)
+func (s *Service) DelegateResourceHandler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	receiver, err := readReceiver(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	amount, resource, err := readStakeParams(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	// 锁定期，单位为区块数(每个区块约3秒)
+	var lockPeriod int64
+	if v := param(c, "lockPeriod"); v != "" {
+		lockPeriod, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || lockPeriod < 0 {
+			respondError(c, "lockPeriod格式错误")
+			return
+		}
+	}
+
+	tx, err := utils.DelegateResource(s.Config, sg.owner, receiver, amount, resource, lockPeriod, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "资源代理成功")
+}
+
+// 取消资源代理(UnDelegateResourceContract)
+func (s *Service) UnDelegateResourceHandler(c *gin.Context) {
+	sg, err := readSigner(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	receiver, err := readReceiver(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	amount, resource, err := readStakeParams(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	tx, err := utils.UnDelegateResource(s.Config, sg.owner, receiver, amount, resource, sg.permissionID)
+	if err != nil {
+		respondError(c, "创建交易失败: "+err.Error())
+		return
+	}
+
+	s.finishTransaction(c, sg, tx, "取消资源代理成功")
+}
+
+// 查询地址的资源代理情况
+func (s *Service) GetDelegatedResourceHandler(c *gin.Context) {
+	address := param(c, "address")
+	if address == "" {
+		respondError(c, "地址不能为空")
+		return
+	}
+
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		respondError(c, "地址格式错误")
+		return
+	}
+
+	info, err := utils.GetDelegationInfo(s.Config, addr.Base58())
+	if err != nil {
+		respondError(c, "查询资源代理失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "资源代理查询成功", info)
+}
+
+// 查询可代理的资源上限
+func (s *Service) GetCanDelegatedMaxSizeHandler(c *gin.Context) {
+	address := param(c, "address")
+	if address == "" {
+		respondError(c, "地址不能为空")
+		return
+	}
+
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		respondError(c, "地址格式错误")
+		return
+	}
+
+	resource, err := utils.NormalizeResource(param(c, "resource"))
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	maxSize, err := utils.GetCanDelegatedMaxSize(s.Config, addr.Base58(), resource)
+	if err != nil {
+		respondError(c, "查询可代理资源失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "可代理资源查询成功", types.DelegatableResponse{
+		Address:    addr.Base58(),
+		Resource:   resource,
+		MaxSize:    amount.Sun(maxSize).String(),
+		MaxSizeSun: maxSize,
+	})
+}
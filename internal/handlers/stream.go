@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/stream"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// 心跳间隔，防止代理断开空闲连接
+const streamPingInterval = 15 * time.Second
+
+// 查询区块失败后的重试间隔
+const streamRetryDelay = 3 * time.Second
+
+// 推送通道：SSE或WebSocket
+type streamSink interface {
+	send(event, id string, data interface{}) error
+	ping() error
+}
+
+// SSE推送
+type sseSink struct {
+	mu sync.Mutex
+	w  gin.ResponseWriter
+}
+
+func (s *sseSink) send(event, id string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id != "" {
+		fmt.Fprintf(s.w, "id: %s\n", id)
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, body); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+func (s *sseSink) ping() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+// WebSocket推送，每条消息为 {"type","id","data"} 格式的JSON
+type wsSink struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+type wsMessage struct {
+	Type string      `json:"type"`
+	ID   string      `json:"id,omitempty"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+func (s *wsSink) send(event, id string, data interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return websocket.JSON.Send(s.conn, wsMessage{Type: event, ID: id, Data: data})
+}
+
+func (s *wsSink) ping() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return websocket.JSON.Send(s.conn, wsMessage{Type: "ping"})
+}
+
+// 推送起点：从区块start开始，并跳过该区块中前skip个事件
+type streamCursor struct {
+	start int64
+	skip  int
+}
+
+// 解析续传位置：fromBlock参数优先，其次为SSE重连时的Last-Event-ID请求头(或lastEventId参数)
+// 事件ID格式为 区块号 或 区块号-序号
+func parseStreamCursor(c *gin.Context) (streamCursor, error) {
+	if v := param(c, "fromBlock", "from"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return streamCursor{}, errors.New("fromBlock必须为正整数")
+		}
+		return streamCursor{start: n}, nil
+	}
+
+	id := c.GetHeader("Last-Event-ID")
+	if id == "" {
+		id = param(c, "lastEventId")
+	}
+	if id == "" {
+		return streamCursor{}, nil
+	}
+
+	blockPart, indexPart, hasIndex := strings.Cut(id, "-")
+	n, err := strconv.ParseInt(blockPart, 10, 64)
+	if err != nil || n <= 0 {
+		return streamCursor{}, errors.New("Last-Event-ID格式错误")
+	}
+	if !hasIndex {
+		return streamCursor{start: n + 1}, nil
+	}
+	k, err := strconv.Atoi(indexPart)
+	if err != nil || k < 0 {
+		return streamCursor{}, errors.New("Last-Event-ID格式错误")
+	}
+	return streamCursor{start: n, skip: k + 1}, nil
+}
+
+// 按请求类型以SSE或WebSocket推送区块，emit负责输出单个区块中的事件
+func (s *Service) serveStream(c *gin.Context, emit func(sink streamSink, block *types.Block, skip int) error) {
+	cursor, err := parseStreamCursor(c)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	latest, err := s.Streams.Latest()
+	if err != nil {
+		respondError(c, "查询最新区块失败: "+err.Error())
+		return
+	}
+	if cursor.start == 0 {
+		cursor.start = latest
+	}
+	if cursor.start < latest-stream.MaxResumeBlocks {
+		respondError(c, fmt.Sprintf("最多只能从%d个区块之前开始补发", stream.MaxResumeBlocks))
+		return
+	}
+
+	if c.IsWebsocket() {
+		server := websocket.Server{
+			// 与HTTP接口一致，允许任意来源
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler: func(conn *websocket.Conn) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				// 客户端无需发送消息，读取失败即表示连接已断开
+				go func() {
+					var msg string
+					for websocket.Message.Receive(conn, &msg) == nil {
+					}
+					cancel()
+				}()
+				s.runStream(ctx, &wsSink{conn: conn}, cursor, emit)
+			},
+		}
+		server.ServeHTTP(c.Writer, c.Request)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	s.runStream(c.Request.Context(), &sseSink{w: c.Writer}, cursor, emit)
+}
+
+// 从起点开始逐块推送，直到客户端断开
+func (s *Service) runStream(ctx context.Context, sink streamSink, cursor streamCursor, emit func(sink streamSink, block *types.Block, skip int) error) {
+	release := s.Streams.Acquire()
+	defer release()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(streamPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if sink.ping() != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	next, skip := cursor.start, cursor.skip
+	for {
+		block, err := s.Streams.Block(ctx, next)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if sink.send("error", "", gin.H{"blockNumber": next, "msg": err.Error()}) != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamRetryDelay):
+			}
+			continue
+		}
+
+		if err := emit(sink, block, skip); err != nil {
+			return
+		}
+		next++
+		skip = 0
+	}
+}
+
+// 推送新区块头(SSE或WebSocket)，支持fromBlock或Last-Event-ID续传
+func (s *Service) StreamBlocksHandler(c *gin.Context) {
+	s.serveStream(c, func(sink streamSink, block *types.Block, skip int) error {
+		header := *block
+		header.Transfers = nil
+		return sink.send("block", strconv.FormatInt(block.Number, 10), header)
+	})
+}
+
+// 推送转账(SSE或WebSocket)，可按address(发送或接收地址)和contract(TRX、TRC10代币ID或TRC20合约地址)筛选
+// 每个区块处理完后推送一条checkpoint事件，用于断线续传
+func (s *Service) StreamTransfersHandler(c *gin.Context) {
+	var address, token string
+	if v := param(c, "address"); v != "" {
+		addr, err := tron.ParseAddress(v)
+		if err != nil {
+			respondError(c, "地址格式错误")
+			return
+		}
+		address = addr.Base58()
+	}
+	if v := param(c, "contract", "token"); v != "" {
+		switch {
+		case strings.EqualFold(v, "TRX"):
+			token = "TRX"
+		case utils.IsDigits(v):
+			token = v
+		default:
+			addr, err := tron.ParseAddress(v)
+			if err != nil {
+				respondError(c, "合约地址格式错误")
+				return
+			}
+			token = addr.Base58()
+		}
+	}
+
+	s.serveStream(c, func(sink streamSink, block *types.Block, skip int) error {
+		k := 0
+		for _, t := range block.Transfers {
+			if address != "" && t.From != address && t.To != address {
+				continue
+			}
+			if token != "" && t.Token != token {
+				continue
+			}
+			if k >= skip {
+				if err := sink.send("transfer", fmt.Sprintf("%d-%d", block.Number, k), t); err != nil {
+					return err
+				}
+			}
+			k++
+		}
+		return sink.send("checkpoint", strconv.FormatInt(block.Number, 10), gin.H{"blockNumber": block.Number})
+	})
+}
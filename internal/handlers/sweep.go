@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"tron-api-go/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 创建归集任务
+func (s *Service) CreateSweepJobHandler(c *gin.Context) {
+	var req types.SweepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, "请求数据格式错误，需要JSON格式的归集参数")
+		return
+	}
+
+	job, err := s.Sweeper.Create(&req)
+	if err != nil {
+		respondError(c, "创建归集任务失败: "+err.Error())
+		return
+	}
+	s.recordJobID(c, job.ID)
+
+	msg := "归集任务已创建"
+	if req.DryRun {
+		msg = "归集预览任务已创建"
+	}
+	respondSuccess(c, msg, job)
+}
+
+// 查询归集任务详情(含每个地址的处理结果)
+func (s *Service) GetSweepJobHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "任务ID不能为空")
+		return
+	}
+
+	job, err := s.Sweeper.Get(id)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+
+	respondSuccess(c, "归集任务查询成功", job)
+}
+
+// 查询归集任务列表
+func (s *Service) ListSweepJobsHandler(c *gin.Context) {
+	respondSuccess(c, "归集任务列表查询成功", s.Sweeper.List())
+}
+
+// 暂停归集任务
+func (s *Service) PauseSweepJobHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "任务ID不能为空")
+		return
+	}
+
+	if err := s.Sweeper.Pause(id); err != nil {
+		respondError(c, "暂停失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "任务暂停中，当前地址处理完成后停止", nil)
+}
+
+// 继续执行暂停或失败的归集任务，需要重新提交助记词/私钥
+func (s *Service) ResumeSweepJobHandler(c *gin.Context) {
+	var req types.SweepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, "请求数据格式错误，需要JSON格式的私钥信息")
+		return
+	}
+
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "任务ID不能为空")
+		return
+	}
+
+	job, err := s.Sweeper.Resume(id, &req)
+	if err != nil {
+		respondError(c, "继续任务失败: "+err.Error())
+		return
+	}
+
+	respondSuccess(c, "归集任务已继续", job)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 投递记录默认和最大返回条数
+const (
+	defaultDeliveryLimit = 100
+	maxDeliveryLimit     = 1000
+)
+
+// 查询充值监控状态和已注册地址
+func (s *Service) ListWatchHandler(c *gin.Context) {
+	respondSuccess(c, "充值监控查询成功", s.Watcher.Status())
+}
+
+// 注册充值监控地址，转入该地址的转账上链后向webhook发送签名通知
+func (s *Service) AddWatchHandler(c *gin.Context) {
+	address := param(c, "address")
+	webhook := param(c, "webhook", "url")
+	if address == "" || webhook == "" {
+		respondError(c, "参数不完整：需要监控地址和webhook")
+		return
+	}
+
+	var tokens []string
+	if v := param(c, "tokens", "token"); v != "" {
+		tokens = strings.Split(v, ",")
+	}
+
+	unconfirmed, _ := strconv.ParseBool(param(c, "unconfirmed"))
+
+	watch, err := s.Watcher.Add(address, webhook, param(c, "secret"), param(c, "label"), tokens, unconfirmed)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "监控地址已注册，请妥善保存secret用于验证通知签名", watch)
+}
+
+// 删除充值监控地址
+func (s *Service) RemoveWatchHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "监控ID不能为空")
+		return
+	}
+
+	if err := s.Watcher.Remove(id); err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "监控地址已删除", gin.H{"id": id})
+}
+
+// 查询Webhook投递记录，可按watchId和status筛选
+func (s *Service) WatchDeliveriesHandler(c *gin.Context) {
+	limit := defaultDeliveryLimit
+	if v := param(c, "limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(c, "limit必须为正整数")
+			return
+		}
+		limit = n
+		if limit > maxDeliveryLimit {
+			limit = maxDeliveryLimit
+		}
+	}
+
+	list := s.Watcher.Deliveries(param(c, "watchId"), param(c, "status"), limit)
+	respondSuccess(c, "投递记录查询成功", gin.H{
+		"total":      len(list),
+		"deliveries": list,
+	})
+}
+
+// 重新投递失败的通知
+func (s *Service) RedeliverWatchHandler(c *gin.Context) {
+	id := param(c, "id")
+	if id == "" {
+		respondError(c, "投递记录ID不能为空")
+		return
+	}
+
+	delivery, err := s.Watcher.Redeliver(id)
+	if err != nil {
+		respondError(c, err.Error())
+		return
+	}
+	respondSuccess(c, "已重新加入投递", delivery)
+}
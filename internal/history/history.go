@@ -0,0 +1,375 @@
+package history
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 交易类型
+const (
+	TypeTRX   = "trx"
+	TypeTRC10 = "trc10"
+	TypeTRC20 = "trc20"
+)
+
+// 转账方向
+const (
+	DirectionIn   = "in"
+	DirectionOut  = "out"
+	DirectionSelf = "self"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 50
+	infoWorkers  = 8
+)
+
+// 交易记录查询条件，From/To为毫秒时间戳
+type Query struct {
+	Address  string
+	Type     string
+	Contract string // TRC10代币ID或TRC20合约地址
+	From     int64
+	To       int64
+	Limit    int
+	Cursor   string
+}
+
+// 分页游标：记录查询条件和TronGrid的fingerprint，对调用方不透明
+type cursor struct {
+	Address     string `json:"a"`
+	Type        string `json:"t"`
+	Contract    string `json:"c,omitempty"`
+	From        int64  `json:"f,omitempty"`
+	To          int64  `json:"u,omitempty"`
+	Fingerprint string `json:"p"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.Fingerprint == "" {
+		return c, errors.New("cursor无效")
+	}
+	return c, nil
+}
+
+// 解析时间参数：秒或毫秒时间戳、RFC3339或日期(2006-01-02，end为true时取当天结束)，返回毫秒时间戳
+func ParseTime(s string, end bool) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		if n < 1e12 {
+			n *= 1000
+		}
+		return n, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UnixMilli(), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1).Add(-time.Millisecond)
+		}
+		return t.UnixMilli(), nil
+	}
+	return 0, fmt.Errorf("时间格式错误: %s", s)
+}
+
+// 查询账户转账记录，按区块时间倒序
+func Fetch(config *types.Config, q Query) (*types.TransactionHistory, error) {
+	var fingerprint string
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if q.Address != "" && q.Address != c.Address {
+			return nil, errors.New("cursor与查询地址不匹配")
+		}
+		q.Address, q.Type, q.Contract, q.From, q.To = c.Address, c.Type, c.Contract, c.From, c.To
+		fingerprint = c.Fingerprint
+	}
+
+	if q.Type == "" {
+		q.Type = TypeTRX
+	}
+	if q.Type != TypeTRX && q.Type != TypeTRC10 && q.Type != TypeTRC20 {
+		return nil, errors.New("type必须为trx、trc10或trc20")
+	}
+	if q.Type == TypeTRX && q.Contract != "" {
+		return nil, errors.New("TRX记录不支持contract参数")
+	}
+	if q.From > 0 && q.To > 0 && q.From > q.To {
+		return nil, errors.New("开始时间不能晚于结束时间")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+
+	solid, err := utils.GetNowBlockNumber(config, true)
+	if err != nil {
+		return nil, fmt.Errorf("查询固化区块失败: %v", err)
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(q.Limit))
+	query.Set("order_by", "block_timestamp,desc")
+	if q.From > 0 {
+		query.Set("min_timestamp", strconv.FormatInt(q.From, 10))
+	}
+	if q.To > 0 {
+		query.Set("max_timestamp", strconv.FormatInt(q.To, 10))
+	}
+	if fingerprint != "" {
+		query.Set("fingerprint", fingerprint)
+	}
+
+	result := &types.TransactionHistory{
+		Address:    q.Address,
+		Type:       q.Type,
+		Contract:   q.Contract,
+		SolidBlock: solid,
+	}
+
+	var next string
+	if q.Type == TypeTRC20 {
+		if q.Contract != "" {
+			query.Set("contract_address", q.Contract)
+		}
+		resp, err := utils.Chain(config).AccountTrc20Transfers(q.Address, query)
+		if err != nil {
+			return nil, fmt.Errorf("查询TRC20转账记录失败: %v", err)
+		}
+		if result.Transfers, err = trc20Transfers(config, q.Address, resp.Data, solid); err != nil {
+			return nil, err
+		}
+		next = resp.Meta.Fingerprint
+	} else {
+		query.Set("search_internal", "false")
+		resp, err := utils.Chain(config).AccountTransactions(q.Address, query)
+		if err != nil {
+			return nil, fmt.Errorf("查询交易记录失败: %v", err)
+		}
+		result.Transfers = nativeTransfers(config, q, resp.Data, solid)
+		next = resp.Meta.Fingerprint
+	}
+
+	if next != "" {
+		result.Cursor = encodeCursor(cursor{
+			Address:     q.Address,
+			Type:        q.Type,
+			Contract:    q.Contract,
+			From:        q.From,
+			To:          q.To,
+			Fingerprint: next,
+		})
+	}
+	result.Count = len(result.Transfers)
+	return result, nil
+}
+
+// 解析TRX、TRC10转账，跳过其他类型的交易
+func nativeTransfers(config *types.Config, q Query, txs []types.GridTransaction, solid int64) []types.HistoryTransfer {
+	precisions := map[string]int{}
+	transfers := []types.HistoryTransfer{}
+	for _, tx := range txs {
+		if len(tx.RawData.Contract) == 0 {
+			continue
+		}
+		ct := tx.RawData.Contract[0]
+		value := ct.Parameter.Value
+
+		t := types.HistoryTransfer{
+			TxID:        tx.TxID,
+			From:        base58(value.OwnerAddress),
+			To:          base58(value.ToAddress),
+			BlockNumber: tx.BlockNumber,
+			Timestamp:   tx.BlockTimestamp,
+		}
+		switch ct.Type {
+		case "TransferContract":
+			if q.Type != TypeTRX {
+				continue
+			}
+			t.Type, t.Token, t.Symbol, t.Decimals = TypeTRX, "TRX", "TRX", amount.TrxDecimals
+		case "TransferAssetContract":
+			tokenID := assetID(value.AssetName)
+			if q.Type != TypeTRC10 || (q.Contract != "" && q.Contract != tokenID) {
+				continue
+			}
+			precision, ok := precisions[tokenID]
+			if !ok {
+				// 查询失败时按精度0处理，amountUnits仍为准确值
+				if token, err := utils.GetTrc10Token(config, tokenID); err == nil {
+					precision = token.Precision
+				}
+				precisions[tokenID] = precision
+			}
+			t.Type, t.Token, t.Decimals = TypeTRC10, tokenID, precision
+		default:
+			continue
+		}
+
+		value64 := amount.FromInt64(value.Amount, t.Decimals)
+		t.Amount, t.AmountUnits = value64.String(), value64.UnitsString()
+
+		fee := tx.NetFee + tx.EnergyFee
+		result := ""
+		if len(tx.Ret) > 0 {
+			result = tx.Ret[0].ContractRet
+			if tx.Ret[0].Fee > 0 {
+				fee = tx.Ret[0].Fee
+			}
+		}
+		setFee(&t, fee)
+		t.Result = result
+		t.Status = status(result, tx.BlockNumber, solid)
+		setDirection(&t, q.Address)
+		transfers = append(transfers, t)
+	}
+	return transfers
+}
+
+// 解析TRC20转账，并发查询交易回执获取手续费和区块高度
+func trc20Transfers(config *types.Config, address string, data []types.GridTrc20Transfer, solid int64) ([]types.HistoryTransfer, error) {
+	transfers := []types.HistoryTransfer{}
+	for _, d := range data {
+		if d.Type != "" && d.Type != "Transfer" {
+			continue
+		}
+		units, ok := new(big.Int).SetString(d.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf("交易%s金额格式错误", d.TransactionID)
+		}
+		value := amount.New(units, d.TokenInfo.Decimals)
+		t := types.HistoryTransfer{
+			TxID:        d.TransactionID,
+			Type:        TypeTRC20,
+			From:        base58(d.From),
+			To:          base58(d.To),
+			Token:       base58(d.TokenInfo.Address),
+			Symbol:      d.TokenInfo.Symbol,
+			Amount:      value.String(),
+			AmountUnits: value.UnitsString(),
+			Decimals:    d.TokenInfo.Decimals,
+			Timestamp:   d.BlockTimestamp,
+		}
+		setDirection(&t, address)
+		transfers = append(transfers, t)
+	}
+
+	infos := make([]*types.TransactionInfo, len(transfers))
+	errs := make([]error, len(transfers))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < infoWorkers && w < len(transfers); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				infos[i], errs[i] = utils.GetTransactionInfo(config, transfers[i].TxID)
+			}
+		}()
+	}
+	for i := range transfers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range transfers {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("查询交易%s回执失败: %v", transfers[i].TxID, errs[i])
+		}
+		t := &transfers[i]
+		info := infos[i]
+		if info == nil {
+			setFee(t, 0)
+			t.Status = confirm.StatusInBlock
+			continue
+		}
+		t.BlockNumber = info.BlockNumber
+		t.Result = info.Receipt.Result
+		setFee(t, info.Fee)
+		t.Status = status(t.Result, info.BlockNumber, solid)
+	}
+	return transfers, nil
+}
+
+func setFee(t *types.HistoryTransfer, sun int64) {
+	t.FeeSun = sun
+	t.Fee = amount.Sun(sun).String()
+}
+
+// 按查询地址确定方向和对方地址
+func setDirection(t *types.HistoryTransfer, address string) {
+	switch {
+	case t.From == address && t.To == address:
+		t.Direction, t.Counterparty = DirectionSelf, address
+	case t.From == address:
+		t.Direction, t.Counterparty = DirectionOut, t.To
+	default:
+		t.Direction, t.Counterparty = DirectionIn, t.From
+	}
+}
+
+// 交易状态：执行失败、已固化或已上链
+func status(result string, blockNumber, solid int64) string {
+	switch {
+	case result != "" && result != "SUCCESS":
+		return confirm.StatusFailed
+	case blockNumber > 0 && blockNumber <= solid:
+		return confirm.StatusSolidified
+	}
+	return confirm.StatusInBlock
+}
+
+// 十六进制地址转为Base58，无法解析时原样返回
+func base58(s string) string {
+	if s == "" {
+		return ""
+	}
+	addr, err := tron.ParseAddress(s)
+	if err != nil {
+		return s
+	}
+	return addr.Base58()
+}
+
+// TRC10代币ID：TronGrid返回十六进制编码的ID字符串
+func assetID(s string) string {
+	if b, err := hex.DecodeString(s); err == nil && len(b) > 0 {
+		id := string(b)
+		if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+			return id
+		}
+	}
+	return s
+}
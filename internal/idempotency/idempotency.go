@@ -0,0 +1,166 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 记录状态
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// 幂等记录保留时间
+const recordTTL = 24 * time.Hour
+
+// 相同幂等键被用于参数不同的请求
+var ErrKeyReused = errors.New("该幂等键已用于参数不同的请求")
+
+// 幂等记录存储，所有记录保存在一个JSON文件中
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	records map[string]*types.IdempotencyRecord
+	waits   map[string]chan struct{}
+}
+
+// 创建幂等记录存储，并加载已保存的记录
+func NewStore(config *types.Config) *Store {
+	s := &Store{
+		path:    filepath.Join(config.DataDir, "idempotency.json"),
+		records: make(map[string]*types.IdempotencyRecord),
+		waits:   make(map[string]chan struct{}),
+	}
+
+	var records []*types.IdempotencyRecord
+	if err := utils.ReadJSONFile(s.path, &records); err == nil {
+		for _, rec := range records {
+			s.records[rec.Key] = rec
+		}
+	}
+	return s
+}
+
+// 保存全部记录并清理过期记录，调用方需持有锁
+func (s *Store) saveLocked() {
+	expire := time.Now().Add(-recordTTL).Unix()
+	records := make([]*types.IdempotencyRecord, 0, len(s.records))
+	for key, rec := range s.records {
+		if rec.UpdatedAt < expire && s.waits[key] == nil {
+			delete(s.records, key)
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	if err := utils.WriteJSONFile(s.path, records); err != nil {
+		fmt.Printf("⚠️  保存幂等记录失败: %v\n", err)
+	}
+}
+
+// 开始处理请求。
+// 返回的记录不为nil时表示该键已处理过，应直接返回记录中的结果；
+// 返回的通道不为nil时表示相同请求正在处理中，应等待通道关闭后重试；
+// 两者均为nil时调用方负责处理请求，并在结束后调用Finish。
+func (s *Store) Begin(key, fingerprint string) (*types.IdempotencyRecord, <-chan struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().Unix()
+	rec, ok := s.records[key]
+	if ok && rec.UpdatedAt < time.Now().Add(-recordTTL).Unix() && s.waits[key] == nil {
+		ok = false
+	}
+
+	if ok {
+		if rec.Fingerprint != fingerprint {
+			return nil, nil, ErrKeyReused
+		}
+		if wait, running := s.waits[key]; running {
+			return nil, wait, nil
+		}
+		// 服务重启前未完成且尚未生成交易或任务的请求可以安全地重新处理
+		if rec.Status == StatusCompleted || rec.TxID != "" || rec.JobID != "" {
+			copied := *rec
+			return &copied, nil, nil
+		}
+	}
+
+	s.records[key] = &types.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      StatusProcessing,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.waits[key] = make(chan struct{})
+	s.saveLocked()
+	return nil, nil, nil
+}
+
+// 记录请求生成的交易ID，在广播前调用
+func (s *Store) SetTxID(key, txID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.records[key]
+	if !ok {
+		return
+	}
+	rec.TxID = txID
+	rec.UpdatedAt = time.Now().Unix()
+	s.saveLocked()
+}
+
+// 记录请求创建的异步任务ID，在任务保存后、返回响应前调用
+func (s *Store) SetJobID(key, jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.records[key]
+	if !ok {
+		return
+	}
+	rec.JobID = jobID
+	rec.UpdatedAt = time.Now().Unix()
+	s.saveLocked()
+}
+
+// 结束请求处理。请求成功或已生成交易、任务时保存响应；
+// 未生成交易就失败的请求(如参数错误)释放幂等键，允许使用相同的键重试
+func (s *Store) Finish(key string, response []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if wait, ok := s.waits[key]; ok {
+		close(wait)
+		delete(s.waits, key)
+	}
+
+	rec, ok := s.records[key]
+	if !ok {
+		return
+	}
+
+	var resp types.APIResponse
+	success := json.Unmarshal(response, &resp) == nil && resp.Code == 1
+	if !success && rec.TxID == "" && rec.JobID == "" {
+		delete(s.records, key)
+		s.saveLocked()
+		return
+	}
+
+	rec.Status = StatusCompleted
+	rec.Response = append(json.RawMessage(nil), response...)
+	rec.UpdatedAt = time.Now().Unix()
+	s.saveLocked()
+}
@@ -0,0 +1,143 @@
+package network
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/types"
+)
+
+// 内置网络名称
+const (
+	Mainnet = "mainnet"
+	Shasta  = "shasta"
+	Nile    = "nile"
+)
+
+// 内置网络配置
+var builtin = map[string]types.Network{
+	Mainnet: {
+		Name:            Mainnet,
+		FullNodeURL:     "https://api.trongrid.io",
+		SolidityNodeURL: "https://api.trongrid.io",
+		EventServerURL:  "https://api.trongrid.io",
+		ContractAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		ExplorerURL:     "https://tronscan.org/#/transaction/{txid}",
+	},
+	Shasta: {
+		Name:            Shasta,
+		FullNodeURL:     "https://api.shasta.trongrid.io",
+		SolidityNodeURL: "https://api.shasta.trongrid.io",
+		EventServerURL:  "https://api.shasta.trongrid.io",
+		ContractAddress: "TG3XXyExBkPp9nzdajDZsozEu4BkaSJozs",
+		ExplorerURL:     "https://shasta.tronscan.org/#/transaction/{txid}",
+	},
+	Nile: {
+		Name:            Nile,
+		FullNodeURL:     "https://nile.trongrid.io",
+		SolidityNodeURL: "https://nile.trongrid.io",
+		EventServerURL:  "https://nile.trongrid.io",
+		ContractAddress: "TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf",
+		ExplorerURL:     "https://nile.tronscan.org/#/transaction/{txid}",
+	},
+}
+
+// 查找网络配置，config.Networks中的自定义网络优先于内置网络
+func Lookup(config *types.Config, name string) (types.Network, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if n, ok := config.Networks[name]; ok {
+		n.Name = name
+		return n, nil
+	}
+	if n, ok := builtin[name]; ok {
+		return n, nil
+	}
+	return types.Network{}, fmt.Errorf("未知网络: %s", name)
+}
+
+// 全部可用网络，按名称排序
+func List(config *types.Config) []types.Network {
+	names := make(map[string]bool)
+	for name := range builtin {
+		names[name] = true
+	}
+	for name := range config.Networks {
+		names[strings.ToLower(name)] = true
+	}
+
+	list := make([]types.Network, 0, len(names))
+	for name := range names {
+		if n, err := Lookup(config, name); err == nil {
+			list = append(list, n)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// 将网络配置写入config，网络中未设置的项保留config中的原值
+func Apply(config *types.Config, n types.Network) {
+	config.Network = n.Name
+	if n.FullNodeURL != "" {
+		config.TronAPIURL = n.FullNodeURL
+	}
+	if n.SolidityNodeURL != "" {
+		config.SolidityNodeURL = n.SolidityNodeURL
+	}
+	if n.EventServerURL != "" {
+		config.EventServerURL = n.EventServerURL
+	}
+	if n.ContractAddress != "" {
+		config.ContractAddress = n.ContractAddress
+	}
+	if n.ExplorerURL != "" {
+		config.ExplorerURL = n.ExplorerURL
+	}
+	if len(n.FallbackNodeURLs) > 0 {
+		config.FallbackNodeURLs = n.FallbackNodeURLs
+	}
+}
+
+// 当前配置对应的网络
+func Current(config *types.Config) types.Network {
+	return types.Network{
+		Name:            config.Network,
+		FullNodeURL:     config.TronAPIURL,
+		SolidityNodeURL: config.SolidityNodeURL,
+		EventServerURL:  config.EventServerURL,
+		ContractAddress: config.ContractAddress,
+		ExplorerURL:     config.ExplorerURL,
+
+		FallbackNodeURLs: config.FallbackNodeURLs,
+	}
+}
+
+// 基于进程配置创建指定网络的配置：使用该网络的节点和合约地址，
+// 任务数据保存在 DataDir/<网络名> 下，与默认网络互不影响
+func Derive(config *types.Config, name string) (*types.Config, error) {
+	n, err := Lookup(config, name)
+	if err != nil {
+		return nil, err
+	}
+
+	derived := *config
+	derived.SolidityNodeURL, derived.EventServerURL, derived.ExplorerURL = "", "", ""
+	derived.FallbackNodeURLs = nil
+	Apply(&derived, n)
+	derived.DataDir = filepath.Join(config.DataDir, n.Name)
+	if derived.Chain, err = chain.New(&derived); err != nil {
+		return nil, err
+	}
+	return &derived, nil
+}
+
+// 交易浏览器链接，未配置模板时返回空字符串
+func ExplorerLink(config *types.Config, txID string) string {
+	if config.ExplorerURL == "" || txID == "" {
+		return ""
+	}
+	return strings.ReplaceAll(config.ExplorerURL, "{txid}", txID)
+}
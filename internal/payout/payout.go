@@ -0,0 +1,306 @@
+package payout
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 批次状态
+const (
+	StatusInvalid     = "invalid"
+	StatusChecked     = "checked"
+	StatusRunning     = "running"
+	StatusCompleted   = "completed"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+// 明细行状态
+const (
+	itemPending   = "pending"
+	itemInvalid   = "invalid"
+	itemBroadcast = "broadcast"
+	itemSuccess   = "success"
+	itemFailed    = "failed"
+	itemSkipped   = "skipped"
+)
+
+// 单个批次最多付款行数
+const maxRows = 2000
+
+// 批量付款管理器
+type Manager struct {
+	config *types.Config
+	dir    string
+
+	mu      sync.Mutex
+	batches map[string]*types.PayoutBatch
+	dones   map[string]chan struct{}
+}
+
+// 创建批量付款管理器，并加载已保存的批次
+func NewManager(config *types.Config) *Manager {
+	m := &Manager{
+		config:  config,
+		dir:     filepath.Join(config.DataDir, "payout"),
+		batches: make(map[string]*types.PayoutBatch),
+		dones:   make(map[string]chan struct{}),
+	}
+	for _, batch := range m.load() {
+		m.start(batch, nil)
+	}
+	return m
+}
+
+// 加载磁盘上的批次，返回服务重启前仍在执行的批次。
+// 这些批次在后台继续核实已广播的明细，私钥不落盘，尚未发送的明细需通过Resume提交私钥后继续
+func (m *Manager) load() []*types.PayoutBatch {
+	files, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+
+	var running []*types.PayoutBatch
+	for _, file := range files {
+		var batch types.PayoutBatch
+		if err := utils.ReadJSONFile(file, &batch); err != nil || batch.ID == "" {
+			continue
+		}
+		m.batches[batch.ID] = &batch
+		if batch.Status == StatusRunning {
+			running = append(running, &batch)
+		}
+	}
+	return running
+}
+
+// 保存批次，调用方需持有锁
+func (m *Manager) saveLocked(batch *types.PayoutBatch) error {
+	batch.Summary = summarize(batch)
+	batch.UpdatedAt = time.Now().Unix()
+	return utils.WriteJSONFile(filepath.Join(m.dir, batch.ID+".json"), batch)
+}
+
+// 保存批次
+func (m *Manager) save(batch *types.PayoutBatch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.saveLocked(batch); err != nil {
+		fmt.Printf("⚠️  保存付款批次 %s 失败: %v\n", batch.ID, err)
+	}
+}
+
+// 在锁内修改批次
+func (m *Manager) update(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn()
+}
+
+// 统计批次进度
+func summarize(batch *types.PayoutBatch) types.PayoutSummary {
+	summary := types.PayoutSummary{Total: len(batch.Items)}
+	for _, item := range batch.Items {
+		switch item.Status {
+		case itemPending, itemBroadcast:
+			summary.Pending++
+		case itemSuccess:
+			summary.Success++
+		case itemFailed, itemInvalid:
+			summary.Failed++
+		case itemSkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// 深拷贝批次，避免读取时与执行中的批次产生数据竞争
+func snapshot(batch *types.PayoutBatch, withItems bool) *types.PayoutBatch {
+	data, _ := json.Marshal(batch)
+	var out types.PayoutBatch
+	json.Unmarshal(data, &out)
+	if !withItems {
+		out.Items = nil
+	}
+	return &out
+}
+
+// 查询批次详情
+func (m *Manager) Get(id string) (*types.PayoutBatch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	batch, ok := m.batches[id]
+	if !ok {
+		return nil, errors.New("付款批次不存在")
+	}
+	return snapshot(batch, true), nil
+}
+
+// 查询批次列表(不含明细)
+func (m *Manager) List() []*types.PayoutBatch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]*types.PayoutBatch, 0, len(m.batches))
+	for _, batch := range m.batches {
+		list = append(list, snapshot(batch, false))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt > list[j].CreatedAt
+	})
+	return list
+}
+
+// 校验付款明细并启动批次。校验未通过时返回带有逐行错误的批次和错误
+func (m *Manager) Create(req *types.PayoutRequest) (*types.PayoutBatch, error) {
+	if req.Key == "" {
+		return nil, errors.New("私钥不能为空")
+	}
+	key, err := tron.ParsePrivateKey(req.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Rows) == 0 {
+		return nil, errors.New("付款明细不能为空")
+	}
+	if len(req.Rows) > maxRows {
+		return nil, fmt.Errorf("付款明细不能超过%d行", maxRows)
+	}
+
+	batch := &types.PayoutBatch{
+		ID:              newBatchID(),
+		From:            key.Address().Base58(),
+		ContinueOnError: req.ContinueOnError,
+		DryRun:          req.DryRun,
+		CreatedAt:       time.Now().Unix(),
+	}
+
+	v := newValidator(m.config, batch.From)
+	if err := v.validate(batch, req); err != nil {
+		batch.Status = StatusInvalid
+		batch.Error = err.Error()
+		batch.Summary = summarize(batch)
+		return batch, err
+	}
+
+	if req.DryRun {
+		batch.Status = StatusChecked
+		batch.Summary = summarize(batch)
+		return batch, nil
+	}
+
+	batch.Status = StatusRunning
+	m.mu.Lock()
+	m.batches[batch.ID] = batch
+	err = m.saveLocked(batch)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("保存付款批次失败: %v", err)
+	}
+
+	m.start(batch, key)
+	return snapshot(batch, true), nil
+}
+
+// 等待批次执行结束或超时
+func (m *Manager) Wait(id string, timeout time.Duration) {
+	m.mu.Lock()
+	done, ok := m.dones[id]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// 继续执行中断的批次，私钥须与批次的付款地址一致
+func (m *Manager) Resume(id, keyHex string) (*types.PayoutBatch, error) {
+	key, err := tron.ParsePrivateKey(keyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	batch, ok := m.batches[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, errors.New("付款批次不存在")
+	}
+	if _, running := m.dones[id]; running {
+		m.mu.Unlock()
+		return nil, errors.New("批次正在执行中")
+	}
+	if batch.Status != StatusInterrupted {
+		m.mu.Unlock()
+		return nil, errors.New("只能继续已中断的批次")
+	}
+	if key.Address().Base58() != batch.From {
+		m.mu.Unlock()
+		return nil, errors.New("私钥与批次的付款地址不一致")
+	}
+	batch.Status = StatusRunning
+	batch.Error = ""
+	err = m.saveLocked(batch)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("保存付款批次失败: %v", err)
+	}
+
+	m.start(batch, key)
+	return snapshot(batch, true), nil
+}
+
+// 在后台协程中逐行付款
+func (m *Manager) start(batch *types.PayoutBatch, key *tron.PrivateKey) {
+	done := make(chan struct{})
+	m.mu.Lock()
+	m.dones[batch.ID] = done
+	m.mu.Unlock()
+
+	r := &runner{m: m, batch: batch, key: key}
+	go func() {
+		err := r.run()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.dones, batch.ID)
+		switch {
+		case errors.Is(err, errKeyRequired):
+			batch.Status = StatusInterrupted
+			batch.Error = "服务重启，批次已中断，" + err.Error()
+		case errors.Is(err, errUnconfirmed):
+			batch.Status = StatusInterrupted
+			batch.Error = err.Error()
+		case err != nil:
+			batch.Status = StatusFailed
+			batch.Error = err.Error()
+		default:
+			batch.Status = StatusCompleted
+		}
+		m.saveLocked(batch)
+		close(done)
+	}()
+}
+
+func newBatchID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("payout_%s_%s", time.Now().Format("20060102150405"), hex.EncodeToString(b))
+}
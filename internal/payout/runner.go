@@ -0,0 +1,197 @@
+package payout
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 等待交易上链的轮询间隔
+const confirmInterval = 3 * time.Second
+
+var (
+	// 交易已过期但节点查询失败，无法判断是否上链，明细保持broadcast状态
+	errUnconfirmed = errors.New("交易已过期，但节点查询失败，无法确认是否上链")
+	// 服务重启后继续批次时，尚未发送的明细需要重新提交私钥
+	errKeyRequired = errors.New("剩余明细需要提交私钥后继续发送")
+)
+
+// 批次执行器，key为nil时只核实已广播的明细
+type runner struct {
+	m     *Manager
+	batch *types.PayoutBatch
+	key   *tron.PrivateKey
+}
+
+// 按顺序逐行付款，出错时默认停止并将剩余明细标记为跳过。
+// 已广播的明细(服务中断后继续的批次)按交易ID核实，不会重新发送
+func (r *runner) run() error {
+	var failed int
+	for i := range r.batch.Items {
+		item := &r.batch.Items[i]
+		if item.Status != itemPending && item.Status != itemBroadcast {
+			continue
+		}
+
+		var err error
+		switch {
+		case item.Status == itemBroadcast:
+			err = r.confirm(item, nil, true)
+		case r.key == nil:
+			return errKeyRequired
+		default:
+			err = r.pay(item)
+		}
+		unconfirmed := errors.Is(err, errUnconfirmed)
+		r.m.update(func() {
+			switch {
+			case unconfirmed:
+				item.Error = err.Error()
+			case err != nil:
+				item.Status = itemFailed
+				item.Error = err.Error()
+			default:
+				item.Status = itemSuccess
+				item.Error = ""
+			}
+		})
+		r.m.save(r.batch)
+
+		if err == nil {
+			continue
+		}
+		// 状态未知时不能判定失败，也不继续发送后续明细
+		if unconfirmed {
+			return fmt.Errorf("第%d行%w", item.Row, err)
+		}
+		failed++
+		if !r.batch.ContinueOnError {
+			r.skipRemaining(i + 1)
+			return fmt.Errorf("第%d行付款失败，后续明细未发送: %v", item.Row, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d行付款失败", failed)
+	}
+	return nil
+}
+
+// 将剩余待发送的明细标记为跳过
+func (r *runner) skipRemaining(from int) {
+	r.m.update(func() {
+		for i := from; i < len(r.batch.Items); i++ {
+			if r.batch.Items[i].Status == itemPending {
+				r.batch.Items[i].Status = itemSkipped
+			}
+		}
+	})
+	r.m.save(r.batch)
+}
+
+// 构建、签名、记录并广播单行付款交易，然后等待上链
+func (r *runner) pay(item *types.PayoutItem) error {
+	tx, err := r.build(item)
+	if err != nil {
+		return fmt.Errorf("创建交易失败: %v", err)
+	}
+	if err := utils.SignTransaction(tx, r.key); err != nil {
+		return fmt.Errorf("签名失败: %v", err)
+	}
+
+	// 广播前先记录交易ID，服务中断后可据此核实是否到账
+	r.m.update(func() {
+		item.TxID = tx.TxID
+		item.Status = itemBroadcast
+		item.Time = time.Now().Unix()
+		item.Expiration = utils.TransactionExpiration(tx)
+	})
+	r.m.save(r.batch)
+
+	// 只有节点明确拒绝时才判定失败，超时等错误时交易可能已被接收，继续按交易ID查询
+	result, err := utils.BroadcastTransaction(r.m.config, tx)
+	if err != nil && utils.BroadcastRejected(result) {
+		return err
+	}
+	return r.confirm(item, tx, err == nil)
+}
+
+// 按代币类型构建付款交易，备注写入交易的data字段
+func (r *runner) build(item *types.PayoutItem) (*types.Transaction, error) {
+	cfg := r.m.config
+	units, ok := new(big.Int).SetString(item.AmountUnits, 10)
+	if !ok {
+		return nil, errors.New("金额格式错误")
+	}
+
+	payload := map[string]interface{}{
+		"owner_address": r.batch.From,
+	}
+	if item.Memo != "" {
+		payload["extra_data"] = item.Memo
+	}
+
+	switch item.TokenType {
+	case tokenTRX:
+		payload["to_address"] = item.Address
+		payload["amount"] = units.Int64()
+		return utils.BuildTransaction(cfg, "/wallet/createtransaction", payload, 0)
+	case tokenTRC10:
+		payload["to_address"] = item.Address
+		payload["asset_name"] = item.Token
+		payload["amount"] = units.Int64()
+		return utils.BuildTransaction(cfg, "/wallet/transferasset", payload, 0)
+	case tokenTRC20:
+		to, err := tron.ParseAddress(item.Address)
+		if err != nil {
+			return nil, err
+		}
+		parameter, err := tron.EncodeTransferParams(to, units)
+		if err != nil {
+			return nil, err
+		}
+		payload["contract_address"] = item.Token
+		payload["function_selector"] = "transfer(address,uint256)"
+		payload["parameter"] = parameter
+		payload["fee_limit"] = cfg.FeeLimit
+		payload["call_value"] = 0
+		return utils.BuildContractTransaction(cfg, payload, 0)
+	}
+	return nil, fmt.Errorf("不支持的代币类型: %s", item.TokenType)
+}
+
+// 等待交易上链并检查执行结果。广播未确认成功时每次查询前重新广播，
+// 直到节点接收或明确拒绝；交易过期后仍查询不到才判定未上链，过期后节点仍查询失败时返回errUnconfirmed
+func (r *runner) confirm(item *types.PayoutItem, tx *types.Transaction, sent bool) error {
+	for {
+		if !sent && tx != nil {
+			result, err := utils.BroadcastTransaction(r.m.config, tx)
+			if err != nil && utils.BroadcastRejected(result) {
+				return err
+			}
+			sent = err == nil || result != nil && result.Code == "DUP_TRANSACTION_ERROR"
+		}
+
+		info, err := utils.GetTransactionInfo(r.m.config, item.TxID)
+		if err == nil && info != nil {
+			if info.Result == "FAILED" || (info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS") {
+				return fmt.Errorf("交易执行失败: %s %s", info.Receipt.Result, info.ResMessage)
+			}
+			return nil
+		}
+
+		if confirm.Expired(item.Expiration) {
+			if err != nil {
+				return fmt.Errorf("%w: %v", errUnconfirmed, err)
+			}
+			return errors.New("交易已过期未上链")
+		}
+		time.Sleep(confirmInterval)
+	}
+}
@@ -0,0 +1,381 @@
+package payout
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 代币类型
+const (
+	tokenTRX   = "trx"
+	tokenTRC20 = "trc20"
+	tokenTRC10 = "trc10"
+)
+
+// 备注最大字节数
+const maxMemoBytes = 200
+
+// 各类交易签名后的大致字节数，用于估算带宽
+const (
+	trxTxSize   = 270
+	trc10TxSize = 285
+	trc20TxSize = 345
+)
+
+// 链参数缺失时使用的默认单价(SUN)
+const (
+	defaultEnergyFee      = 420
+	defaultTransactionFee = 1000
+)
+
+// 付款代币
+type token struct {
+	id       string // TRX | 合约Base58地址 | TRC10代币ID
+	kind     string
+	decimals int
+}
+
+// 付款明细校验器
+type validator struct {
+	config *types.Config
+	from   string
+	tokens map[string]*token
+}
+
+func newValidator(config *types.Config, from string) *validator {
+	return &validator{
+		config: config,
+		from:   from,
+		tokens: make(map[string]*token),
+	}
+}
+
+// 解析代币标识：TRX、纯数字的TRC10代币ID或TRC20合约地址
+func (v *validator) resolveToken(raw string) (*token, error) {
+	raw = strings.TrimSpace(raw)
+	if t, ok := v.tokens[raw]; ok {
+		return t, nil
+	}
+
+	var t *token
+	switch {
+	case strings.EqualFold(raw, "TRX"):
+		t = &token{id: "TRX", kind: tokenTRX, decimals: 6}
+	case utils.IsDigits(raw):
+		info, err := utils.GetTrc10Token(v.config, raw)
+		if err != nil {
+			return nil, err
+		}
+		t = &token{id: raw, kind: tokenTRC10, decimals: info.Precision}
+	default:
+		addr, err := tron.ParseAddress(raw)
+		if err != nil {
+			return nil, fmt.Errorf("代币 %s 格式错误", raw)
+		}
+		decimals, err := utils.GetTokenDecimals(v.config, addr.Base58())
+		if err != nil {
+			return nil, fmt.Errorf("代币 %s: %v", addr.Base58(), err)
+		}
+		t = &token{id: addr.Base58(), kind: tokenTRC20, decimals: decimals}
+	}
+
+	v.tokens[raw] = t
+	v.tokens[t.id] = t
+	return t, nil
+}
+
+// 校验每一行明细，并检查余额和资源是否足够
+func (v *validator) validate(batch *types.PayoutBatch, req *types.PayoutRequest) error {
+	defaultToken := req.Token
+	if defaultToken == "" {
+		defaultToken = v.config.ContractAddress
+	}
+
+	invalid := 0
+	batch.Items = make([]types.PayoutItem, 0, len(req.Rows))
+	for i, row := range req.Rows {
+		item := types.PayoutItem{
+			Row:     i + 1,
+			Address: strings.TrimSpace(row.Address),
+			Amount:  strings.TrimSpace(row.Amount),
+			Token:   strings.TrimSpace(row.Token),
+			Memo:    row.Memo,
+			Status:  itemPending,
+		}
+		if item.Token == "" {
+			item.Token = defaultToken
+		}
+
+		if err := v.validateRow(&item); err != nil {
+			item.Status = itemInvalid
+			item.Error = err.Error()
+			invalid++
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	if invalid > 0 {
+		return fmt.Errorf("共%d行校验失败，未发送任何交易", invalid)
+	}
+
+	if err := v.checkBalances(batch); err != nil {
+		return err
+	}
+	return nil
+}
+
+// 校验单行明细并填充代币信息和最小单位金额
+func (v *validator) validateRow(item *types.PayoutItem) error {
+	t, err := v.resolveToken(item.Token)
+	if err != nil {
+		return err
+	}
+	item.Token = t.id
+	item.TokenType = t.kind
+
+	to, err := tron.ParseAddress(item.Address)
+	if err != nil {
+		return errors.New("收款地址格式错误")
+	}
+	item.Address = to.Base58()
+	if item.Address == v.from {
+		return errors.New("收款地址不能与付款地址相同")
+	}
+
+	value, err := amount.Parse(item.Amount, t.decimals)
+	if err != nil {
+		return err
+	}
+	if value.Sign() <= 0 {
+		return errors.New("金额必须大于0")
+	}
+	if _, ok := value.Int64(); t.kind != tokenTRC20 && !ok {
+		return errors.New("金额过大")
+	}
+	item.Amount = value.String()
+	item.AmountUnits = value.UnitsString()
+
+	if len(item.Memo) > maxMemoBytes {
+		return fmt.Errorf("备注不能超过%d字节", maxMemoBytes)
+	}
+	return nil
+}
+
+// 汇总各代币付款总额，检查余额、带宽和能量
+func (v *validator) checkBalances(batch *types.PayoutBatch) error {
+	totals := make(map[string]*types.PayoutTotal)
+	sums := make(map[string]*big.Int)
+	var order []string
+
+	res := &types.PayoutResources{}
+	var memoRows int64
+	estimates := make(map[string]int64)
+
+	for _, item := range batch.Items {
+		if _, ok := totals[item.Token]; !ok {
+			t := v.tokens[item.Token]
+			totals[item.Token] = &types.PayoutTotal{
+				Token:     item.Token,
+				TokenType: item.TokenType,
+				Decimals:  t.decimals,
+			}
+			sums[item.Token] = new(big.Int)
+			order = append(order, item.Token)
+		}
+		units, _ := new(big.Int).SetString(item.AmountUnits, 10)
+		totals[item.Token].Rows++
+		sums[item.Token].Add(sums[item.Token], units)
+
+		size := int64(len(item.Memo))
+		switch item.TokenType {
+		case tokenTRX:
+			size += trxTxSize
+		case tokenTRC10:
+			size += trc10TxSize
+		case tokenTRC20:
+			size += trc20TxSize
+		}
+		res.BandwidthRequired += size
+		if item.Memo != "" {
+			memoRows++
+		}
+	}
+
+	trxBalance, err := utils.GetAccountBalance(v.config, v.from)
+	if err != nil {
+		return fmt.Errorf("查询TRX余额失败: %v", err)
+	}
+
+	var trxSpend int64
+	insufficient := false
+	for _, id := range order {
+		total := totals[id]
+		sum := sums[id]
+		total.Amount = amount.New(sum, total.Decimals).String()
+		total.AmountUnits = sum.String()
+
+		var balance *big.Int
+		switch total.TokenType {
+		case tokenTRX:
+			balance = big.NewInt(trxBalance)
+			trxSpend = sum.Int64()
+		case tokenTRC10:
+			units, err := utils.GetTrc10BalanceRaw(v.config, v.from, id)
+			if err != nil {
+				return fmt.Errorf("查询TRC10代币 %s 余额失败: %v", id, err)
+			}
+			balance = big.NewInt(units)
+		case tokenTRC20:
+			units, err := utils.GetTrc20BalanceRaw(v.config, v.from, id)
+			if err != nil {
+				return fmt.Errorf("查询代币 %s 余额失败: %v", id, err)
+			}
+			balance = units
+		}
+
+		total.Balance = amount.New(balance, total.Decimals).String()
+		total.BalanceUnits = balance.String()
+		total.Sufficient = balance.Cmp(sum) >= 0
+		if !total.Sufficient {
+			insufficient = true
+		}
+		batch.Totals = append(batch.Totals, *total)
+	}
+	if insufficient {
+		return errors.New("付款地址余额不足")
+	}
+
+	// 按每行实际参数估算TRC20转账能量，相同收款地址和合约只估算一次
+	for _, item := range batch.Items {
+		if item.TokenType != tokenTRC20 {
+			continue
+		}
+		cacheKey := item.Token + ":" + item.Address
+		energy, ok := estimates[cacheKey]
+		if !ok {
+			to, _ := tron.ParseAddress(item.Address)
+			units, _ := new(big.Int).SetString(item.AmountUnits, 10)
+			parameter, err := tron.EncodeTransferParams(to, units)
+			if err != nil {
+				return err
+			}
+			energy, err = utils.EstimateEnergy(v.config, v.from, item.Token, "transfer(address,uint256)", parameter)
+			if err != nil {
+				return fmt.Errorf("第%d行能量估算失败: %v", item.Row, err)
+			}
+			estimates[cacheKey] = energy
+		}
+		res.EnergyRequired += energy
+	}
+
+	account, err := utils.GetAccountResource(v.config, v.from)
+	if err != nil {
+		return fmt.Errorf("查询账户资源失败: %v", err)
+	}
+	res.EnergyAvailable = nonNegative(account.EnergyLimit - account.EnergyUsed)
+	res.BandwidthAvailable = nonNegative(account.FreeNetLimit-account.FreeNetUsed) + nonNegative(account.NetLimit-account.NetUsed)
+
+	params, err := utils.GetChainParameters(v.config)
+	if err != nil {
+		return fmt.Errorf("查询链参数失败: %v", err)
+	}
+	energyFee := params["getEnergyFee"]
+	if energyFee == 0 {
+		energyFee = defaultEnergyFee
+	}
+	transactionFee := params["getTransactionFee"]
+	if transactionFee == 0 {
+		transactionFee = defaultTransactionFee
+	}
+
+	fee := nonNegative(res.EnergyRequired-res.EnergyAvailable)*energyFee +
+		nonNegative(res.BandwidthRequired-res.BandwidthAvailable)*transactionFee +
+		memoRows*params["getMemoFee"]
+	res.FeeEstimateSun = fee
+	res.FeeEstimate = amount.Sun(fee).String()
+
+	res.TrxBalance = amount.Sun(trxBalance).String()
+	res.TrxRequired = amount.Sun(trxSpend + fee).String()
+	res.Sufficient = trxBalance >= trxSpend+fee
+	batch.Resources = res
+
+	if !res.Sufficient {
+		return fmt.Errorf("TRX余额不足以支付付款金额和预估手续费(需要 %s TRX)", res.TrxRequired)
+	}
+	return nil
+}
+
+func nonNegative(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// 解析CSV付款明细，列依次为address,amount,token,memo；首行为表头时按列名识别
+func ParseCSV(r io.Reader) ([]types.PayoutRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV格式错误: %v", err)
+	}
+
+	columns := map[string]int{"address": 0, "amount": 1, "token": 2, "memo": 3}
+	if len(records) > 0 && hasColumn(records[0], "address") {
+		columns = make(map[string]int)
+		for i, name := range records[0] {
+			name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+			columns[name] = i
+		}
+		if _, ok := columns["address"]; !ok {
+			return nil, errors.New("CSV表头缺少address列")
+		}
+		if _, ok := columns["amount"]; !ok {
+			return nil, errors.New("CSV表头缺少amount列")
+		}
+		records = records[1:]
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]types.PayoutRow, 0, len(records))
+	for _, record := range records {
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		rows = append(rows, types.PayoutRow{
+			Address: field(record, "address"),
+			Amount:  field(record, "amount"),
+			Token:   field(record, "token"),
+			Memo:    field(record, "memo"),
+		})
+	}
+	return rows, nil
+}
+
+// 判断首行是否包含指定列名
+func hasColumn(record []string, name string) bool {
+	for _, v := range record {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(v, "\ufeff")), name) {
+			return true
+		}
+	}
+	return false
+}
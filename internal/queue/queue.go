@@ -0,0 +1,326 @@
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 任务状态
+const (
+	StatusQueued      = "queued"
+	StatusRetrying    = "retrying"
+	StatusBroadcast   = "broadcast"
+	StatusConfirmed   = "confirmed"
+	StatusFailed      = "failed"
+	StatusCancelled   = "cancelled"
+	StatusKeyRequired = "key_required"
+)
+
+// 代币类型
+const (
+	TokenTRX   = "trx"
+	TokenTRC10 = "trc10"
+	TokenTRC20 = "trc20"
+)
+
+// 默认最多发送次数
+const defaultMaxAttempts = 8
+
+// 单个任务最多保留的事件数
+const maxHistory = 50
+
+// 待发送的转账
+type Transfer struct {
+	TokenType    string
+	Token        string // TRC10代币ID或TRC20合约地址，TRX为空
+	From         string
+	To           string
+	Amount       amount.Amount
+	PermissionID int
+	MaxAttempts  int
+}
+
+// 发送队列管理器：任务持久化到嵌入式数据库，由后台工作协程构建、签名、广播并确认
+type Manager struct {
+	config *types.Config
+	store  *store // 打开失败时为nil，新任务无法加入
+
+	mu      sync.Mutex
+	jobs    map[string]*types.QueueJob
+	keys    map[string]*tron.PrivateKey // 私钥仅保存在内存中
+	running map[string]bool
+	work    chan string
+}
+
+// 创建发送队列，加载已保存的任务并启动工作协程
+func NewManager(config *types.Config) *Manager {
+	m := &Manager{
+		config:  config,
+		jobs:    make(map[string]*types.QueueJob),
+		keys:    make(map[string]*tron.PrivateKey),
+		running: make(map[string]bool),
+		work:    make(chan string),
+	}
+	store, err := openStore(filepath.Join(config.DataDir, "queue"))
+	if err != nil {
+		fmt.Printf("⚠️  打开发送队列存储失败: %v\n", err)
+	} else {
+		m.store = store
+		m.load()
+	}
+
+	workers := config.QueueWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go m.worker()
+	}
+	go m.dispatch()
+	return m
+}
+
+// 加载已保存的任务。私钥不落盘，服务重启后尚未签名的任务需要重新提供私钥
+func (m *Manager) load() {
+	jobs, err := m.store.all()
+	if err != nil {
+		fmt.Printf("⚠️  加载发送队列任务失败: %v\n", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if (job.Status == StatusQueued || job.Status == StatusRetrying) && job.Transaction == nil {
+			setStatus(job, StatusKeyRequired, errKeyRequired.Error())
+		}
+		m.jobs[job.ID] = job
+	}
+}
+
+// 保存任务，调用方需持有锁
+func (m *Manager) saveLocked(job *types.QueueJob) error {
+	if m.store == nil {
+		return errStoreUnavailable
+	}
+	job.UpdatedAt = time.Now().Unix()
+	return m.store.put(job)
+}
+
+// 在锁内修改任务并保存，任务已取消时不再修改
+func (m *Manager) update(job *types.QueueJob, fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job.Status == StatusCancelled {
+		return
+	}
+	fn()
+	if err := m.saveLocked(job); err != nil {
+		fmt.Printf("⚠️  保存队列任务 %s 失败: %v\n", job.ID, err)
+	}
+}
+
+// 修改任务状态并记录事件
+func setStatus(job *types.QueueJob, status, errMsg string) {
+	job.Status = status
+	job.Error = errMsg
+	job.History = append(job.History, types.QueueEvent{
+		Time:   time.Now().Unix(),
+		Status: status,
+		TxID:   job.TxID,
+		Error:  errMsg,
+	})
+	if len(job.History) > maxHistory {
+		job.History = job.History[len(job.History)-maxHistory:]
+	}
+}
+
+// 深拷贝任务，列表中不含已签名交易和事件记录
+func snapshot(job *types.QueueJob, detail bool) *types.QueueJob {
+	data, _ := json.Marshal(job)
+	var out types.QueueJob
+	json.Unmarshal(data, &out)
+	if !detail {
+		out.Transaction = nil
+		out.History = nil
+	}
+	return &out
+}
+
+// 加入发送队列
+func (m *Manager) Enqueue(t *Transfer, key *tron.PrivateKey) (*types.QueueJob, error) {
+	if key == nil {
+		return nil, errors.New("私钥不能为空")
+	}
+	switch t.TokenType {
+	case TokenTRX, TokenTRC10, TokenTRC20:
+	default:
+		return nil, fmt.Errorf("不支持的代币类型: %s", t.TokenType)
+	}
+
+	maxAttempts := t.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	now := time.Now().Unix()
+	job := &types.QueueJob{
+		ID:           newJobID(),
+		TokenType:    t.TokenType,
+		Token:        t.Token,
+		From:         t.From,
+		Signer:       key.Address().Base58(),
+		To:           t.To,
+		Amount:       t.Amount.String(),
+		AmountUnits:  t.Amount.UnitsString(),
+		Decimals:     t.Amount.Decimals(),
+		PermissionID: t.PermissionID,
+		MaxAttempts:  maxAttempts,
+		NextRunAt:    now,
+		CreatedAt:    now,
+	}
+	setStatus(job, StatusQueued, "")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.saveLocked(job); err != nil {
+		return nil, fmt.Errorf("保存队列任务失败: %v", err)
+	}
+	m.jobs[job.ID] = job
+	m.keys[job.ID] = key
+	return snapshot(job, true), nil
+}
+
+// 查询任务详情
+func (m *Manager) Get(id string) (*types.QueueJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errors.New("队列任务不存在")
+	}
+	return snapshot(job, true), nil
+}
+
+// 查询任务列表，status为空时返回全部
+func (m *Manager) List(status string) []*types.QueueJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]*types.QueueJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if status != "" && job.Status != status {
+			continue
+		}
+		list = append(list, snapshot(job, false))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt > list[j].CreatedAt
+	})
+	return list
+}
+
+// 取消尚未广播的任务。任务已有签名交易时(广播失败后重试中)交易可能已被节点接收，
+// 需等交易过期并确认未上链后才能取消
+func (m *Manager) Cancel(id string) (*types.QueueJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, errors.New("队列任务不存在")
+	}
+	if err := m.cancellable(job); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	tx, txID := job.Transaction, job.TxID
+	m.mu.Unlock()
+
+	if tx != nil {
+		if !confirm.Expired(utils.TransactionExpiration(tx)) {
+			return nil, errors.New("任务已有签名交易，可能已被节点接收，请等交易过期后再取消")
+		}
+		info, err := utils.GetTransactionInfo(m.config, txID)
+		if err != nil {
+			return nil, fmt.Errorf("查询交易失败: %v", err)
+		}
+		if info != nil {
+			return nil, errors.New("交易已上链，无法取消")
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// 查询期间任务可能已被处理
+	if err := m.cancellable(job); err != nil {
+		return nil, err
+	}
+	if job.TxID != txID {
+		return nil, errors.New("任务已重新构建交易，请稍后重试")
+	}
+
+	setStatus(job, StatusCancelled, "")
+	job.NextRunAt = 0
+	delete(m.keys, id)
+	if err := m.saveLocked(job); err != nil {
+		return nil, fmt.Errorf("保存队列任务失败: %v", err)
+	}
+	return snapshot(job, true), nil
+}
+
+// 任务当前是否可以取消，调用方需持有锁
+func (m *Manager) cancellable(job *types.QueueJob) error {
+	switch job.Status {
+	case StatusQueued, StatusRetrying, StatusKeyRequired:
+		if m.running[job.ID] {
+			return errors.New("任务正在发送中，请稍后重试")
+		}
+		return nil
+	case StatusBroadcast:
+		return errors.New("交易已广播，无法取消")
+	}
+	return fmt.Errorf("任务状态为%s，无法取消", job.Status)
+}
+
+// 为等待私钥的任务重新提供私钥并继续发送
+func (m *Manager) Resume(id string, key *tron.PrivateKey) (*types.QueueJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errors.New("队列任务不存在")
+	}
+	if job.Status != StatusKeyRequired {
+		return nil, fmt.Errorf("任务状态为%s，无需提供私钥", job.Status)
+	}
+	if key.Address().Base58() != job.Signer {
+		return nil, errors.New("私钥与任务的签名地址不一致")
+	}
+
+	m.keys[id] = key
+	setStatus(job, StatusQueued, "")
+	job.NextRunAt = time.Now().Unix()
+	if err := m.saveLocked(job); err != nil {
+		return nil, fmt.Errorf("保存队列任务失败: %v", err)
+	}
+	return snapshot(job, true), nil
+}
+
+func newJobID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("queue_%s_%s", time.Now().Format("20060102150405"), hex.EncodeToString(b))
+}
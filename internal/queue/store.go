@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"tron-api-go/internal/types"
+)
+
+// 任务所在的bucket，键为任务ID，值为任务的JSON
+var jobsBucket = []byte("jobs")
+
+var errStoreUnavailable = errors.New("发送队列存储不可用")
+
+// 发送队列的持久化存储：任务保存在 DataDir/queue/queue.db (BoltDB)，
+// 每次修改在单个事务内写入并落盘，进程崩溃不会留下写了一半的任务
+type store struct {
+	db *bolt.DB
+}
+
+// 打开存储，数据库文件不存在时创建
+func openStore(dir string) (*store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	// 数据库文件被其他进程占用时不无限等待
+	db, err := bolt.Open(filepath.Join(dir, "queue.db"), 0o600, &bolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &store{db: db}, nil
+}
+
+// 读取全部任务，无法解析的记录跳过
+func (s *store) all() ([]*types.QueueJob, error) {
+	var jobs []*types.QueueJob
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var job types.QueueJob
+			if err := json.Unmarshal(v, &job); err != nil || job.ID == "" {
+				return nil
+			}
+			jobs = append(jobs, &job)
+			return nil
+		})
+	})
+	return jobs, err
+}
+
+// 保存任务
+func (s *store) put(job *types.QueueJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(job.ID), data)
+	})
+}
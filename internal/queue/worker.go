@@ -0,0 +1,297 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+const (
+	// 调度间隔
+	dispatchInterval = time.Second
+	// 广播后查询上链结果的间隔
+	confirmInterval = 3 * time.Second
+	// 未上链交易的重新广播间隔
+	rebroadcastInterval = 10 * time.Second
+)
+
+// 重试退避：5秒起每次翻倍，最长10分钟
+const (
+	baseBackoff = 5 * time.Second
+	maxBackoff  = 10 * time.Minute
+)
+
+var errKeyRequired = errors.New("服务重启后私钥已从内存中清除，请调用 /v1/queue/resume 重新提供私钥")
+
+// 定期挑选到期的任务交给工作协程
+func (m *Manager) dispatch() {
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, id := range m.due() {
+			m.work <- id
+		}
+	}
+}
+
+// 到期且未在处理中的任务
+func (m *Manager) due() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Unix()
+	var ids []string
+	for id, job := range m.jobs {
+		if m.running[id] || job.NextRunAt == 0 || job.NextRunAt > now {
+			continue
+		}
+		switch job.Status {
+		case StatusQueued, StatusRetrying, StatusBroadcast:
+			m.running[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (m *Manager) worker() {
+	for id := range m.work {
+		m.process(id)
+
+		m.mu.Lock()
+		delete(m.running, id)
+		m.mu.Unlock()
+	}
+}
+
+func (m *Manager) process(id string) {
+	m.mu.Lock()
+	job := m.jobs[id]
+	status := job.Status
+	key := m.keys[id]
+	m.mu.Unlock()
+
+	switch status {
+	case StatusQueued, StatusRetrying:
+		m.send(job, key)
+	case StatusBroadcast:
+		m.check(job)
+	}
+}
+
+// 构建、签名并广播交易。已有未过期的签名交易时直接重新广播，保证同一任务只对应一笔有效交易
+func (m *Manager) send(job *types.QueueJob, key *tron.PrivateKey) {
+	cfg := m.config
+	m.update(job, func() { job.Attempts++ })
+
+	tx := job.Transaction
+	if tx != nil && confirm.Expired(utils.TransactionExpiration(tx)) {
+		// 旧交易已过期，确认其未上链后再重新构建，避免重复付款
+		info, err := utils.GetTransactionInfo(cfg, job.TxID)
+		if err != nil {
+			m.retry(job, fmt.Errorf("查询交易失败: %v", err))
+			return
+		}
+		if info != nil {
+			m.settle(job, info)
+			return
+		}
+		tx = nil
+	}
+
+	if tx == nil {
+		if key == nil {
+			m.update(job, func() {
+				setStatus(job, StatusKeyRequired, errKeyRequired.Error())
+				job.NextRunAt = 0
+			})
+			return
+		}
+
+		var err error
+		tx, err = m.build(job)
+		if err != nil {
+			m.retry(job, fmt.Errorf("创建交易失败: %v", err))
+			return
+		}
+		if err := utils.SignTransaction(tx, key); err != nil {
+			m.fail(job, "签名失败: "+err.Error())
+			return
+		}
+		if job.From != job.Signer || job.PermissionID != 0 {
+			weight, err := utils.GetSignWeight(cfg, tx)
+			if err != nil {
+				m.retry(job, fmt.Errorf("查询签名权重失败: %v", err))
+				return
+			}
+			if !weight.Enough {
+				m.fail(job, "签名权重不足，发送队列不支持多人签名")
+				return
+			}
+		}
+
+		// 广播前先保存已签名交易，服务中断后可继续广播或按交易ID核实
+		m.update(job, func() {
+			if job.TxID != "" {
+				job.Rebuilds++
+			}
+			job.Transaction = tx
+			job.TxID = tx.TxID
+		})
+	}
+
+	result, err := utils.BroadcastTransaction(cfg, tx)
+	if err != nil {
+		code := ""
+		if result != nil {
+			code = result.Code
+		}
+		switch code {
+		case "DUP_TRANSACTION_ERROR":
+			// 节点已有该交易，按广播成功处理
+		case "TRANSACTION_EXPIRATION_ERROR", "TAPOS_ERROR":
+			// 交易未被节点接受，下次重新构建
+			m.update(job, func() { job.Transaction = nil })
+			m.retry(job, err)
+			return
+		case "SIGERROR", "CONTRACT_VALIDATE_ERROR", "CONTRACT_EXE_ERROR":
+			m.fail(job, err.Error())
+			return
+		default:
+			m.retry(job, err)
+			return
+		}
+	}
+
+	now := time.Now()
+	m.update(job, func() {
+		setStatus(job, StatusBroadcast, "")
+		job.LastBroadcastAt = now.Unix()
+		job.NextRunAt = now.Add(confirmInterval).Unix()
+	})
+}
+
+// 查询已广播交易的上链结果，过期未上链时重新构建
+func (m *Manager) check(job *types.QueueJob) {
+	cfg := m.config
+	next := func() {
+		m.update(job, func() { job.NextRunAt = time.Now().Add(confirmInterval).Unix() })
+	}
+
+	info, err := utils.GetTransactionInfo(cfg, job.TxID)
+	if err != nil {
+		next()
+		return
+	}
+	if info != nil {
+		m.settle(job, info)
+		return
+	}
+
+	if job.Transaction == nil || confirm.Expired(utils.TransactionExpiration(job.Transaction)) {
+		if job.Attempts >= job.MaxAttempts {
+			m.fail(job, fmt.Sprintf("发送%d次后仍失败，交易已过期且未上链", job.Attempts))
+			return
+		}
+		m.update(job, func() {
+			setStatus(job, StatusQueued, "交易过期未上链，重新构建交易")
+			job.NextRunAt = time.Now().Unix()
+		})
+		return
+	}
+
+	if time.Since(time.Unix(job.LastBroadcastAt, 0)) >= rebroadcastInterval {
+		utils.BroadcastTransaction(cfg, job.Transaction)
+		m.update(job, func() { job.LastBroadcastAt = time.Now().Unix() })
+	}
+	next()
+}
+
+// 按链上执行结果结束任务
+func (m *Manager) settle(job *types.QueueJob, info *types.TransactionInfo) {
+	m.update(job, func() {
+		job.BlockNumber = info.BlockNumber
+		job.NextRunAt = 0
+		if info.Result == "FAILED" || (info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS") {
+			setStatus(job, StatusFailed, fmt.Sprintf("交易执行失败: %s %s", info.Receipt.Result, info.ResMessage))
+		} else {
+			setStatus(job, StatusConfirmed, "")
+		}
+		delete(m.keys, job.ID)
+	})
+}
+
+// 任务失败且不再重试
+func (m *Manager) fail(job *types.QueueJob, msg string) {
+	m.update(job, func() {
+		setStatus(job, StatusFailed, msg)
+		job.NextRunAt = 0
+		delete(m.keys, job.ID)
+	})
+}
+
+// 按指数退避安排重试，超过最多发送次数后任务失败。
+// 已有签名交易时该交易仍可能上链，继续等待至交易过期并确认链上不存在后才判定失败
+func (m *Manager) retry(job *types.QueueJob, err error) {
+	m.update(job, func() {
+		if job.Attempts >= job.MaxAttempts {
+			msg := fmt.Sprintf("发送%d次后仍失败: %v", job.Attempts, err)
+			if job.TxID != "" {
+				setStatus(job, StatusBroadcast, msg+"，等待交易过期后核实是否上链")
+				job.NextRunAt = time.Now().Add(confirmInterval).Unix()
+				return
+			}
+			setStatus(job, StatusFailed, msg)
+			job.NextRunAt = 0
+			delete(m.keys, job.ID)
+			return
+		}
+		setStatus(job, StatusRetrying, err.Error())
+		job.NextRunAt = time.Now().Add(backoff(job.Attempts)).Unix()
+	})
+}
+
+// 第n次失败后的等待时间
+func backoff(n int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < n && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+// 按代币类型构建转账交易
+func (m *Manager) build(job *types.QueueJob) (*types.Transaction, error) {
+	cfg := m.config
+	units, ok := new(big.Int).SetString(job.AmountUnits, 10)
+	if !ok {
+		return nil, errors.New("金额格式错误")
+	}
+
+	switch job.TokenType {
+	case TokenTRX:
+		return utils.CreateTrxTransaction(cfg, job.From, job.To, units.Int64(), job.PermissionID)
+	case TokenTRC10:
+		return utils.CreateTrc10Transaction(cfg, job.From, job.To, job.Token, units.Int64(), job.PermissionID)
+	case TokenTRC20:
+		to, err := tron.ParseAddress(job.To)
+		if err != nil {
+			return nil, err
+		}
+		parameter, err := tron.EncodeTransferParams(to, units)
+		if err != nil {
+			return nil, err
+		}
+		return utils.TriggerSmartContract(cfg, job.From, job.Token, "transfer(address,uint256)", parameter, 0, job.PermissionID)
+	}
+	return nil, fmt.Errorf("不支持的代币类型: %s", job.TokenType)
+}
@@ -8,53 +8,132 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// 设置所有路由
-func SetupRoutes(r *gin.Engine, config *types.Config) {
+// 设置所有路由，返回各网络的处理器服务
+func SetupRoutes(r *gin.Engine, config *types.Config) *handlers.Networks {
 	// 加载HTML模板
 	r.LoadHTMLGlob("templates/*")
 
 	// 中间件
 	r.Use(utils.CorsMiddleware())
 
-	// 创建处理器服务
-	handlerService := handlers.NewService(config)
+	// 创建处理器服务，v1接口按network参数选择网络
+	networks := handlers.NewNetworks(config)
 
 	// 主页路由
-	r.GET("/", handlerService.IndexHandler)
-	r.GET("/doc", handlerService.DocsHandler)
+	r.GET("/", networks.Default.IndexHandler)
+	r.GET("/doc", networks.Default.DocsHandler)
 
 	// API v1 路由组
 	v1 := r.Group("/v1")
 	{
 		// 工具接口
-		v1.Any("/status", handlerService.StatusHandler)
-		v1.Any("/getApiList", handlerService.GetApiListHandler)
+		v1.Any("/status", networks.Handle((*handlers.Service).StatusHandler))
+		v1.Any("/getApiList", networks.Handle((*handlers.Service).GetApiListHandler))
+		v1.Any("/getNetworks", networks.Handle((*handlers.Service).GetNetworksHandler))
 
 		// 地址生成相关接口
-		v1.Any("/createAddress", handlerService.CreateAddressHandler)
-		v1.Any("/generateAddressWithMnemonic", handlerService.GenerateAddressWithMnemonicHandler)
-		v1.Any("/getAddressByKey", handlerService.GetAddressByKeyHandler)
-		v1.Any("/mnemonicToAddress", handlerService.MnemonicToAddressHandler)
-		v1.Any("/mnemonicToAddressBatch", handlerService.MnemonicToAddressBatchHandler)
-		v1.Any("/privateKeyToAddress", handlerService.PrivateKeyToAddressHandler)
+		v1.Any("/createAddress", networks.Handle((*handlers.Service).CreateAddressHandler))
+		v1.Any("/generateAddressWithMnemonic", networks.Handle((*handlers.Service).GenerateAddressWithMnemonicHandler))
+		v1.Any("/getAddressByKey", networks.Handle((*handlers.Service).GetAddressByKeyHandler))
+		v1.Any("/mnemonicToAddress", networks.Handle((*handlers.Service).MnemonicToAddressHandler))
+		v1.Any("/mnemonicToAddressBatch", networks.Handle((*handlers.Service).MnemonicToAddressBatchHandler))
+		v1.Any("/privateKeyToAddress", networks.Handle((*handlers.Service).PrivateKeyToAddressHandler))
 
 		// 余额查询相关接口
-		v1.Any("/getTrxBalance", handlerService.GetTrxBalanceHandler)
-		v1.Any("/getTrc20Balance", handlerService.GetTrc20BalanceHandler)
-		v1.Any("/getTrc10Info", handlerService.GetTrc10InfoHandler)
-		v1.Any("/getTrc10Balance", handlerService.GetTrc10BalanceHandler)
+		v1.Any("/getTrxBalance", networks.Handle((*handlers.Service).GetTrxBalanceHandler))
+		v1.Any("/getTrc20Balance", networks.Handle((*handlers.Service).GetTrc20BalanceHandler))
+		v1.Any("/getTrc10Info", networks.Handle((*handlers.Service).GetTrc10InfoHandler))
+		v1.Any("/getTrc10Balance", networks.Handle((*handlers.Service).GetTrc10BalanceHandler))
 
 		// 转账相关接口
-		v1.Any("/sendTrx", handlerService.SendTrxHandler)
-		v1.Any("/sendTrc20", handlerService.SendTrc20Handler)
-		v1.Any("/sendTrc10", handlerService.SendTrc10Handler)
+		v1.Any("/sendTrx", networks.Idempotent((*handlers.Service).SendTrxHandler))
+		v1.Any("/sendTrc20", networks.Idempotent((*handlers.Service).SendTrc20Handler))
+		v1.Any("/sendTrc10", networks.Idempotent((*handlers.Service).SendTrc10Handler))
+
+		// TRC20授权相关接口
+		v1.Any("/approveTrc20", networks.Idempotent((*handlers.Service).ApproveTrc20Handler))
+		v1.Any("/getTrc20Allowance", networks.Handle((*handlers.Service).GetTrc20AllowanceHandler))
+		v1.Any("/increaseTrc20Allowance", networks.Idempotent((*handlers.Service).IncreaseAllowanceHandler))
+		v1.Any("/decreaseTrc20Allowance", networks.Idempotent((*handlers.Service).DecreaseAllowanceHandler))
+		v1.Any("/transferFromTrc20", networks.Idempotent((*handlers.Service).TransferFromTrc20Handler))
+
+		// TRC721 NFT相关接口
+		v1.Any("/getTrc721Owner", networks.Handle((*handlers.Service).GetTrc721OwnerHandler))
+		v1.Any("/getTrc721Balance", networks.Handle((*handlers.Service).GetTrc721BalanceHandler))
+		v1.Any("/getTrc721TokenURI", networks.Handle((*handlers.Service).GetTrc721TokenURIHandler))
+		v1.Any("/getTrc721TokenOfOwnerByIndex", networks.Handle((*handlers.Service).GetTrc721TokenOfOwnerByIndexHandler))
+		v1.Any("/listTrc721Tokens", networks.Handle((*handlers.Service).ListTrc721TokensHandler))
+		v1.Any("/sendTrc721", networks.Idempotent((*handlers.Service).SendTrc721Handler))
+
+		// 批量付款相关接口
+		v1.Any("/batchPayout", networks.Idempotent((*handlers.Service).BatchPayoutHandler))
+		v1.Any("/getBatchPayout", networks.Handle((*handlers.Service).GetBatchPayoutHandler))
+		v1.Any("/listBatchPayouts", networks.Handle((*handlers.Service).ListBatchPayoutsHandler))
+		v1.Any("/resumeBatchPayout", networks.Handle((*handlers.Service).ResumeBatchPayoutHandler))
+
+		// 发送队列相关接口
+		v1.Any("/queue", networks.Handle((*handlers.Service).ListQueueHandler))
+		v1.Any("/queue/get", networks.Handle((*handlers.Service).GetQueueJobHandler))
+		v1.Any("/queue/cancel", networks.Handle((*handlers.Service).CancelQueueJobHandler))
+		v1.Any("/queue/resume", networks.Handle((*handlers.Service).ResumeQueueJobHandler))
+
+		// 充值监控相关接口
+		v1.Any("/watch", networks.Handle((*handlers.Service).ListWatchHandler))
+		v1.Any("/watch/add", networks.Handle((*handlers.Service).AddWatchHandler))
+		v1.Any("/watch/remove", networks.Handle((*handlers.Service).RemoveWatchHandler))
+		v1.Any("/watch/deliveries", networks.Handle((*handlers.Service).WatchDeliveriesHandler))
+		v1.Any("/watch/redeliver", networks.Handle((*handlers.Service).RedeliverWatchHandler))
+
+		// 实时推送相关接口(SSE/WebSocket)
+		v1.GET("/stream/blocks", networks.Handle((*handlers.Service).StreamBlocksHandler))
+		v1.GET("/stream/transfers", networks.Handle((*handlers.Service).StreamTransfersHandler))
+
+		// 多签交易相关接口
+		v1.Any("/addSignature", networks.Handle((*handlers.Service).AddSignatureHandler))
+		v1.Any("/getSignWeight", networks.Handle((*handlers.Service).GetSignWeightHandler))
+		v1.Any("/getApprovedList", networks.Handle((*handlers.Service).GetApprovedListHandler))
+		v1.Any("/broadcastTransaction", networks.Idempotent((*handlers.Service).BroadcastTransactionHandler))
+
+		// 质押相关接口(Stake 2.0)
+		v1.Any("/freezeBalanceV2", networks.Idempotent((*handlers.Service).FreezeBalanceV2Handler))
+		v1.Any("/unfreezeBalanceV2", networks.Idempotent((*handlers.Service).UnfreezeBalanceV2Handler))
+		v1.Any("/withdrawExpireUnfreeze", networks.Idempotent((*handlers.Service).WithdrawExpireUnfreezeHandler))
+		v1.Any("/cancelAllUnfreezeV2", networks.Idempotent((*handlers.Service).CancelAllUnfreezeV2Handler))
+		v1.Any("/getUnfreezeInfo", networks.Handle((*handlers.Service).GetUnfreezeInfoHandler))
+
+		// 资源代理相关接口
+		v1.Any("/delegateResource", networks.Idempotent((*handlers.Service).DelegateResourceHandler))
+		v1.Any("/unDelegateResource", networks.Idempotent((*handlers.Service).UnDelegateResourceHandler))
+		v1.Any("/getDelegatedResource", networks.Handle((*handlers.Service).GetDelegatedResourceHandler))
+		v1.Any("/getCanDelegatedMaxSize", networks.Handle((*handlers.Service).GetCanDelegatedMaxSizeHandler))
+
+		// 资金归集相关接口
+		v1.Any("/createSweepJob", networks.Idempotent((*handlers.Service).CreateSweepJobHandler))
+		v1.Any("/getSweepJob", networks.Handle((*handlers.Service).GetSweepJobHandler))
+		v1.Any("/listSweepJobs", networks.Handle((*handlers.Service).ListSweepJobsHandler))
+		v1.Any("/pauseSweepJob", networks.Handle((*handlers.Service).PauseSweepJobHandler))
+		v1.Any("/resumeSweepJob", networks.Handle((*handlers.Service).ResumeSweepJobHandler))
+
+		// 账户权限相关接口
+		v1.Any("/getAccountPermission", networks.Handle((*handlers.Service).GetAccountPermissionHandler))
+		v1.Any("/updateAccountPermission", networks.Idempotent((*handlers.Service).UpdateAccountPermissionHandler))
 
 		// 交易查询相关接口
-		v1.Any("/getTransaction", handlerService.GetTransactionHandler)
-		v1.Any("/getTrc20TransactionReceipt", handlerService.GetTrc20TransactionReceiptHandler)
+		v1.Any("/getTransaction", networks.Handle((*handlers.Service).GetTransactionHandler))
+		v1.Any("/waitForTransaction", networks.Handle((*handlers.Service).WaitForTransactionHandler))
+		v1.Any("/getTrc20TransactionReceipt", networks.Handle((*handlers.Service).GetTrc20TransactionReceiptHandler))
+		v1.Any("/getTransactionHistory", networks.Handle((*handlers.Service).GetTransactionHistoryHandler))
+
+		// 智能合约相关接口
+		v1.Any("/getContractEvents", networks.Handle((*handlers.Service).GetContractEventsHandler))
+		v1.Any("/callContract", networks.Handle((*handlers.Service).CallContractHandler))
+		v1.Any("/triggerContract", networks.Idempotent((*handlers.Service).TriggerContractHandler))
+		v1.Any("/deployContract", networks.Idempotent((*handlers.Service).DeployContractHandler))
 
 		// 区块链信息查询接口
-		v1.Any("/getBlockHeight", handlerService.GetBlockHeightHandler)
-		v1.Any("/getBlockByNumber", handlerService.GetBlockByNumberHandler)
+		v1.Any("/getBlockHeight", networks.Handle((*handlers.Service).GetBlockHeightHandler))
+		v1.Any("/getBlockByNumber", networks.Handle((*handlers.Service).GetBlockByNumberHandler))
 	}
+
+	return networks
 }
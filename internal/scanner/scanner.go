@@ -0,0 +1,354 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/blocks"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+const (
+	// 扫描间隔(TRON约3秒出一个块)
+	scanInterval = 3 * time.Second
+	// 每轮最多扫描的区块数，落后较多时分多轮追赶
+	maxBlocksPerRound = 20
+)
+
+var errForkTooDeep = errors.New("分叉深度超过已固化区块，请检查节点是否正常")
+
+// 区块处理器。同一区块在服务中断后可能被重复调用，实现需要保证幂等
+type Handler interface {
+	// 新区块，尚未固化，之后可能因分叉回滚
+	Block(block *types.Block) error
+	// 已处理的区块因分叉被丢弃，按从高到低的顺序调用
+	Rollback(block *types.Block) error
+	// 区块已固化，不会再回滚，按从低到高的顺序调用
+	Final(block *types.Block) error
+}
+
+// 扫描器参数
+type Options struct {
+	Name    string                       // 进度文件名，保存在 data/scanner/<Name>.json
+	Handler Handler                      // 区块处理器
+	Filter  func(t *types.Transfer) bool // 只保留需要处理的转账，为空时保留全部
+	Idle    func() bool                  // 返回true时暂停扫描，恢复后从最新固化区块开始
+}
+
+// 已处理区块的摘要
+type blockRef struct {
+	Number int64  `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// 持久化的扫描进度
+type cursor struct {
+	Final   blockRef       `json:"final"`   // 已固化并处理完成的最高区块
+	Pending []*types.Block `json:"pending"` // 已处理但尚未固化的区块(只含筛选后的转账)
+}
+
+// 防分叉区块扫描器：在磁盘上记录处理进度，按父区块哈希检测分叉并回滚孤块中的事件，
+// 区块进入固化高度后再发出最终事件
+type Scanner struct {
+	config *types.Config
+	opts   Options
+	path   string
+
+	mu         sync.Mutex
+	cursor     cursor
+	head       int64
+	solid      int64
+	err        string
+	paused     bool
+	forkResets int   // 因分叉过深重置的次数
+	lastReset  int64 // 最近一次重置的时间
+}
+
+// 创建扫描器并加载处理进度
+func New(config *types.Config, opts Options) *Scanner {
+	s := &Scanner{
+		config: config,
+		opts:   opts,
+		path:   filepath.Join(config.DataDir, "scanner", opts.Name+".json"),
+	}
+	utils.ReadJSONFile(s.path, &s.cursor)
+	return s
+}
+
+// 在后台协程中持续扫描
+func (s *Scanner) Start() {
+	go func() {
+		ticker := time.NewTicker(scanInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			err := s.Scan()
+
+			s.mu.Lock()
+			s.err = ""
+			if err != nil {
+				s.err = err.Error()
+			}
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// 查询扫描状态
+func (s *Scanner) Status() types.ScannerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return types.ScannerStatus{
+		Final:   s.cursor.Final.Number,
+		Tip:     s.tipLocked().Number,
+		Head:    s.head,
+		Solid:   s.solid,
+		Pending: len(s.cursor.Pending),
+		Error:   s.err,
+
+		ForkResets:    s.forkResets,
+		LastForkReset: s.lastReset,
+	}
+}
+
+// 已处理的最高区块
+func (s *Scanner) tipLocked() blockRef {
+	if n := len(s.cursor.Pending); n > 0 {
+		b := s.cursor.Pending[n-1]
+		return blockRef{Number: b.Number, Hash: b.Hash}
+	}
+	return s.cursor.Final
+}
+
+func (s *Scanner) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return utils.WriteJSONFile(s.path, &s.cursor)
+}
+
+// 扫描一轮：处理新区块，检测并回滚分叉，发出已固化区块的最终事件
+func (s *Scanner) Scan() error {
+	s.mu.Lock()
+	if s.opts.Idle != nil && s.opts.Idle() {
+		s.paused = true
+		s.mu.Unlock()
+		return nil
+	}
+	paused := s.paused
+	s.mu.Unlock()
+
+	head, err := utils.GetNowBlockNumber(s.config, false)
+	if err != nil {
+		return fmt.Errorf("查询最新区块失败: %v", err)
+	}
+	solid, err := utils.GetNowBlockNumber(s.config, true)
+	if err != nil {
+		return fmt.Errorf("查询固化区块失败: %v", err)
+	}
+
+	s.mu.Lock()
+	s.head, s.solid = head, solid
+	start := s.cursor.Final.Number == 0 && len(s.cursor.Pending) == 0
+	s.mu.Unlock()
+
+	if paused {
+		// 暂停前已处理的区块先确认是否仍在主链上，发出最终事件或回滚，
+		// 仍有未到固化高度的区块时继续跟随链尾，否则跳过暂停期间的区块
+		if err := s.finalize(solid); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.paused = false
+		start = len(s.cursor.Pending) == 0
+		s.mu.Unlock()
+	}
+	// 首次运行或暂停后恢复时，从最新固化区块开始
+	if start {
+		return s.reset(solid)
+	}
+
+	for i := 0; i < maxBlocksPerRound; i++ {
+		s.mu.Lock()
+		tip := s.tipLocked()
+		s.mu.Unlock()
+		if tip.Number >= head {
+			break
+		}
+
+		block, err := blocks.Fetch(s.config, tip.Number+1)
+		if err != nil {
+			return fmt.Errorf("查询区块%d失败: %v", tip.Number+1, err)
+		}
+		if block == nil {
+			break
+		}
+
+		if block.ParentHash != tip.Hash {
+			// 新区块不是当前链尾的子块，说明发生了分叉，回滚链尾后重新比较
+			err := s.rollback()
+			if err == errForkTooDeep {
+				return s.recoverFork(solid)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.apply(block); err != nil {
+			return err
+		}
+	}
+
+	return s.finalize(solid)
+}
+
+// 将进度重置到指定区块，调用前未固化列表须为空
+func (s *Scanner) reset(num int64) error {
+	nb, err := utils.GetBlockByNum(s.config, num)
+	if err != nil {
+		return fmt.Errorf("查询区块%d失败: %v", num, err)
+	}
+	if nb == nil {
+		return fmt.Errorf("区块%d不存在", num)
+	}
+
+	s.mu.Lock()
+	s.cursor = cursor{Final: blockRef{Number: num, Hash: nb.BlockID}}
+	s.paused = false
+	s.mu.Unlock()
+	return s.save()
+}
+
+// 分叉深度超过已固化区块(通常是节点切换或节点数据异常)时，
+// 回滚全部未固化区块并从节点最新固化区块重新开始，重置次数和时间显示在状态中
+func (s *Scanner) recoverFork(solid int64) error {
+	for {
+		s.mu.Lock()
+		n := len(s.cursor.Pending)
+		s.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if err := s.rollback(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	final := s.cursor.Final.Number
+	s.mu.Unlock()
+	if err := s.reset(solid); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.forkResets++
+	s.lastReset = time.Now().Unix()
+	s.mu.Unlock()
+	fmt.Printf("⚠️  扫描器 %s %v，已从固化区块%d重置到%d\n", s.opts.Name, errForkTooDeep, final, solid)
+	return nil
+}
+
+// 处理新区块并加入未固化列表
+func (s *Scanner) apply(block *types.Block) error {
+	if s.opts.Filter != nil {
+		kept := block.Transfers[:0]
+		for i := range block.Transfers {
+			if s.opts.Filter(&block.Transfers[i]) {
+				kept = append(kept, block.Transfers[i])
+			}
+		}
+		block.Transfers = kept
+	}
+
+	if err := s.opts.Handler.Block(block); err != nil {
+		return fmt.Errorf("处理区块%d失败: %v", block.Number, err)
+	}
+
+	s.mu.Lock()
+	s.cursor.Pending = append(s.cursor.Pending, block)
+	s.mu.Unlock()
+	return s.save()
+}
+
+// 回滚链尾区块
+func (s *Scanner) rollback() error {
+	s.mu.Lock()
+	n := len(s.cursor.Pending)
+	if n == 0 {
+		s.mu.Unlock()
+		return errForkTooDeep
+	}
+	block := s.cursor.Pending[n-1]
+	s.mu.Unlock()
+
+	if err := s.opts.Handler.Rollback(block); err != nil {
+		return fmt.Errorf("回滚区块%d失败: %v", block.Number, err)
+	}
+
+	s.mu.Lock()
+	s.cursor.Pending = s.cursor.Pending[:n-1]
+	s.mu.Unlock()
+	return s.save()
+}
+
+// 发出已进入固化高度的区块的最终事件
+func (s *Scanner) finalize(solid int64) error {
+	// 先确认未固化列表中已到固化高度的最高区块仍在主链上，
+	// 由于区块按父哈希相连，该区块在主链上即说明更低的区块也在主链上
+	s.mu.Lock()
+	var highest *types.Block
+	for _, b := range s.cursor.Pending {
+		if b.Number <= solid {
+			highest = b
+		}
+	}
+	s.mu.Unlock()
+	if highest == nil {
+		return nil
+	}
+
+	nb, err := utils.GetBlockByNum(s.config, highest.Number)
+	if err != nil {
+		return fmt.Errorf("查询区块%d失败: %v", highest.Number, err)
+	}
+	if nb == nil || nb.BlockID != highest.Hash {
+		// 已处理的区块不在主链上，回滚到该高度之下，下一轮重新扫描
+		for {
+			s.mu.Lock()
+			tip := s.tipLocked()
+			s.mu.Unlock()
+			if tip.Number < highest.Number {
+				return nil
+			}
+			if err := s.rollback(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		s.mu.Lock()
+		if len(s.cursor.Pending) == 0 || s.cursor.Pending[0].Number > highest.Number {
+			s.mu.Unlock()
+			return nil
+		}
+		block := s.cursor.Pending[0]
+		s.mu.Unlock()
+
+		if err := s.opts.Handler.Final(block); err != nil {
+			return fmt.Errorf("处理固化区块%d失败: %v", block.Number, err)
+		}
+
+		s.mu.Lock()
+		s.cursor.Pending = s.cursor.Pending[1:]
+		s.cursor.Final = blockRef{Number: block.Number, Hash: block.Hash}
+		s.mu.Unlock()
+		if err := s.save(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/types"
+)
+
+// 可热加载的配置项(节点地址、重试策略、API Key、请求头及自定义网络)：变化时重建各网络的链客户端并替换，
+// 不影响已建立的连接和进行中的请求
+var reloadable = map[string]bool{
+	"tron_api_url":      true,
+	"solidity_node_url": true,
+	"event_server_url":  true,
+	"api_keys":          true,
+	"api_key_rate":      true,
+	"headers":           true,
+
+	"fallback_node_urls": true,
+	"upstream_timeout":   true,
+	"upstream_retries":   true,
+	"networks":           true,
+}
+
+// 配置热加载，收到SIGHUP时重新读取配置文件和环境变量
+type Reloader struct {
+	loader *Loader
+	apply  func(*types.Config) error
+
+	mu      sync.Mutex
+	applied *types.Config // 当前生效的配置
+}
+
+// config为启动时生效的配置，apply按新配置重建各网络的链客户端并更新其配置
+func NewReloader(loader *Loader, config *types.Config, apply func(*types.Config) error) *Reloader {
+	applied := *config
+	return &Reloader{loader: loader, apply: apply, applied: &applied}
+}
+
+// 将可热加载的配置项从src复制到dst
+func ApplyReloadable(dst, src *types.Config) {
+	dst.TronAPIURL = src.TronAPIURL
+	dst.SolidityNodeURL = src.SolidityNodeURL
+	dst.EventServerURL = src.EventServerURL
+	dst.FallbackNodeURLs = src.FallbackNodeURLs
+	dst.UpstreamTimeout = src.UpstreamTimeout
+	dst.UpstreamRetries = src.UpstreamRetries
+	dst.APIKeys = src.APIKeys
+	dst.APIKeyRate = src.APIKeyRate
+	dst.Headers = src.Headers
+	dst.Networks = src.Networks
+}
+
+// 监听SIGHUP信号并重新加载配置
+func (r *Reloader) Watch() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			if err := r.Reload(); err != nil {
+				fmt.Printf("⚠️  重新加载配置失败，继续使用原配置: %v\n", err)
+			}
+		}
+	}()
+}
+
+// 重新加载配置，只应用可热加载的配置项，其他变化的配置项需重启服务后生效
+func (r *Reloader) Reload() error {
+	next, err := r.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var hot, restart []string
+	for _, key := range changed(r.applied, next) {
+		if reloadable[key] {
+			hot = append(hot, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+
+	if len(hot) > 0 && rebuildable(r.applied.Backend) {
+		candidate := *r.applied
+		ApplyReloadable(&candidate, next)
+		if err := r.apply(&candidate); err != nil {
+			return err
+		}
+		r.applied = &candidate
+		fmt.Printf("🔄 配置已重新加载: %s\n", strings.Join(hot, ", "))
+	} else {
+		fmt.Printf("🔄 配置已重新加载，无可热加载的变化\n")
+	}
+	if len(restart) > 0 {
+		fmt.Printf("⚠️  以下配置项需重启服务后生效: %s\n", strings.Join(restart, ", "))
+	}
+	return nil
+}
+
+// 仅HTTP节点后端按新地址重建，fixture和模拟节点重建会丢失状态
+func rebuildable(backend string) bool {
+	switch strings.ToLower(backend) {
+	case "", chain.BackendTronGrid, chain.BackendFullNode:
+		return true
+	}
+	return false
+}
+
+// 两份配置中取值不同的配置项
+func changed(a, b *types.Config) []string {
+	before, after := fields(a), fields(b)
+	var keys []string
+	for key, v := range after {
+		if !reflect.DeepEqual(before[key], v) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fields(config *types.Config) map[string]interface{} {
+	data, _ := json.Marshal(config)
+	m := make(map[string]interface{})
+	json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,291 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/network"
+	"tron-api-go/internal/types"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 环境变量前缀，如 TRON_API_PORT、TRON_API_NODE
+const EnvPrefix = "TRON_API_"
+
+// 可通过配置文件、环境变量和命令行参数设置的配置项
+type option struct {
+	key    string // 配置文件中的键，与types.Config的json标签一致
+	flag   string // 命令行参数名
+	env    string // 环境变量名(不含前缀)
+	number bool   // 数值类型
+	list   bool   // 列表，命令行参数和环境变量中以逗号分隔
+	usage  string
+}
+
+var options = []option{
+	{key: "port", flag: "port", env: "PORT", usage: "服务端口"},
+	{key: "network", flag: "network", env: "NETWORK", usage: "网络: mainnet、shasta、nile 或自定义网络"},
+	{key: "backend", flag: "backend", env: "BACKEND", usage: "链后端: trongrid、fullnode、fixture 或 simulator"},
+	{key: "fixture_file", flag: "fixture", env: "FIXTURE", usage: "fixture后端的JSON数据文件"},
+	{key: "tron_api_url", flag: "node", env: "NODE", usage: "TronGrid或java-tron全节点地址"},
+	{key: "solidity_node_url", flag: "solidity-node", env: "SOLIDITY_NODE", usage: "固化节点地址，默认与全节点相同"},
+	{key: "event_server_url", flag: "event-server", env: "EVENT_SERVER", usage: "TronGrid /v1 接口地址，默认与全节点相同"},
+	{key: "contract_address", flag: "usdt", env: "USDT", usage: "USDT合约地址"},
+	{key: "decimals", flag: "decimals", env: "DECIMALS", number: true, usage: "USDT精度"},
+	{key: "explorer_url", flag: "explorer", env: "EXPLORER", usage: "交易浏览器链接模板，{txid}替换为交易ID"},
+	{key: "fee_limit", flag: "fee-limit", env: "FEE_LIMIT", number: true, usage: "合约调用手续费上限(SUN)"},
+	{key: "data_dir", flag: "data-dir", env: "DATA_DIR", usage: "任务数据存储目录"},
+	{key: "queue_workers", flag: "queue-workers", env: "QUEUE_WORKERS", number: true, usage: "发送队列工作协程数"},
+	{key: "api_keys", flag: "api-keys", env: "KEYS", list: true, usage: "TronGrid API Key，多个以逗号分隔"},
+	{key: "api_key_rate", flag: "api-key-rate", env: "KEY_RATE", number: true, usage: "每个API Key每秒请求数上限，0为不限制"},
+	{key: "fallback_node_urls", flag: "fallback-nodes", env: "FALLBACK_NODES", list: true, usage: "备用全节点地址，多个以逗号分隔"},
+	{key: "upstream_timeout", flag: "upstream-timeout", env: "UPSTREAM_TIMEOUT", number: true, usage: "单次请求节点的超时时间(秒)"},
+	{key: "upstream_retries", flag: "upstream-retries", env: "UPSTREAM_RETRIES", number: true, usage: "只读请求失败后的最大重试次数"},
+}
+
+// 默认配置，节点地址和USDT合约地址由网络配置决定
+func Default() *types.Config {
+	return &types.Config{
+		Port:         "9527",
+		Network:      network.Mainnet,
+		Decimals:     6,         // USDT 精度
+		FeeLimit:     100000000, // 合约调用手续费上限 100 TRX
+		DataDir:      "data",    // 归集任务等数据存储目录
+		QueueWorkers: 2,         // 发送队列工作协程数
+
+		UpstreamTimeout: 10, // 单次请求节点超时 10 秒
+		UpstreamRetries: 2,  // 只读请求失败后重试 2 次
+	}
+}
+
+// 配置加载器，优先级：命令行参数 > 环境变量 > 配置文件 > 网络配置 > 默认值。
+// 命令行参数在启动时解析一次，配置文件和环境变量每次加载时重新读取
+type Loader struct {
+	path  string                 // 配置文件路径，为空时不读取
+	flags map[string]interface{} // 命令行中显式设置的配置项
+}
+
+// 解析命令行参数，配置文件路径由 -config 参数或 TRON_API_CONFIG 环境变量指定
+func NewLoader(args []string) (*Loader, error) {
+	fs := flag.NewFlagSet("tron-api-go", flag.ExitOnError)
+	path := fs.String("config", os.Getenv(EnvPrefix+"CONFIG"), "配置文件路径(.yaml、.yml、.toml 或 .json)")
+	simulate := fs.Bool("simulate", false, "使用进程内模拟节点，不访问网络(等同于 -backend simulator)")
+	values := make(map[string]*string, len(options))
+	for _, opt := range options {
+		values[opt.flag] = fs.String(opt.flag, "", opt.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	l := &Loader{path: *path, flags: make(map[string]interface{})}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, opt := range options {
+			if opt.flag == f.Name && err == nil {
+				l.flags[opt.key], err = convert(opt, *values[f.Name])
+				if err != nil {
+					err = fmt.Errorf("命令行参数 -%s: %v", f.Name, err)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if *simulate {
+		l.flags["backend"] = chain.BackendSimulator
+	}
+	return l, nil
+}
+
+// 配置文件路径
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// 按优先级合并各来源的配置并校验
+func (l *Loader) Load() (*types.Config, error) {
+	values := make(map[string]interface{})
+	if l.path != "" {
+		file, err := readFile(l.path)
+		if err != nil {
+			return nil, err
+		}
+		merge(values, file)
+	}
+	env, err := readEnv()
+	if err != nil {
+		return nil, err
+	}
+	merge(values, env)
+	merge(values, l.flags)
+
+	config, err := build(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// 由合并后的配置项生成配置：先应用网络配置，再用显式设置的项覆盖
+func build(values map[string]interface{}) (*types.Config, error) {
+	// 先解析一次以取得网络名称和自定义网络
+	explicit := Default()
+	if err := decode(values, explicit); err != nil {
+		return nil, err
+	}
+	profile, err := network.Lookup(explicit, explicit.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Default()
+	config.Networks = explicit.Networks
+	network.Apply(config, profile)
+	if _, ok := values["tron_api_url"]; ok {
+		// 自定义全节点时固化节点和事件服务默认使用同一地址
+		config.SolidityNodeURL, config.EventServerURL = "", ""
+	}
+	if err := decode(values, config); err != nil {
+		return nil, err
+	}
+	config.Network = profile.Name
+	return config, nil
+}
+
+// 将配置项写入config，不认识的配置项视为错误
+func decode(values map[string]interface{}, config *types.Config) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(config); err != nil {
+		return fmt.Errorf("配置项错误: %v", err)
+	}
+	return nil
+}
+
+// 读取配置文件，按扩展名选择格式
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	default:
+		return nil, fmt.Errorf("不支持的配置文件格式: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+
+	// 标量配置项统一按字符串转换，端口等可写成数字或字符串
+	for _, opt := range options {
+		v, ok := values[opt.key]
+		if !ok || v == nil {
+			continue
+		}
+		if _, isList := v.([]interface{}); isList && opt.list {
+			continue
+		}
+		if values[opt.key], err = convert(opt, scalar(v)); err != nil {
+			return nil, fmt.Errorf("配置文件 %s 中的 %s: %v", path, opt.key, err)
+		}
+	}
+	if err := decode(values, Default()); err != nil {
+		return nil, fmt.Errorf("配置文件 %s: %v", path, err)
+	}
+	return values, nil
+}
+
+// 读取环境变量，空值视为未设置
+func readEnv() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, opt := range options {
+		name := EnvPrefix + opt.env
+		s := os.Getenv(name)
+		if s == "" {
+			continue
+		}
+		v, err := convert(opt, s)
+		if err != nil {
+			return nil, fmt.Errorf("环境变量 %s: %v", name, err)
+		}
+		values[opt.key] = v
+	}
+	if simulate, _ := strconv.ParseBool(os.Getenv(EnvPrefix + "SIMULATE")); simulate {
+		values["backend"] = chain.BackendSimulator
+	}
+	return values, nil
+}
+
+// 配置文件中的标量值转为字符串，浮点数不使用科学计数法(150000000 而非 1.5e+08)
+func scalar(v interface{}) string {
+	switch n := v.(type) {
+	case json.Number:
+		// 整数原样保留，避免超过2^53的数值经float64丢失精度
+		if _, err := n.Int64(); err != nil {
+			if f, err := n.Float64(); err == nil {
+				return strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		}
+		return n.String()
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
+
+func convert(opt option, s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if opt.list {
+		list := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	}
+	if !opt.number {
+		return s, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("需要整数: %s", s)
+	}
+	return n, nil
+}
+
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
@@ -0,0 +1,137 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// 合约调用手续费上限的最大值 15000 TRX
+const maxFeeLimit = 15000000000
+
+// 校验配置，返回全部错误
+func Validate(config *types.Config) error {
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(config.Port); err != nil || port < 1 || port > 65535 {
+		fail("port 无效: %q", config.Port)
+	}
+
+	switch strings.ToLower(config.Backend) {
+	case "", chain.BackendTronGrid, chain.BackendFullNode:
+		if err := checkURL(config.TronAPIURL); err != nil {
+			fail("tron_api_url %v", err)
+		}
+		if config.SolidityNodeURL != "" {
+			if err := checkURL(config.SolidityNodeURL); err != nil {
+				fail("solidity_node_url %v", err)
+			}
+		}
+		if config.EventServerURL != "" {
+			if err := checkURL(config.EventServerURL); err != nil {
+				fail("event_server_url %v", err)
+			}
+		}
+		for i, u := range config.FallbackNodeURLs {
+			if err := checkURL(u); err != nil {
+				fail("fallback_node_urls 第 %d 项%v", i+1, err)
+			}
+		}
+	case chain.BackendFixture:
+		if config.FixtureFile == "" {
+			fail("fixture后端需要设置 fixture_file")
+		}
+	case chain.BackendSimulator:
+	default:
+		fail("backend 不支持: %s", config.Backend)
+	}
+
+	if !tron.IsValidAddress(config.ContractAddress) {
+		fail("contract_address 不是有效的TRON地址: %q", config.ContractAddress)
+	}
+	if config.Decimals < 0 || config.Decimals > 77 {
+		fail("decimals 需在 0 至 77 之间: %d", config.Decimals)
+	}
+	if config.FeeLimit <= 0 || config.FeeLimit > maxFeeLimit {
+		fail("fee_limit 需在 1 至 %d SUN 之间: %d", int64(maxFeeLimit), config.FeeLimit)
+	}
+	if config.QueueWorkers < 1 {
+		fail("queue_workers 至少为 1: %d", config.QueueWorkers)
+	}
+	if strings.TrimSpace(config.DataDir) == "" {
+		fail("data_dir 不能为空")
+	}
+	if config.ExplorerURL != "" && !strings.Contains(config.ExplorerURL, "{txid}") {
+		fail("explorer_url 需包含 {txid}: %s", config.ExplorerURL)
+	}
+
+	if config.UpstreamTimeout < 1 || config.UpstreamTimeout > 300 {
+		fail("upstream_timeout 需在 1 至 300 秒之间: %d", config.UpstreamTimeout)
+	}
+	if config.UpstreamRetries < 0 || config.UpstreamRetries > 10 {
+		fail("upstream_retries 需在 0 至 10 之间: %d", config.UpstreamRetries)
+	}
+	if config.APIKeyRate < 0 {
+		fail("api_key_rate 不能为负数: %d", config.APIKeyRate)
+	}
+	seen := make(map[string]bool, len(config.APIKeys))
+	for i, key := range config.APIKeys {
+		if strings.TrimSpace(key) == "" || seen[key] {
+			fail("api_keys 第 %d 项为空或重复", i+1)
+		}
+		seen[key] = true
+	}
+	for name, value := range config.Headers {
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			fail("headers 中的请求头无效: %q", name)
+		}
+	}
+
+	names := make([]string, 0, len(config.Networks))
+	for name := range config.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := config.Networks[name]
+		if err := checkURL(n.FullNodeURL); err != nil {
+			fail("networks.%s.full_node_url %v", name, err)
+		}
+		for i, u := range n.FallbackNodeURLs {
+			if err := checkURL(u); err != nil {
+				fail("networks.%s.fallback_node_urls 第 %d 项%v", name, i+1, err)
+			}
+		}
+		if n.ContractAddress != "" && !tron.IsValidAddress(n.ContractAddress) {
+			fail("networks.%s.contract_address 不是有效的TRON地址: %q", name, n.ContractAddress)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("配置无效:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func checkURL(s string) error {
+	if s == "" {
+		return errors.New("不能为空")
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("不是有效的http(s)地址: %q", s)
+	}
+	return nil
+}
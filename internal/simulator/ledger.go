@@ -0,0 +1,498 @@
+package simulator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// 模拟链的资源价格
+const (
+	energyPrice      = 420     // 每单位能量的价格(SUN)
+	createAccountFee = 1100000 // 转账激活新账户：1 TRX创建费 + 0.1 TRX带宽费
+	expiration       = 60 * time.Second
+)
+
+// 支持的交易类型
+const (
+	contractTransfer      = "TransferContract"
+	contractTransferAsset = "TransferAssetContract"
+	contractTrigger       = "TriggerSmartContract"
+)
+
+// 交易原始数据，按visible=true的JSON格式保存，raw_data_hex与java-tron一样为其protobuf编码
+type rawData struct {
+	Contract      []rawContract `json:"contract"`
+	RefBlockBytes string        `json:"ref_block_bytes"`
+	RefBlockHash  string        `json:"ref_block_hash"`
+	Expiration    int64         `json:"expiration"`
+	Data          string        `json:"data,omitempty"`
+	FeeLimit      int64         `json:"fee_limit,omitempty"`
+	Timestamp     int64         `json:"timestamp"`
+}
+
+type rawContract struct {
+	Type      string `json:"type"`
+	Parameter struct {
+		Value   contractValue `json:"value"`
+		TypeURL string        `json:"type_url"`
+	} `json:"parameter"`
+	PermissionID int `json:"Permission_id,omitempty"`
+}
+
+type contractValue struct {
+	OwnerAddress    string `json:"owner_address"`
+	ToAddress       string `json:"to_address,omitempty"`
+	Amount          int64  `json:"amount,omitempty"`
+	AssetName       string `json:"asset_name,omitempty"`
+	ContractAddress string `json:"contract_address,omitempty"`
+	Data            string `json:"data,omitempty"`
+	CallValue       int64  `json:"call_value,omitempty"`
+}
+
+func (r *record) contract() *rawContract {
+	return &r.raw.Contract[0]
+}
+
+func (r *record) value() *contractValue {
+	return &r.contract().Parameter.Value
+}
+
+// 交易涉及的地址，用于按账户查询
+func (r *record) parties() []string {
+	v := r.value()
+	list := []string{v.OwnerAddress}
+	add := func(addr string) {
+		if addr == "" {
+			return
+		}
+		for _, a := range list {
+			if a == addr {
+				return
+			}
+		}
+		list = append(list, addr)
+	}
+	add(v.ToAddress)
+	add(v.ContractAddress)
+	for _, t := range r.transfers {
+		add(t.from)
+		add(t.to)
+	}
+	return list
+}
+
+// 账户是否为交易的发起方、接收方或被调用的合约
+func (r *record) involves(address string) bool {
+	v := r.value()
+	return v.OwnerAddress == address || v.ToAddress == address || v.ContractAddress == address
+}
+
+// 构建未签名交易，引用最新区块
+func (n *Node) build(kind string, value contractValue, feeLimit int64, permissionID int, memo string) (*types.Transaction, error) {
+	now := time.Now().UnixMilli()
+	if now <= n.clock {
+		now = n.clock + 1
+	}
+	n.clock = now
+
+	head := n.head()
+	raw := rawData{
+		RefBlockBytes: fmt.Sprintf("%04x", head.number&0xffff),
+		RefBlockHash:  head.id[16:32],
+		Expiration:    now + expiration.Milliseconds(),
+		Data:          hex.EncodeToString([]byte(memo)),
+		Timestamp:     now,
+	}
+	if kind == contractTrigger {
+		raw.FeeLimit = feeLimit
+	}
+	c := rawContract{Type: kind, PermissionID: permissionID}
+	c.Parameter.Value = value
+	c.Parameter.TypeURL = "type.googleapis.com/protocol." + kind
+	raw.Contract = []rawContract{c}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := encodeRaw(&raw)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(encoded)
+	return &types.Transaction{
+		Visible:    true,
+		TxID:       hex.EncodeToString(sum[:]),
+		RawData:    data,
+		RawDataHex: hex.EncodeToString(encoded),
+	}, nil
+}
+
+// 按java-tron的protobuf定义编码交易原始数据
+func encodeRaw(raw *rawData) ([]byte, error) {
+	refBytes, err := hex.DecodeString(raw.RefBlockBytes)
+	if err != nil {
+		return nil, err
+	}
+	refHash, err := hex.DecodeString(raw.RefBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	memo, err := hex.DecodeString(raw.Data)
+	if err != nil {
+		return nil, err
+	}
+	out := tron.RawData{
+		RefBlockBytes: refBytes,
+		RefBlockHash:  refHash,
+		Expiration:    raw.Expiration,
+		Data:          memo,
+		Timestamp:     raw.Timestamp,
+		FeeLimit:      raw.FeeLimit,
+	}
+	for _, c := range raw.Contract {
+		value, err := encodeValue(c.Type, &c.Parameter.Value)
+		if err != nil {
+			return nil, err
+		}
+		out.Contracts = append(out.Contracts, tron.RawContract{
+			Type:         tron.ContractTypes[c.Type],
+			TypeURL:      c.Parameter.TypeURL,
+			Value:        value,
+			PermissionID: c.PermissionID,
+		})
+	}
+	return out.Marshal(), nil
+}
+
+// 合约参数的protobuf编码，字段编号与java-tron的合约定义一致
+func encodeValue(kind string, v *contractValue) ([]byte, error) {
+	var e tron.Encoder
+	owner := mustParse(v.OwnerAddress)
+	switch kind {
+	case contractTransfer:
+		to := mustParse(v.ToAddress)
+		e.Bytes(1, owner[:])
+		e.Bytes(2, to[:])
+		e.Varint(3, uint64(v.Amount))
+	case contractTransferAsset:
+		to := mustParse(v.ToAddress)
+		e.Bytes(1, []byte(v.AssetName))
+		e.Bytes(2, owner[:])
+		e.Bytes(3, to[:])
+		e.Varint(4, uint64(v.Amount))
+	case contractTrigger:
+		contract := mustParse(v.ContractAddress)
+		data, err := hex.DecodeString(v.Data)
+		if err != nil {
+			return nil, err
+		}
+		e.Bytes(1, owner[:])
+		e.Bytes(2, contract[:])
+		e.Varint(3, uint64(v.CallValue))
+		e.Bytes(4, data)
+	default:
+		return nil, fmt.Errorf("模拟节点不支持交易类型 %s", kind)
+	}
+	return e.Result(), nil
+}
+
+// 解码raw_data_hex，只接受模拟节点支持的单合约交易
+func decodeRaw(rawDataHex string) (rawData, error) {
+	var raw rawData
+	decoded, err := tron.DecodeRawData(rawDataHex)
+	if err != nil {
+		return raw, err
+	}
+	if len(decoded.Contracts) != 1 {
+		return raw, errors.New("transaction must contain exactly one contract")
+	}
+	raw.RefBlockBytes = hex.EncodeToString(decoded.RefBlockBytes)
+	raw.RefBlockHash = hex.EncodeToString(decoded.RefBlockHash)
+	raw.Expiration = decoded.Expiration
+	raw.Data = hex.EncodeToString(decoded.Data)
+	raw.FeeLimit = decoded.FeeLimit
+	raw.Timestamp = decoded.Timestamp
+
+	c := decoded.Contracts[0]
+	fields, err := tron.DecodeFields(c.Value)
+	if err != nil {
+		return raw, err
+	}
+	address := func(num int) string {
+		b := fields[num].Bytes
+		if len(b) == 0 {
+			return ""
+		}
+		var addr tron.Address
+		copy(addr[:], b)
+		return addr.Base58()
+	}
+
+	rc := rawContract{Type: tron.ContractTypeName(c.Type), PermissionID: c.PermissionID}
+	rc.Parameter.TypeURL = c.TypeURL
+	v := &rc.Parameter.Value
+	switch rc.Type {
+	case contractTransfer:
+		v.OwnerAddress, v.ToAddress, v.Amount = address(1), address(2), int64(fields[3].Varint)
+	case contractTransferAsset:
+		v.AssetName, v.OwnerAddress, v.ToAddress, v.Amount = string(fields[1].Bytes), address(2), address(3), int64(fields[4].Varint)
+	case contractTrigger:
+		v.OwnerAddress, v.ContractAddress = address(1), address(2)
+		v.CallValue, v.Data = int64(fields[3].Varint), hex.EncodeToString(fields[4].Bytes)
+	default:
+		return raw, fmt.Errorf("unsupported contract type %s", rc.Type)
+	}
+	raw.Contract = []rawContract{rc}
+	return raw, nil
+}
+
+// 广播失败
+type broadcastError struct {
+	code    string
+	message string
+}
+
+func (e *broadcastError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// 校验已签名交易并放入交易池
+func (n *Node) broadcast(tx *types.Transaction) error {
+	data, err := hex.DecodeString(tx.RawDataHex)
+	if err != nil {
+		return &broadcastError{"OTHER_ERROR", "raw_data_hex is not valid hex"}
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != tx.TxID {
+		return &broadcastError{"SIGERROR", "txID does not match raw_data_hex"}
+	}
+
+	r := &record{tx: tx}
+	if r.raw, err = decodeRaw(tx.RawDataHex); err != nil {
+		return &broadcastError{"OTHER_ERROR", err.Error()}
+	}
+	if r.raw.Expiration < time.Now().UnixMilli() {
+		return &broadcastError{"TRANSACTION_EXPIRATION_ERROR", "Transaction expired"}
+	}
+	if _, ok := n.txs[tx.TxID]; ok {
+		return &broadcastError{"DUP_TRANSACTION_ERROR", "Dup transaction."}
+	}
+	if err := checkSignature(r); err != nil {
+		return &broadcastError{"SIGERROR", err.Error()}
+	}
+	if err := n.validate(r); err != nil {
+		return &broadcastError{"CONTRACT_VALIDATE_ERROR", err.Error()}
+	}
+
+	n.txs[tx.TxID] = r
+	n.pending = append(n.pending, r)
+	return nil
+}
+
+// 模拟链的账户均为默认权限(owner和active只有账户自身一个密钥，阈值为1)，只需校验所有者已签名
+func checkSignature(r *record) error {
+	if len(r.tx.Signature) == 0 {
+		return errors.New("miss sig or contract")
+	}
+	signers, err := signers(r.tx)
+	if err != nil {
+		return err
+	}
+	owner := r.value().OwnerAddress
+	for _, s := range signers {
+		if s == owner {
+			return nil
+		}
+	}
+	return fmt.Errorf("Validate signature error: %s is signed by %v but it is not contained of permission", r.tx.TxID, signers)
+}
+
+// 从签名中恢复签名者地址
+func signers(tx *types.Transaction) ([]string, error) {
+	list := make([]string, 0, len(tx.Signature))
+	for _, sig := range tx.Signature {
+		addr, err := tron.RecoverSigner(tx.TxID, sig)
+		if err != nil {
+			return nil, fmt.Errorf("Validate signature error: %v", err)
+		}
+		list = append(list, addr.Base58())
+	}
+	return list, nil
+}
+
+// 按当前状态校验交易，错误信息与java-tron一致
+func (n *Node) validate(r *record) error {
+	c, v := r.contract(), r.value()
+	owner, ok := n.accounts[v.OwnerAddress]
+	if !ok {
+		return fmt.Errorf("Validate %s error, no OwnerAccount.", c.Type)
+	}
+
+	switch c.Type {
+	case contractTransfer:
+		if v.Amount <= 0 {
+			return errors.New("Amount must be greater than 0.")
+		}
+		if v.ToAddress == v.OwnerAddress {
+			return errors.New("Cannot transfer TRX to yourself.")
+		}
+		if owner.balance < v.Amount+n.activationFee(v.ToAddress) {
+			return errors.New("Validate TransferContract error, balance is not sufficient.")
+		}
+	case contractTransferAsset:
+		if v.AssetName != AssetID {
+			return errors.New("No asset!")
+		}
+		if v.Amount <= 0 {
+			return errors.New("Amount must be greater than 0.")
+		}
+		if v.ToAddress == v.OwnerAddress {
+			return errors.New("Cannot transfer asset to yourself.")
+		}
+		if owner.assets[AssetID] < v.Amount {
+			return errors.New("assetBalance is not sufficient.")
+		}
+		if owner.balance < n.activationFee(v.ToAddress) {
+			return errors.New("Validate TransferAssetContract error, balance is not sufficient.")
+		}
+	case contractTrigger:
+		if _, ok := n.tokens[v.ContractAddress]; !ok {
+			return errors.New("No contract or not a valid smart contract")
+		}
+		if v.CallValue != 0 {
+			return errors.New("callValue must be 0 for non-payable contract")
+		}
+	default:
+		return fmt.Errorf("模拟节点不支持交易类型 %s", c.Type)
+	}
+	return nil
+}
+
+// 接收地址未激活时需要的激活费用
+func (n *Node) activationFee(address string) int64 {
+	if _, ok := n.accounts[address]; ok {
+		return 0
+	}
+	return createAccountFee
+}
+
+// 在出块时执行交易并记录执行结果
+func (n *Node) execute(r *record) {
+	v := r.value()
+	owner := n.accounts[v.OwnerAddress]
+	r.ret = "SUCCESS"
+	r.info.Receipt.NetUsage = int64(len(r.tx.RawDataHex)/2 + 65*len(r.tx.Signature))
+
+	switch r.contract().Type {
+	case contractTransfer, contractTransferAsset:
+		if fee := n.activationFee(v.ToAddress); fee > 0 {
+			owner.balance -= fee
+			n.accounts[v.ToAddress] = &account{assets: map[string]int64{}, created: r.raw.Timestamp}
+			r.info.Fee, r.info.Receipt.NetFee, r.info.Receipt.NetUsage = fee, fee, 0
+		}
+		to := n.accounts[v.ToAddress]
+		if r.contract().Type == contractTransfer {
+			owner.balance -= v.Amount
+			to.balance += v.Amount
+		} else {
+			owner.assets[AssetID] -= v.Amount
+			to.assets[AssetID] += v.Amount
+		}
+
+	case contractTrigger:
+		tok := n.tokens[v.ContractAddress]
+		data, _ := hex.DecodeString(v.Data)
+		r.info.ContractAddress = mustParse(v.ContractAddress).Hex()
+
+		// 先试运行计算能量，能量费超过fee_limit或余额时不修改状态
+		exec, err := tok.execute(v.OwnerAddress, data, true)
+		if err != nil {
+			exec = &execution{energy: energyRead, revert: err.Error(), result: revertData(err.Error())}
+		}
+		limit := r.raw.FeeLimit
+		if owner.balance < limit {
+			limit = owner.balance
+		}
+		fee := exec.energy * energyPrice
+		switch {
+		case fee > limit:
+			r.ret, fee = "OUT_OF_ENERGY", limit
+			r.info.ResMessage = hex.EncodeToString([]byte("Not enough energy for 'SSTORE' operation executing"))
+		case exec.revert != "":
+			r.ret = "REVERT"
+			r.info.ResMessage = hex.EncodeToString([]byte("REVERT opcode executed"))
+			r.info.ContractResult = []string{hex.EncodeToString(exec.result)}
+		default:
+			exec, _ = tok.execute(v.OwnerAddress, data, false)
+			r.info.ContractResult = []string{hex.EncodeToString(exec.result)}
+			r.info.Log = exec.logs
+			r.transfers = exec.events
+		}
+		owner.balance -= fee
+		r.info.Fee = fee
+		r.info.Receipt.EnergyFee = fee
+		r.info.Receipt.EnergyUsageTotal = fee / energyPrice
+		r.info.Receipt.Result = r.ret
+	}
+
+	if r.ret != "SUCCESS" {
+		r.info.Result = "FAILED"
+	}
+}
+
+// 区块及接口中的交易，visible为false时地址使用十六进制
+func (r *record) render(visible bool) map[string]interface{} {
+	raw := r.raw
+	raw.Contract = append([]rawContract(nil), r.raw.Contract...)
+	if !visible {
+		v := &raw.Contract[0].Parameter.Value
+		v.OwnerAddress = hexAddress(v.OwnerAddress)
+		v.ToAddress = hexAddress(v.ToAddress)
+		v.ContractAddress = hexAddress(v.ContractAddress)
+		v.AssetName = hex.EncodeToString([]byte(v.AssetName))
+	}
+
+	tx := map[string]interface{}{
+		"visible":      visible,
+		"txID":         r.tx.TxID,
+		"raw_data":     raw,
+		"raw_data_hex": r.tx.RawDataHex,
+		"signature":    r.tx.Signature,
+	}
+	if r.block != nil {
+		tx["ret"] = []map[string]interface{}{{"contractRet": r.ret}}
+	}
+	return tx
+}
+
+// TronGrid /v1/accounts/{address}/transactions 中的交易
+func (r *record) grid() map[string]interface{} {
+	tx := r.render(false)
+	tx["blockNumber"] = r.block.number
+	tx["block_timestamp"] = r.block.timestamp
+	tx["net_fee"] = r.info.Receipt.NetFee
+	tx["energy_fee"] = r.info.Receipt.EnergyFee
+	tx["ret"] = []map[string]interface{}{{"contractRet": r.ret, "fee": r.info.Fee}}
+	return tx
+}
+
+func hexAddress(s string) string {
+	if s == "" {
+		return ""
+	}
+	return mustParse(s).Hex()
+}
+
+// 解析已校验过的地址
+func mustParse(s string) tron.Address {
+	addr, _ := tron.ParseAddress(s)
+	return addr
+}
@@ -0,0 +1,420 @@
+package simulator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// 后端名称
+const Name = "simulator"
+
+// 模拟链的TRC10代币ID
+const AssetID = "1000001"
+
+// 模拟节点选项
+type Options struct {
+	BlockInterval time.Duration // 出块间隔，为0时只能调用Produce手动出块
+	SolidDepth    int64         // 固化区块落后最新区块的块数
+	Accounts      int           // 预置开发账户数量
+	Balance       int64         // 每个开发账户的TRX余额(SUN)
+	Token         string        // 模拟TRC20合约地址
+	TokenDecimals int           // 模拟TRC20代币精度
+	TokenBalance  int64         // 每个开发账户的TRC20余额(整数单位)
+}
+
+// 默认选项：3秒出块，落后2块固化，5个开发账户各持有10000 TRX、100万USDT和100万TRC10代币
+func DefaultOptions() Options {
+	return Options{
+		BlockInterval: 3 * time.Second,
+		SolidDepth:    2,
+		Accounts:      5,
+		Balance:       10000 * 1000000,
+		Token:         "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		TokenDecimals: 6,
+		TokenBalance:  1000000,
+	}
+}
+
+// 预置的开发账户
+type DevAccount struct {
+	Address    string `json:"address"`
+	PrivateKey string `json:"privateKey"`
+}
+
+// 进程内模拟的TRON节点，实现 types.ChainClient。
+// 状态全部保存在内存中：账户TRX及TRC10余额、TRC20合约账本、区块和交易池。
+// 广播的交易经过txID、过期时间、签名和余额校验后进入交易池，按出块间隔打包。
+type Node struct {
+	opts Options
+
+	mu       sync.Mutex
+	accounts map[string]*account // 键为Base58地址
+	tokens   map[string]*token   // 键为合约Base58地址
+	blocks   []*block
+	pending  []*record
+	txs      map[string]*record // 已上链及交易池中的交易
+	history  map[string][]*record
+	dev      []DevAccount
+	clock    int64 // 上一笔交易的时间戳，保证同一毫秒内构建的交易ID不同
+
+	stop chan struct{}
+}
+
+type account struct {
+	balance int64
+	assets  map[string]int64
+	created int64
+}
+
+type block struct {
+	number    int64
+	id        string
+	parent    string
+	timestamp int64
+	txs       []*record
+}
+
+// 交易及其执行结果
+type record struct {
+	tx    *types.Transaction
+	raw   rawData
+	block *block
+	ret   string // 执行结果 SUCCESS、REVERT、OUT_OF_ENERGY
+	info  txInfo
+	// 执行中产生的TRC20事件
+	transfers []tokenTransfer
+}
+
+// TRC20 Transfer或Approval事件
+type tokenTransfer struct {
+	token    *token
+	event    string
+	from, to string
+	value    *big.Int
+}
+
+// 交易执行结果(/wallet/gettransactioninfobyid)
+type txInfo struct {
+	ID              string                 `json:"id,omitempty"`
+	Fee             int64                  `json:"fee,omitempty"`
+	BlockNumber     int64                  `json:"blockNumber,omitempty"`
+	BlockTimeStamp  int64                  `json:"blockTimeStamp,omitempty"`
+	ContractResult  []string               `json:"contractResult,omitempty"`
+	ContractAddress string                 `json:"contract_address,omitempty"`
+	Receipt         receipt                `json:"receipt"`
+	Log             []types.TransactionLog `json:"log,omitempty"`
+	Result          string                 `json:"result,omitempty"`
+	ResMessage      string                 `json:"resMessage,omitempty"`
+}
+
+type receipt struct {
+	EnergyUsageTotal int64  `json:"energy_usage_total,omitempty"`
+	EnergyFee        int64  `json:"energy_fee,omitempty"`
+	NetUsage         int64  `json:"net_usage,omitempty"`
+	NetFee           int64  `json:"net_fee,omitempty"`
+	Result           string `json:"result,omitempty"`
+}
+
+// 创建模拟节点，预置开发账户并生成创世区块
+func New(opts Options) (*Node, error) {
+	contract, err := tron.ParseAddress(opts.Token)
+	if err != nil {
+		return nil, fmt.Errorf("模拟TRC20合约地址错误: %v", err)
+	}
+
+	n := &Node{
+		opts:     opts,
+		accounts: make(map[string]*account),
+		tokens:   make(map[string]*token),
+		txs:      make(map[string]*record),
+		history:  make(map[string][]*record),
+	}
+	usdt := newToken(contract, "Tether USD", "USDT", opts.TokenDecimals)
+	n.tokens[contract.Base58()] = usdt
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(opts.TokenDecimals)), nil)
+	for i := 0; i < opts.Accounts; i++ {
+		seed := sha256.Sum256([]byte("tron-api-go simulator " + strconv.Itoa(i)))
+		key, err := tron.PrivateKeyFromBytes(seed[:])
+		if err != nil {
+			return nil, err
+		}
+		addr := key.Address().Base58()
+		n.accounts[addr] = &account{
+			balance: opts.Balance,
+			assets:  map[string]int64{AssetID: opts.TokenBalance * 1000000},
+		}
+		usdt.mint(addr, new(big.Int).Mul(big.NewInt(opts.TokenBalance), unit))
+		n.dev = append(n.dev, DevAccount{Address: addr, PrivateKey: key.Hex()})
+	}
+
+	n.seal(time.Now().UnixMilli(), nil)
+	return n, nil
+}
+
+// 预置的开发账户
+func (n *Node) DevAccounts() []DevAccount {
+	return n.dev
+}
+
+// 按出块间隔在后台出块
+func (n *Node) Start() {
+	if n.opts.BlockInterval <= 0 || n.stop != nil {
+		return
+	}
+	n.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(n.opts.BlockInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.Produce()
+			case <-stop:
+				return
+			}
+		}
+	}(n.stop)
+}
+
+// 停止后台出块
+func (n *Node) Stop() {
+	if n.stop != nil {
+		close(n.stop)
+		n.stop = nil
+	}
+}
+
+// 打包交易池中的交易生成新区块，返回区块高度。执行时校验失败的交易被丢弃
+func (n *Node) Produce() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if head := n.head(); now <= head.timestamp {
+		now = head.timestamp + 1
+	}
+
+	var included []*record
+	for _, r := range n.pending {
+		if r.raw.Expiration < now {
+			delete(n.txs, r.tx.TxID)
+			continue
+		}
+		if err := n.validate(r); err != nil {
+			delete(n.txs, r.tx.TxID)
+			continue
+		}
+		n.execute(r)
+		included = append(included, r)
+	}
+	n.pending = nil
+	return n.seal(now, included).number
+}
+
+// 生成区块，区块ID前8字节为区块高度
+func (n *Node) seal(timestamp int64, txs []*record) *block {
+	b := &block{timestamp: timestamp, txs: txs}
+	if len(n.blocks) > 0 {
+		head := n.head()
+		b.number, b.parent = head.number+1, head.id
+	} else {
+		b.parent = hex.EncodeToString(make([]byte, 32))
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s%d", b.parent, timestamp)
+	for _, r := range txs {
+		h.Write([]byte(r.tx.TxID))
+	}
+	id := h.Sum(nil)
+	binary.BigEndian.PutUint64(id[:8], uint64(b.number))
+	b.id = hex.EncodeToString(id)
+
+	for _, r := range txs {
+		r.block = b
+		r.info.BlockNumber = b.number
+		r.info.BlockTimeStamp = timestamp
+		r.info.ID = r.tx.TxID
+		for _, addr := range r.parties() {
+			n.history[addr] = append(n.history[addr], r)
+		}
+	}
+	n.blocks = append(n.blocks, b)
+	return b
+}
+
+func (n *Node) head() *block {
+	return n.blocks[len(n.blocks)-1]
+}
+
+// 最新固化区块
+func (n *Node) solid() *block {
+	i := len(n.blocks) - 1 - int(n.opts.SolidDepth)
+	if i < 0 {
+		i = 0
+	}
+	return n.blocks[i]
+}
+
+func (n *Node) Name() string {
+	return Name
+}
+
+func (n *Node) Balance(address string) (int64, error) {
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if acc, ok := n.accounts[addr.Base58()]; ok {
+		return acc.balance, nil
+	}
+	return 0, nil
+}
+
+// 按TronGrid /v1/accounts/{address}/transactions 的格式返回账户交易，
+// 支持limit、fingerprint、min_timestamp、max_timestamp及order_by
+func (n *Node) AccountTransactions(address string, query url.Values) (*types.GridTransactionsResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	records, next, err := n.accountRecords(address, query)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.GridTransactionsResponse{Data: []types.GridTransaction{}}
+	for _, r := range records {
+		var tx types.GridTransaction
+		raw, _ := json.Marshal(r.grid())
+		if err := json.Unmarshal(raw, &tx); err != nil {
+			return nil, err
+		}
+		resp.Data = append(resp.Data, tx)
+	}
+	resp.Meta.Fingerprint, resp.Meta.PageSize = next, len(resp.Data)
+	return resp, nil
+}
+
+// 按TronGrid /v1/accounts/{address}/transactions/trc20 的格式返回账户TRC20转账，支持contract_address筛选
+func (n *Node) AccountTrc20Transfers(address string, query url.Values) (*types.GridTrc20Response, error) {
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	contract := query.Get("contract_address")
+
+	n.mu.Lock()
+	var all []types.GridTrc20Transfer
+	for _, r := range n.ordered(addr.Base58(), query) {
+		for _, t := range r.transfers {
+			if t.from != addr.Base58() && t.to != addr.Base58() {
+				continue
+			}
+			if contract != "" && contract != t.token.address.Base58() {
+				continue
+			}
+			var item types.GridTrc20Transfer
+			item.TransactionID = r.tx.TxID
+			item.BlockTimestamp = r.block.timestamp
+			item.From, item.To, item.Type, item.Value = t.from, t.to, t.event, t.value.String()
+			item.TokenInfo.Symbol = t.token.symbol
+			item.TokenInfo.Address = t.token.address.Base58()
+			item.TokenInfo.Decimals = t.token.decimals
+			item.TokenInfo.Name = t.token.name
+			all = append(all, item)
+		}
+	}
+	n.mu.Unlock()
+
+	page, next, err := paginate(len(all), query)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.GridTrc20Response{Data: []types.GridTrc20Transfer{}}
+	resp.Data = append(resp.Data, all[page[0]:page[1]]...)
+	resp.Meta.Fingerprint, resp.Meta.PageSize = next, len(resp.Data)
+	return resp, nil
+}
+
+func (n *Node) accountRecords(address string, query url.Values) ([]*record, string, error) {
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		return nil, "", err
+	}
+	// 与TronGrid一致，TRC20转账的接收方不在交易列表中
+	var all []*record
+	for _, r := range n.ordered(addr.Base58(), query) {
+		if r.involves(addr.Base58()) {
+			all = append(all, r)
+		}
+	}
+	page, next, err := paginate(len(all), query)
+	if err != nil {
+		return nil, "", err
+	}
+	return all[page[0]:page[1]], next, nil
+}
+
+// 账户相关的已上链交易，按时间筛选并排序(默认从新到旧)
+func (n *Node) ordered(address string, query url.Values) []*record {
+	min, _ := strconv.ParseInt(query.Get("min_timestamp"), 10, 64)
+	max, _ := strconv.ParseInt(query.Get("max_timestamp"), 10, 64)
+
+	var list []*record
+	for _, r := range n.history[address] {
+		if (min > 0 && r.block.timestamp < min) || (max > 0 && r.block.timestamp > max) {
+			continue
+		}
+		list = append(list, r)
+	}
+	asc := query.Get("order_by") == "block_timestamp,asc"
+	sort.SliceStable(list, func(i, j int) bool {
+		if asc {
+			return list[i].block.number < list[j].block.number
+		}
+		return list[i].block.number > list[j].block.number
+	})
+	return list
+}
+
+// 分页，fingerprint为下一页的起始序号
+func paginate(total int, query url.Values) ([2]int, string, error) {
+	limit := 20
+	if s := query.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 {
+			return [2]int{}, "", fmt.Errorf("limit参数错误: %s", s)
+		}
+		limit = v
+	}
+	start := 0
+	if s := query.Get("fingerprint"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return [2]int{}, "", fmt.Errorf("fingerprint参数错误: %s", s)
+		}
+		start = v
+	}
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end >= total {
+		return [2]int{start, total}, "", nil
+	}
+	return [2]int{start, end}, strconv.Itoa(end), nil
+}
@@ -0,0 +1,274 @@
+package simulator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"tron-api-go/internal/abi"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// 模拟TRC20合约支持的方法
+var trc20Methods = []*abi.Entry{
+	mustMethod("name() returns (string)", "view"),
+	mustMethod("symbol() returns (string)", "view"),
+	mustMethod("decimals() returns (uint8)", "view"),
+	mustMethod("totalSupply() returns (uint256)", "view"),
+	mustMethod("balanceOf(address who) returns (uint256)", "view"),
+	mustMethod("allowance(address owner, address spender) returns (uint256)", "view"),
+	mustMethod("transfer(address to, uint256 value) returns (bool)", "nonpayable"),
+	mustMethod("transferFrom(address from, address to, uint256 value) returns (bool)", "nonpayable"),
+	mustMethod("approve(address spender, uint256 value) returns (bool)", "nonpayable"),
+	mustMethod("increaseAllowance(address spender, uint256 addedValue) returns (bool)", "nonpayable"),
+	mustMethod("decreaseAllowance(address spender, uint256 subtractedValue) returns (bool)", "nonpayable"),
+}
+
+var (
+	transferEvent = mustEvent("Transfer(address indexed from, address indexed to, uint256 value)")
+	approvalEvent = mustEvent("Approval(address indexed owner, address indexed spender, uint256 value)")
+)
+
+// 按选择器索引的方法
+var trc20Selectors = func() map[string]*abi.Entry {
+	m := make(map[string]*abi.Entry, len(trc20Methods))
+	for _, e := range trc20Methods {
+		m[e.Selector()] = e
+	}
+	return m
+}()
+
+// 执行能量消耗(简化为固定值)
+const (
+	energyRead     = 500
+	energyWrite    = 14650
+	energyNewState = 29650 // 写入新的存储槽(如首次持有代币)
+)
+
+func mustMethod(sig, mutability string) *abi.Entry {
+	e, err := abi.ParseSignature(sig)
+	if err != nil {
+		panic(err)
+	}
+	e.StateMutability = mutability
+	return e
+}
+
+func mustEvent(sig string) *abi.Entry {
+	e, err := abi.ParseSignature(sig)
+	if err != nil {
+		panic(err)
+	}
+	e.Type = "event"
+	for i := range e.Inputs {
+		e.Inputs[i].Indexed = i < 2
+	}
+	return e
+}
+
+// 模拟的TRC20代币合约
+type token struct {
+	address    tron.Address
+	name       string
+	symbol     string
+	decimals   int
+	supply     *big.Int
+	balances   map[string]*big.Int
+	allowances map[string]map[string]*big.Int
+}
+
+func newToken(address tron.Address, name, symbol string, decimals int) *token {
+	return &token{
+		address:    address,
+		name:       name,
+		symbol:     symbol,
+		decimals:   decimals,
+		supply:     new(big.Int),
+		balances:   make(map[string]*big.Int),
+		allowances: make(map[string]map[string]*big.Int),
+	}
+}
+
+// 合约ABI，用于 /wallet/getcontract
+func (t *token) abi() abi.ABI {
+	list := make(abi.ABI, 0, len(trc20Methods)+2)
+	for _, e := range trc20Methods {
+		list = append(list, *e)
+	}
+	return append(list, *transferEvent, *approvalEvent)
+}
+
+func (t *token) mint(to string, value *big.Int) {
+	t.supply.Add(t.supply, value)
+	t.balances[to] = new(big.Int).Add(t.balanceOf(to), value)
+}
+
+func (t *token) balanceOf(address string) *big.Int {
+	if b, ok := t.balances[address]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+
+func (t *token) allowance(owner, spender string) *big.Int {
+	if b, ok := t.allowances[owner][spender]; ok {
+		return b
+	}
+	return new(big.Int)
+}
+
+// 合约执行结果
+type execution struct {
+	result []byte
+	logs   []types.TransactionLog
+	events []tokenTransfer
+	energy int64
+	revert string // 不为空时表示执行回滚
+}
+
+// 执行合约调用，dryRun为true时不修改状态(triggerconstantcontract)
+func (t *token) execute(caller string, data []byte, dryRun bool) (*execution, error) {
+	if len(data) < 4 {
+		return nil, errors.New("缺少函数选择器")
+	}
+	method, ok := trc20Selectors[hex.EncodeToString(data[:4])]
+	if !ok {
+		return &execution{energy: energyRead, revert: "function not found"}, nil
+	}
+	args, err := abi.DecodeValues(method.Inputs, data[4:])
+	if err != nil {
+		return &execution{energy: energyRead, revert: "invalid parameters"}, nil
+	}
+
+	exec := &execution{energy: energyRead}
+	var out interface{}
+	switch method.Name {
+	case "name":
+		out = t.name
+	case "symbol":
+		out = t.symbol
+	case "decimals":
+		out = fmt.Sprint(t.decimals)
+	case "totalSupply":
+		out = t.supply.String()
+	case "balanceOf":
+		out = t.balanceOf(args[0].(string)).String()
+	case "allowance":
+		out = t.allowance(args[0].(string), args[1].(string)).String()
+	case "transfer":
+		exec.revert = t.transfer(exec, caller, args[0].(string), integer(args[1]), dryRun)
+		out = true
+	case "transferFrom":
+		from, to, value := args[0].(string), args[1].(string), integer(args[2])
+		allowed := t.allowance(from, caller)
+		if allowed.Cmp(value) < 0 {
+			exec.revert = "transfer amount exceeds allowance"
+			break
+		}
+		if exec.revert = t.transfer(exec, from, to, value, dryRun); exec.revert == "" {
+			t.approve(exec, from, caller, new(big.Int).Sub(allowed, value), dryRun)
+		}
+		out = true
+	case "approve":
+		t.approve(exec, caller, args[0].(string), integer(args[1]), dryRun)
+		out = true
+	case "increaseAllowance":
+		t.approve(exec, caller, args[0].(string), new(big.Int).Add(t.allowance(caller, args[0].(string)), integer(args[1])), dryRun)
+		out = true
+	case "decreaseAllowance":
+		allowed := t.allowance(caller, args[0].(string))
+		if allowed.Cmp(integer(args[1])) < 0 {
+			exec.revert = "decreased allowance below zero"
+			break
+		}
+		t.approve(exec, caller, args[0].(string), new(big.Int).Sub(allowed, integer(args[1])), dryRun)
+		out = true
+	}
+
+	if exec.revert != "" {
+		exec.logs, exec.events = nil, nil
+		exec.result = revertData(exec.revert)
+		return exec, nil
+	}
+	if exec.result, err = abi.EncodeValues(method.Outputs, []interface{}{out}); err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+func (t *token) transfer(exec *execution, from, to string, value *big.Int, dryRun bool) string {
+	balance := t.balanceOf(from)
+	if balance.Cmp(value) < 0 {
+		return "transfer amount exceeds balance"
+	}
+	if !tron.IsValidAddress(to) {
+		return "transfer to invalid address"
+	}
+
+	exec.energy = energyWrite
+	if t.balanceOf(to).Sign() == 0 {
+		exec.energy = energyNewState
+	}
+	t.emit(exec, transferEvent, from, to, value)
+	if !dryRun {
+		t.balances[from] = new(big.Int).Sub(balance, value)
+		t.balances[to] = new(big.Int).Add(t.balanceOf(to), value)
+	}
+	return ""
+}
+
+func (t *token) approve(exec *execution, owner, spender string, value *big.Int, dryRun bool) {
+	if exec.energy < energyWrite {
+		exec.energy = energyWrite
+	}
+	t.emit(exec, approvalEvent, owner, spender, value)
+	if dryRun {
+		return
+	}
+	if t.allowances[owner] == nil {
+		t.allowances[owner] = make(map[string]*big.Int)
+	}
+	t.allowances[owner][spender] = value
+}
+
+// 记录事件日志(地址为不带41前缀的十六进制)
+func (t *token) emit(exec *execution, event *abi.Entry, a, b string, value *big.Int) {
+	exec.logs = append(exec.logs, types.TransactionLog{
+		Address: hex.EncodeToString(t.address.EVMBytes()),
+		Topics:  []string{event.Topic(), topicAddress(a), topicAddress(b)},
+		Data:    hex.EncodeToString(word(value)),
+	})
+	exec.events = append(exec.events, tokenTransfer{token: t, event: event.Name, from: a, to: b, value: value})
+}
+
+func topicAddress(s string) string {
+	addr, _ := tron.ParseAddress(s)
+	out := make([]byte, 32)
+	copy(out[12:], addr.EVMBytes())
+	return hex.EncodeToString(out)
+}
+
+func word(n *big.Int) []byte {
+	out := make([]byte, 32)
+	n.FillBytes(out)
+	return out
+}
+
+// 解码结果中的整数为十进制字符串
+func integer(v interface{}) *big.Int {
+	s, _ := v.(string)
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
+
+// Error(string)回滚数据
+func revertData(reason string) []byte {
+	enc, _ := abi.EncodeValues([]abi.Argument{{Type: "string"}}, []interface{}{reason})
+	sel, _ := hex.DecodeString("08c379a0")
+	return append(sel, enc...)
+}
@@ -0,0 +1,455 @@
+package simulator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// 链参数(/wallet/getchainparameters)
+var chainParameters = []map[string]interface{}{
+	{"key": "getMaintenanceTimeInterval", "value": 21600000},
+	{"key": "getTransactionFee", "value": 1000},
+	{"key": "getEnergyFee", "value": energyPrice},
+	{"key": "getCreateAccountFee", "value": 100000},
+	{"key": "getCreateNewAccountFeeInSystemContract", "value": 1000000},
+	{"key": "getMemoFee", "value": 1000000},
+	{"key": "getFreeNetLimit", "value": 600},
+}
+
+// /wallet 接口请求中用到的参数
+type request struct {
+	Value            string `json:"value"`
+	Num              int64  `json:"num"`
+	Address          string `json:"address"`
+	OwnerAddress     string `json:"owner_address"`
+	ToAddress        string `json:"to_address"`
+	Amount           int64  `json:"amount"`
+	AssetName        string `json:"asset_name"`
+	ContractAddress  string `json:"contract_address"`
+	FunctionSelector string `json:"function_selector"`
+	Parameter        string `json:"parameter"`
+	Data             string `json:"data"`
+	FeeLimit         int64  `json:"fee_limit"`
+	CallValue        int64  `json:"call_value"`
+	PermissionID     int    `json:"Permission_id"`
+	ExtraData        string `json:"extra_data"`
+	Visible          bool   `json:"visible"`
+}
+
+// 处理 /wallet 接口调用，返回与java-tron相同结构的响应
+func (n *Node) Wallet(path string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	n.mu.Lock()
+	resp, err := n.handle(path, body)
+	n.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析节点响应失败: %v", err)
+	}
+	return nil
+}
+
+func (n *Node) handle(path string, body []byte) (interface{}, error) {
+	switch path {
+	case "/wallet/broadcasttransaction":
+		var tx types.Transaction
+		if err := json.Unmarshal(body, &tx); err != nil {
+			return nil, err
+		}
+		if err := n.broadcast(&tx); err != nil {
+			var berr *broadcastError
+			if !errors.As(err, &berr) {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"result":  false,
+				"code":    berr.code,
+				"txid":    tx.TxID,
+				"message": hex.EncodeToString([]byte(berr.message)),
+			}, nil
+		}
+		return map[string]interface{}{"result": true, "txid": tx.TxID}, nil
+
+	case "/wallet/getsignweight", "/wallet/getapprovedlist":
+		var tx types.Transaction
+		if err := json.Unmarshal(body, &tx); err != nil {
+			return nil, err
+		}
+		return n.signWeight(path, &tx)
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("解析请求失败: %v", err)
+	}
+
+	switch path {
+	case "/wallet/createtransaction", "/wallet/transferasset":
+		return n.buildTransfer(path, &req)
+	case "/wallet/triggersmartcontract":
+		return n.trigger(&req)
+	case "/wallet/triggerconstantcontract":
+		return n.constant(&req)
+	case "/wallet/getaccount":
+		return n.account(&req)
+	case "/wallet/getaccountresource":
+		return map[string]interface{}{"freeNetLimit": 600}, nil
+	case "/wallet/getchainparameters":
+		return map[string]interface{}{"chainParameter": chainParameters}, nil
+	case "/wallet/getcontract":
+		return n.contract(&req)
+	case "/wallet/getassetissuebyid":
+		return n.asset(&req), nil
+	case "/wallet/getnowblock":
+		return n.renderBlock(n.head(), req.Visible), nil
+	case "/walletsolidity/getnowblock":
+		return n.renderBlock(n.solid(), req.Visible), nil
+	case "/wallet/getblockbynum":
+		if req.Num < 0 || req.Num > n.head().number {
+			return map[string]interface{}{}, nil
+		}
+		return n.renderBlock(n.blocks[req.Num], req.Visible), nil
+	case "/wallet/gettransactionbyid":
+		if r, ok := n.txs[req.Value]; ok && r.block != nil {
+			return r.render(req.Visible), nil
+		}
+		return map[string]interface{}{}, nil
+	case "/wallet/gettransactionfrompending":
+		if r, ok := n.txs[req.Value]; ok && r.block == nil {
+			return r.render(req.Visible), nil
+		}
+		return map[string]interface{}{}, nil
+	case "/wallet/gettransactioninfobyid":
+		if r, ok := n.txs[req.Value]; ok && r.block != nil {
+			return r.info, nil
+		}
+		return map[string]interface{}{}, nil
+	case "/walletsolidity/gettransactioninfobyid":
+		if r, ok := n.txs[req.Value]; ok && r.block != nil && r.block.number <= n.solid().number {
+			return r.info, nil
+		}
+		return map[string]interface{}{}, nil
+	case "/wallet/gettransactioninfobyblocknum":
+		infos := []txInfo{}
+		if req.Num >= 0 && req.Num <= n.head().number {
+			for _, r := range n.blocks[req.Num].txs {
+				infos = append(infos, r.info)
+			}
+		}
+		return infos, nil
+	}
+	return nil, fmt.Errorf("模拟节点不支持接口 %s", path)
+}
+
+// 解析请求中的地址(Base58或41开头的十六进制)
+func parseAddress(label, s string) (string, error) {
+	addr, err := tron.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("Invalid %s", label)
+	}
+	return addr.Base58(), nil
+}
+
+// 构建TRX或TRC10转账交易，与节点一样在构建时校验余额
+func (n *Node) buildTransfer(path string, req *request) (interface{}, error) {
+	owner, err := parseAddress("ownerAddress", req.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAddress("toAddress", req.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, value := contractTransfer, contractValue{OwnerAddress: owner, ToAddress: to, Amount: req.Amount}
+	if path == "/wallet/transferasset" {
+		kind, value.AssetName = contractTransferAsset, req.AssetName
+		if b, err := hex.DecodeString(req.AssetName); err == nil && !req.Visible {
+			value.AssetName = string(b)
+		}
+	}
+
+	tx, err := n.build(kind, value, 0, req.PermissionID, req.ExtraData)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.validate(n.pendingRecord(tx)); err != nil {
+		return nil, fmt.Errorf("class org.tron.core.exception.ContractValidateException : %v", err)
+	}
+	return tx, nil
+}
+
+// 由新建的交易构造未广播的记录，用于构建时校验
+func (n *Node) pendingRecord(tx *types.Transaction) *record {
+	r := &record{tx: tx}
+	json.Unmarshal(tx.RawData, &r.raw)
+	return r
+}
+
+// 调用数据：function_selector与parameter拼接，或直接使用data
+func callData(req *request) (string, error) {
+	if req.FunctionSelector == "" {
+		if _, err := hex.DecodeString(req.Data); err != nil || req.Data == "" {
+			return "", errors.New("缺少function_selector或data")
+		}
+		return req.Data, nil
+	}
+	if _, err := hex.DecodeString(req.Parameter); err != nil {
+		return "", errors.New("parameter不是有效的十六进制")
+	}
+	return hex.EncodeToString(tron.Keccak256([]byte(req.FunctionSelector))[:4]) + req.Parameter, nil
+}
+
+// 合约调用失败时的响应
+func contractError(code, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"result": map[string]interface{}{
+			"code":    code,
+			"message": hex.EncodeToString([]byte(message)),
+		},
+	}
+}
+
+// 构建合约调用交易
+func (n *Node) trigger(req *request) (interface{}, error) {
+	owner, err := parseAddress("ownerAddress", req.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := parseAddress("contractAddress", req.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	data, err := callData(req)
+	if err != nil {
+		return contractError("OTHER_ERROR", err.Error()), nil
+	}
+
+	value := contractValue{OwnerAddress: owner, ContractAddress: contract, Data: data, CallValue: req.CallValue}
+	tx, err := n.build(contractTrigger, value, req.FeeLimit, req.PermissionID, req.ExtraData)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.validate(n.pendingRecord(tx)); err != nil {
+		return contractError("CONTRACT_VALIDATE_ERROR", err.Error()), nil
+	}
+	return map[string]interface{}{
+		"result":      map[string]interface{}{"result": true},
+		"transaction": tx,
+	}, nil
+}
+
+// 试运行合约调用，不修改状态
+func (n *Node) constant(req *request) (interface{}, error) {
+	contract, err := parseAddress("contractAddress", req.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := n.tokens[contract]
+	if !ok {
+		return contractError("CONTRACT_VALIDATE_ERROR", "No contract or not a valid smart contract"), nil
+	}
+	data, err := callData(req)
+	if err != nil {
+		return contractError("OTHER_ERROR", err.Error()), nil
+	}
+
+	caller := tron.AddressFromEVM(make([]byte, 20)).Base58()
+	if req.OwnerAddress != "" {
+		if caller, err = parseAddress("ownerAddress", req.OwnerAddress); err != nil {
+			return nil, err
+		}
+	}
+	raw, _ := hex.DecodeString(data)
+	exec, err := tok.execute(caller, raw, true)
+	if err != nil {
+		return contractError("OTHER_ERROR", err.Error()), nil
+	}
+
+	result := map[string]interface{}{"result": true}
+	ret := map[string]interface{}{}
+	if exec.revert != "" {
+		result["message"] = "REVERT opcode executed"
+		ret["ret"] = "FAILED"
+	}
+	return map[string]interface{}{
+		"result":          result,
+		"energy_used":     exec.energy,
+		"constant_result": []string{hex.EncodeToString(exec.result)},
+		"transaction":     map[string]interface{}{"ret": []interface{}{ret}},
+	}, nil
+}
+
+// 账户信息，未激活账户返回空对象
+func (n *Node) account(req *request) (interface{}, error) {
+	address, err := parseAddress("address", req.Address)
+	if err != nil {
+		return nil, err
+	}
+	acc, ok := n.accounts[address]
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+
+	display := address
+	if !req.Visible {
+		display = mustParse(address).Hex()
+	}
+	keys := []types.PermissionKey{{Address: display, Weight: 1}}
+	resp := map[string]interface{}{
+		"address":          display,
+		"create_time":      acc.created,
+		"owner_permission": types.Permission{PermissionName: "owner", Threshold: 1, Keys: keys},
+		"active_permission": []types.Permission{{
+			Type:           "Active",
+			ID:             2,
+			PermissionName: "active",
+			Threshold:      1,
+			Operations:     "7fff1fc0033e0300000000000000000000000000000000000000000000000000",
+			Keys:           keys,
+		}},
+	}
+	if acc.balance > 0 {
+		resp["balance"] = acc.balance
+	}
+	var assets []map[string]interface{}
+	for id, v := range acc.assets {
+		if v > 0 {
+			assets = append(assets, map[string]interface{}{"key": id, "value": v})
+		}
+	}
+	if len(assets) > 0 {
+		resp["assetV2"] = assets
+	}
+	return resp, nil
+}
+
+// 合约信息及ABI
+func (n *Node) contract(req *request) (interface{}, error) {
+	address, err := parseAddress("contractAddress", req.Value)
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := n.tokens[address]
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	display := address
+	if !req.Visible {
+		display = tok.address.Hex()
+	}
+	return map[string]interface{}{
+		"contract_address":              display,
+		"name":                          tok.name,
+		"abi":                           map[string]interface{}{"entrys": tok.abi()},
+		"consume_user_resource_percent": 100,
+	}, nil
+}
+
+// TRC10代币信息
+func (n *Node) asset(req *request) interface{} {
+	if req.Value != AssetID {
+		return map[string]interface{}{}
+	}
+	return types.Trc10Token{
+		ID:           AssetID,
+		Name:         "SimToken",
+		Abbr:         "SIM",
+		Precision:    6,
+		TotalSupply:  int64(n.opts.Accounts) * n.opts.TokenBalance * 1000000,
+		OwnerAddress: n.witness(true),
+		Description:  "模拟链TRC10代币",
+	}
+}
+
+// 签名权重及已签名地址
+func (n *Node) signWeight(path string, tx *types.Transaction) (interface{}, error) {
+	r := &record{tx: tx}
+	var err error
+	if r.raw, err = decodeRaw(tx.RawDataHex); err != nil {
+		return nil, errors.New("交易数据格式错误")
+	}
+	approved, err := signers(tx)
+	if err != nil {
+		return map[string]interface{}{"result": map[string]interface{}{"code": "SIGNATURE_FORMAT_ERROR", "message": err.Error()}}, nil
+	}
+	if path == "/wallet/getapprovedlist" {
+		return map[string]interface{}{"result": map[string]interface{}{"code": "SUCCESS"}, "approved_list": approved}, nil
+	}
+
+	owner := r.value().OwnerAddress
+	weight := int64(0)
+	for _, s := range approved {
+		if s == owner {
+			weight = 1
+		}
+	}
+	code := "NOT_ENOUGH_PERMISSION"
+	if weight >= 1 {
+		code = "ENOUGH_PERMISSION"
+	}
+	perm := types.Permission{PermissionName: "owner", Threshold: 1, Keys: []types.PermissionKey{{Address: owner, Weight: 1}}}
+	if id := r.contract().PermissionID; id > 0 {
+		perm.Type, perm.ID, perm.PermissionName = "Active", id, "active"
+	}
+	return map[string]interface{}{
+		"result":         map[string]interface{}{"code": code},
+		"permission":     perm,
+		"approved_list":  approved,
+		"current_weight": weight,
+	}, nil
+}
+
+// 区块，visible为false时交易中的地址使用十六进制
+func (n *Node) renderBlock(b *block, visible bool) map[string]interface{} {
+	resp := map[string]interface{}{
+		"blockID": b.id,
+		"block_header": map[string]interface{}{
+			"raw_data": map[string]interface{}{
+				"number":          b.number,
+				"timestamp":       b.timestamp,
+				"parentHash":      b.parent,
+				"witness_address": n.witness(visible),
+				"version":         30,
+			},
+		},
+	}
+	if len(b.txs) > 0 {
+		txs := make([]interface{}, len(b.txs))
+		for i, r := range b.txs {
+			txs[i] = r.render(visible)
+		}
+		resp["transactions"] = txs
+	}
+	return resp
+}
+
+// 出块地址(第一个开发账户)
+func (n *Node) witness(visible bool) string {
+	if len(n.dev) == 0 {
+		return ""
+	}
+	if visible {
+		return n.dev[0].Address
+	}
+	return mustParse(n.dev[0].Address).Hex()
+}
@@ -0,0 +1,163 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/blocks"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+const (
+	// 查询新区块的间隔
+	followInterval = time.Second
+	// 内存中缓存的最近区块数，断线重连时优先从缓存补发
+	bufferSize = 200
+	// 最多可从多少个区块之前开始补发(约1小时)
+	MaxResumeBlocks = 1200
+)
+
+// 区块推送中心：有订阅者时跟随新区块，缓存最近的区块并通知等待中的订阅者
+type Hub struct {
+	config   *types.Config
+	decimals *blocks.DecimalsCache
+
+	mu      sync.Mutex
+	blocks  map[int64]*types.Block
+	head    int64 // 已缓存的最高区块
+	changed chan struct{}
+	active  int
+}
+
+// 创建推送中心并启动区块跟随协程
+func NewHub(config *types.Config) *Hub {
+	h := &Hub{
+		config:   config,
+		decimals: blocks.NewDecimalsCache(config),
+		blocks:   make(map[int64]*types.Block),
+		changed:  make(chan struct{}),
+	}
+	go h.follow()
+	return h
+}
+
+// 登记订阅者，返回的函数用于取消登记。没有订阅者时不查询节点
+func (h *Hub) Acquire() func() {
+	h.mu.Lock()
+	h.active++
+	h.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			h.active--
+			if h.active == 0 {
+				// 不再跟随新区块，丢弃缓存，下次有订阅者时从最新区块重新开始
+				h.head = 0
+				h.blocks = make(map[int64]*types.Block)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// 最新区块号。只有在跟随新区块(有订阅者)时缓存才是最新的，否则向节点查询
+func (h *Hub) Latest() (int64, error) {
+	h.mu.Lock()
+	head, active := h.head, h.active
+	h.mu.Unlock()
+	if head > 0 && active > 0 {
+		return head, nil
+	}
+	return utils.GetNowBlockNumber(h.config, false)
+}
+
+// 获取指定区块：已缓存时直接返回；早于缓存时向节点查询；尚未产生时等待
+func (h *Hub) Block(ctx context.Context, num int64) (*types.Block, error) {
+	for {
+		h.mu.Lock()
+		if block, ok := h.blocks[num]; ok {
+			h.mu.Unlock()
+			return block, nil
+		}
+		if h.head > 0 && num <= h.head {
+			h.mu.Unlock()
+			return h.fetch(num)
+		}
+		wait := h.changed
+		h.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// 向节点查询区块并填充转账金额
+func (h *Hub) fetch(num int64) (*types.Block, error) {
+	block, err := blocks.Fetch(h.config, num)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("区块%d不存在", num)
+	}
+	for i := range block.Transfers {
+		h.decimals.Fill(&block.Transfers[i])
+	}
+	return block, nil
+}
+
+func (h *Hub) follow() {
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.mu.Lock()
+		active := h.active
+		h.mu.Unlock()
+		if active == 0 {
+			continue
+		}
+
+		// 查询失败时下一轮重试，等待中的订阅者不受影响
+		h.round()
+	}
+}
+
+// 缓存新产生的区块。落后超过缓存大小时直接从最新区块开始，更早的区块由订阅者按需查询
+func (h *Hub) round() error {
+	latest, err := utils.GetNowBlockNumber(h.config, false)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	if h.head == 0 || latest-h.head > bufferSize {
+		h.head = latest - 1
+		h.blocks = make(map[int64]*types.Block)
+	}
+	next := h.head + 1
+	h.mu.Unlock()
+
+	for ; next <= latest; next++ {
+		block, err := h.fetch(next)
+		if err != nil {
+			return err
+		}
+
+		h.mu.Lock()
+		h.blocks[next] = block
+		delete(h.blocks, next-bufferSize)
+		h.head = next
+		close(h.changed)
+		h.changed = make(chan struct{})
+		h.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,558 @@
+package sweep
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/confirm"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 地址处理动作
+const (
+	actionSweep = "sweep"
+	actionSkip  = "skip"
+)
+
+// 地址状态
+const (
+	addressPending = "pending"
+	addressPlanned = "planned"
+	addressDone    = "done"
+	addressSkipped = "skipped"
+	addressFailed  = "failed"
+)
+
+// 步骤状态
+const (
+	stepPlanned   = "planned"
+	stepBroadcast = "broadcast"
+	stepSuccess   = "success"
+	stepFailed    = "failed"
+	stepExpired   = "expired"
+)
+
+// 步骤名称
+const (
+	stepTopup       = "topup"
+	stepDelegate    = "delegate"
+	stepTransfer    = "transfer"
+	stepUndelegate  = "undelegate"
+	stepTrxTransfer = "trxTransfer"
+)
+
+// 扫描阶段每处理多少个地址保存一次进度
+const scanSaveInterval = 50
+
+// 等待交易上链的轮询间隔和次数
+const (
+	confirmInterval = 3 * time.Second
+	confirmAttempts = 30
+)
+
+var errStopped = errors.New("任务已暂停")
+
+// 任务私钥，仅保存在内存中
+type secrets struct {
+	deriver *tron.MnemonicDeriver
+	keys    []*tron.PrivateKey
+	feeKey  *tron.PrivateKey
+}
+
+// 解析请求中的私钥，并校验与任务参数一致
+func newSecrets(req *types.SweepRequest, settings *types.SweepSettings) (*secrets, error) {
+	sec := &secrets{}
+
+	switch settings.Source {
+	case "mnemonic":
+		if req.Mnemonic == "" {
+			return nil, errors.New("需要提供助记词")
+		}
+		d, err := tron.NewMnemonicDeriver(req.Mnemonic)
+		if err != nil {
+			return nil, err
+		}
+		sec.deriver = d
+	case "keys":
+		if len(req.Keys) != settings.Num {
+			return nil, fmt.Errorf("需要提供%d个私钥", settings.Num)
+		}
+		for i, k := range req.Keys {
+			key, err := tron.ParsePrivateKey(k)
+			if err != nil {
+				return nil, fmt.Errorf("第%d个私钥无效: %v", i+1, err)
+			}
+			sec.keys = append(sec.keys, key)
+		}
+	}
+
+	if req.FeeKey != "" {
+		feeKey, err := tron.ParsePrivateKey(req.FeeKey)
+		if err != nil {
+			return nil, fmt.Errorf("feeKey无效: %v", err)
+		}
+		if settings.FeeAddress != "" && feeKey.Address().Base58() != settings.FeeAddress {
+			return nil, errors.New("feeKey与任务创建时的手续费钱包不一致")
+		}
+		sec.feeKey = feeKey
+	} else if settings.FeeMode != FeeModeNone && !settings.DryRun {
+		return nil, errors.New("需要提供手续费钱包私钥feeKey")
+	}
+
+	return sec, nil
+}
+
+// 获取第i个充值地址的私钥
+func (s *secrets) key(settings *types.SweepSettings, i int) (*tron.PrivateKey, error) {
+	if s.deriver != nil {
+		return s.deriver.Derive(uint32(settings.Offset + i))
+	}
+	if i < 0 || i >= len(s.keys) {
+		return nil, errors.New("地址索引超出范围")
+	}
+	return s.keys[i], nil
+}
+
+// 任务执行器
+type runner struct {
+	m    *Manager
+	job  *types.SweepJob
+	sec  *secrets
+	stop chan struct{}
+}
+
+func (r *runner) stopped() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// 等待一段时间，任务暂停时提前返回
+func (r *runner) sleep(d time.Duration) error {
+	select {
+	case <-r.stop:
+		return errStopped
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (r *runner) run() error {
+	if err := r.prepare(); err != nil {
+		return err
+	}
+	if err := r.scan(); err != nil {
+		return err
+	}
+	if r.job.Settings.DryRun {
+		r.plan()
+		return nil
+	}
+	return r.execute()
+}
+
+// 生成充值地址列表，恢复任务时校验私钥与地址一致
+func (r *runner) prepare() error {
+	settings := &r.job.Settings
+
+	if len(r.job.Addresses) > 0 {
+		for _, a := range r.job.Addresses {
+			key, err := r.sec.key(settings, a.Index)
+			if err != nil {
+				return err
+			}
+			if key.Address().Base58() != a.Address {
+				return fmt.Errorf("私钥与任务中的地址 %s 不一致", a.Address)
+			}
+		}
+		return nil
+	}
+
+	addresses := make([]types.SweepAddress, 0, settings.Num)
+	for i := 0; i < settings.Num; i++ {
+		if r.stopped() {
+			return errStopped
+		}
+		key, err := r.sec.key(settings, i)
+		if err != nil {
+			return err
+		}
+		addresses = append(addresses, types.SweepAddress{
+			Index:   i,
+			Address: key.Address().Base58(),
+			Status:  addressPending,
+		})
+	}
+
+	r.m.update(func() { r.job.Addresses = addresses })
+	r.m.save(r.job)
+	return nil
+}
+
+// 扫描余额并决定每个地址是否需要归集
+func (r *runner) scan() error {
+	settings := &r.job.Settings
+	threshold := thresholdUnits(settings)
+
+	scanned := 0
+	for i := range r.job.Addresses {
+		a := &r.job.Addresses[i]
+		if a.Scanned {
+			continue
+		}
+		if r.stopped() {
+			r.m.save(r.job)
+			return errStopped
+		}
+
+		trx, token, err := r.balances(a.Address)
+		if err != nil {
+			r.m.update(func() {
+				a.Scanned = true
+				a.Status = addressFailed
+				a.Reason = "查询余额失败: " + err.Error()
+			})
+			continue
+		}
+
+		r.m.update(func() {
+			a.Scanned = true
+			a.TrxBalance = amount.Sun(trx).String()
+			a.TokenBalance = amount.New(token, settings.Decimals).String()
+
+			switch {
+			case token.Sign() > 0 && token.Cmp(threshold) >= 0:
+				a.Action = actionSweep
+			case settings.SweepTrx && trx > settings.TrxReserve && trx >= settings.TrxThreshold && trx > 0:
+				a.Action = actionSweep
+			default:
+				a.Action = actionSkip
+				a.Status = addressSkipped
+				a.Reason = "余额未达到归集阈值"
+			}
+		})
+
+		scanned++
+		if scanned%scanSaveInterval == 0 {
+			r.m.save(r.job)
+		}
+	}
+
+	r.m.save(r.job)
+	return nil
+}
+
+// 归集阈值(代币最小单位)
+func thresholdUnits(settings *types.SweepSettings) *big.Int {
+	threshold, _ := amount.Parse(settings.TokenThreshold, settings.Decimals)
+	return threshold.Units()
+}
+
+// 查询地址的TRX余额(SUN)和代币余额(最小单位)
+func (r *runner) balances(address string) (int64, *big.Int, error) {
+	trx, err := utils.GetAccountBalance(r.m.config, address)
+	if err != nil {
+		return 0, nil, err
+	}
+	token, err := utils.GetTrc20BalanceRaw(r.m.config, address, r.job.Settings.Contract)
+	if err != nil {
+		return 0, nil, err
+	}
+	return trx, token, nil
+}
+
+// 预览模式：根据扫描结果规划每个地址的步骤
+func (r *runner) plan() {
+	settings := &r.job.Settings
+	threshold := thresholdUnits(settings)
+
+	r.m.update(func() {
+		for i := range r.job.Addresses {
+			a := &r.job.Addresses[i]
+			if a.Action != actionSweep {
+				continue
+			}
+
+			now := time.Now().Unix()
+			trx, _ := amount.ParseTrx(a.TrxBalance)
+			token, _ := amount.Parse(a.TokenBalance, settings.Decimals)
+
+			var steps []types.SweepStep
+			if token.Sign() > 0 && token.Units().Cmp(threshold) >= 0 {
+				switch settings.FeeMode {
+				case FeeModeTopup:
+					if sun, _ := trx.Int64(); sun < settings.TopupAmount {
+						steps = append(steps, types.SweepStep{Name: stepTopup, Amount: amount.Sun(settings.TopupAmount - sun).String(), Status: stepPlanned, Time: now})
+					}
+				case FeeModeDelegate:
+					steps = append(steps, types.SweepStep{Name: stepDelegate, Amount: amount.Sun(settings.DelegateAmount).String(), Status: stepPlanned, Time: now})
+				}
+				steps = append(steps, types.SweepStep{Name: stepTransfer, Amount: a.TokenBalance, Status: stepPlanned, Time: now})
+				if settings.FeeMode == FeeModeDelegate {
+					steps = append(steps, types.SweepStep{Name: stepUndelegate, Amount: amount.Sun(settings.DelegateAmount).String(), Status: stepPlanned, Time: now})
+				}
+			}
+			if settings.SweepTrx {
+				steps = append(steps, types.SweepStep{Name: stepTrxTransfer, Status: stepPlanned, Time: now})
+			}
+
+			a.Steps = steps
+			a.Status = addressPlanned
+		}
+	})
+	r.m.save(r.job)
+}
+
+// 逐个地址执行归集
+func (r *runner) execute() error {
+	for i := range r.job.Addresses {
+		a := &r.job.Addresses[i]
+		if a.Action != actionSweep || a.Status == addressDone || a.Status == addressFailed {
+			continue
+		}
+		if r.stopped() {
+			return errStopped
+		}
+
+		err := r.sweepAddress(a)
+		if err == errStopped {
+			r.m.save(r.job)
+			return err
+		}
+
+		r.m.update(func() {
+			if err != nil {
+				a.Status = addressFailed
+				a.Reason = err.Error()
+			} else {
+				a.Status = addressDone
+				a.Reason = ""
+			}
+		})
+		r.m.save(r.job)
+	}
+	return nil
+}
+
+// 归集单个地址
+func (r *runner) sweepAddress(a *types.SweepAddress) error {
+	settings := &r.job.Settings
+	cfg := r.m.config
+
+	key, err := r.sec.key(settings, a.Index)
+	if err != nil {
+		return err
+	}
+
+	trx, token, err := r.balances(a.Address)
+	if err != nil {
+		return fmt.Errorf("查询余额失败: %v", err)
+	}
+	threshold := thresholdUnits(settings)
+
+	// 代币已转出(例如任务恢复时)则不会再次转账。
+	// 各步骤在构建交易时重新查询余额，上次交易过期后按当前余额重建，不沿用过期交易的金额
+	if token.Sign() > 0 && token.Cmp(threshold) >= 0 || r.hasPendingStep(a, stepTransfer) {
+		switch settings.FeeMode {
+		case FeeModeTopup:
+			if trx < settings.TopupAmount || r.hasPendingStep(a, stepTopup) {
+				err := r.runStep(a, stepTopup, r.sec.feeKey, func() (*types.Transaction, string, error) {
+					balance, err := utils.GetAccountBalance(cfg, a.Address)
+					if err != nil {
+						return nil, "", fmt.Errorf("查询TRX余额失败: %v", err)
+					}
+					// 上次充值过期未上链而余额已足够时无需再充值
+					topup := settings.TopupAmount - balance
+					if topup <= 0 {
+						return nil, "", nil
+					}
+					tx, err := utils.CreateTrxTransaction(cfg, r.sec.feeKey.Address().Base58(), a.Address, topup, 0)
+					return tx, amount.Sun(topup).String(), err
+				})
+				if err != nil {
+					return err
+				}
+			}
+		case FeeModeDelegate:
+			err := r.runStep(a, stepDelegate, r.sec.feeKey, func() (*types.Transaction, string, error) {
+				tx, err := utils.DelegateResource(cfg, r.sec.feeKey.Address().Base58(), a.Address, settings.DelegateAmount, utils.ResourceEnergy, 0, 0)
+				return tx, amount.Sun(settings.DelegateAmount).String(), err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err := r.runStep(a, stepTransfer, key, func() (*types.Transaction, string, error) {
+			token, err := utils.GetTrc20BalanceRaw(cfg, a.Address, settings.Contract)
+			if err != nil {
+				return nil, "", fmt.Errorf("查询代币余额失败: %v", err)
+			}
+			if token.Sign() <= 0 {
+				return nil, "", nil
+			}
+			target, _ := tron.ParseAddress(settings.Target)
+			parameter, err := tron.EncodeTransferParams(target, token)
+			if err != nil {
+				return nil, "", err
+			}
+			tx, err := utils.TriggerSmartContract(cfg, a.Address, settings.Contract, "transfer(address,uint256)", parameter, 0, 0)
+			return tx, amount.New(token, settings.Decimals).String(), err
+		})
+
+		// 无论转账是否成功，都收回代理的能量
+		if settings.FeeMode == FeeModeDelegate && r.stepSucceeded(a, stepDelegate) {
+			undelegateErr := r.runStep(a, stepUndelegate, r.sec.feeKey, func() (*types.Transaction, string, error) {
+				tx, err := utils.UnDelegateResource(cfg, r.sec.feeKey.Address().Base58(), a.Address, settings.DelegateAmount, utils.ResourceEnergy, 0)
+				return tx, amount.Sun(settings.DelegateAmount).String(), err
+			})
+			if err == nil && undelegateErr == errStopped {
+				return undelegateErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if settings.SweepTrx {
+		err := r.runStep(a, stepTrxTransfer, key, func() (*types.Transaction, string, error) {
+			balance, err := utils.GetAccountBalance(cfg, a.Address)
+			if err != nil {
+				return nil, "", fmt.Errorf("查询TRX余额失败: %v", err)
+			}
+			if balance < settings.TrxThreshold || balance <= settings.TrxReserve {
+				return nil, "", nil
+			}
+			sun := balance - settings.TrxReserve
+			tx, err := utils.CreateTrxTransaction(cfg, a.Address, settings.Target, sun, 0)
+			return tx, amount.Sun(sun).String(), err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 查找地址最近一次的指定步骤
+func lastStep(a *types.SweepAddress, name string) *types.SweepStep {
+	for i := len(a.Steps) - 1; i >= 0; i-- {
+		if a.Steps[i].Name == name {
+			return &a.Steps[i]
+		}
+	}
+	return nil
+}
+
+// 步骤已广播但尚未确认(任务中断后恢复的情况)
+func (r *runner) hasPendingStep(a *types.SweepAddress, name string) bool {
+	step := lastStep(a, name)
+	return step != nil && step.Status == stepBroadcast
+}
+
+// 步骤已成功
+func (r *runner) stepSucceeded(a *types.SweepAddress, name string) bool {
+	step := lastStep(a, name)
+	return step != nil && step.Status == stepSuccess
+}
+
+// 执行一个交易步骤：已成功则跳过，已广播则等待确认，否则构建、签名、记录并广播。
+// build返回交易及其金额，返回nil交易时表示无需执行该步骤
+func (r *runner) runStep(a *types.SweepAddress, name string, key *tron.PrivateKey, build func() (*types.Transaction, string, error)) error {
+	if step := lastStep(a, name); step != nil {
+		switch step.Status {
+		case stepSuccess:
+			return nil
+		case stepBroadcast:
+			err := r.confirm(a, step)
+			if err != errExpired {
+				return err
+			}
+		}
+	}
+
+	tx, value, err := build()
+	if err != nil {
+		return fmt.Errorf("%s: 创建交易失败: %v", name, err)
+	}
+	if tx == nil {
+		return nil
+	}
+	if err := utils.SignTransaction(tx, key); err != nil {
+		return fmt.Errorf("%s: 签名失败: %v", name, err)
+	}
+
+	// 广播前先记录交易ID，服务中断后可据此确认交易状态，避免重复发送
+	var step *types.SweepStep
+	r.m.update(func() {
+		a.Steps = append(a.Steps, types.SweepStep{
+			Name:       name,
+			TxID:       tx.TxID,
+			Amount:     value,
+			Status:     stepBroadcast,
+			Time:       time.Now().Unix(),
+			Expiration: utils.TransactionExpiration(tx),
+		})
+		step = &a.Steps[len(a.Steps)-1]
+	})
+	r.m.save(r.job)
+
+	// 只有节点明确拒绝时才判定失败，超时等错误时交易可能已被接收，按交易ID等待确认
+	if result, err := utils.BroadcastTransaction(r.m.config, tx); err != nil && utils.BroadcastRejected(result) {
+		r.m.update(func() {
+			step.Status = stepFailed
+			step.Error = err.Error()
+		})
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	return r.confirm(a, step)
+}
+
+var errExpired = errors.New("交易已过期")
+
+// 等待交易上链并记录结果
+func (r *runner) confirm(a *types.SweepAddress, step *types.SweepStep) error {
+	for i := 0; i < confirmAttempts; i++ {
+		info, err := utils.GetTransactionInfo(r.m.config, step.TxID)
+		if err == nil && info != nil {
+			failed := info.Result == "FAILED" || (info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS")
+			r.m.update(func() {
+				if failed {
+					step.Status = stepFailed
+					step.Error = info.Receipt.Result + " " + info.ResMessage
+				} else {
+					step.Status = stepSuccess
+				}
+			})
+			r.m.save(r.job)
+			if failed {
+				return fmt.Errorf("%s: 交易执行失败: %s", step.Name, step.Error)
+			}
+			return nil
+		}
+
+		// 超过交易的过期时间且节点确认链上不存在该交易时才视为过期，查询失败时继续等待，避免重新构建导致重复发送
+		if err == nil && confirm.Expired(step.Expiration) {
+			r.m.update(func() { step.Status = stepExpired })
+			r.m.save(r.job)
+			return errExpired
+		}
+
+		if err := r.sleep(confirmInterval); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%s: 等待交易确认超时", step.Name)
+}
@@ -0,0 +1,431 @@
+package sweep
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 任务状态
+const (
+	StatusRunning   = "running"
+	StatusPaused    = "paused"
+	StatusPlanned   = "planned"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// 手续费方式
+const (
+	FeeModeNone     = "none"
+	FeeModeTopup    = "topup"
+	FeeModeDelegate = "delegate"
+)
+
+// 单个任务最多归集的地址数量
+const maxAddresses = 20000
+
+// 默认每个地址补充的TRX(足够支付一次USDT转账的能量费用)
+const defaultTopupAmount = "30"
+
+// 归集任务管理器
+type Manager struct {
+	config *types.Config
+	dir    string
+
+	mu    sync.Mutex
+	jobs  map[string]*types.SweepJob
+	stops map[string]chan struct{}
+}
+
+// 创建归集任务管理器，并加载已保存的任务
+func NewManager(config *types.Config) *Manager {
+	m := &Manager{
+		config: config,
+		dir:    filepath.Join(config.DataDir, "sweep"),
+		jobs:   make(map[string]*types.SweepJob),
+		stops:  make(map[string]chan struct{}),
+	}
+	m.load()
+	return m
+}
+
+// 加载磁盘上的任务，服务重启前未完成的任务标记为暂停
+func (m *Manager) load() {
+	files, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		var job types.SweepJob
+		if err := utils.ReadJSONFile(file, &job); err != nil || job.ID == "" {
+			continue
+		}
+		if job.Status == StatusRunning {
+			job.Status = StatusPaused
+			job.Error = "服务重启，任务已暂停，请重新提交私钥后继续"
+		}
+		m.jobs[job.ID] = &job
+	}
+}
+
+// 保存任务，调用方需持有锁
+func (m *Manager) saveLocked(job *types.SweepJob) error {
+	job.Summary = summarize(job)
+	job.UpdatedAt = time.Now().Unix()
+	return utils.WriteJSONFile(filepath.Join(m.dir, job.ID+".json"), job)
+}
+
+// 保存任务
+func (m *Manager) save(job *types.SweepJob) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.saveLocked(job); err != nil {
+		fmt.Printf("⚠️  保存归集任务 %s 失败: %v\n", job.ID, err)
+	}
+}
+
+// 在锁内修改任务
+func (m *Manager) update(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn()
+}
+
+// 统计任务进度
+func summarize(job *types.SweepJob) types.SweepSummary {
+	summary := types.SweepSummary{Total: len(job.Addresses)}
+	if job.Settings.Num > summary.Total {
+		summary.Total = job.Settings.Num
+	}
+
+	tokenTotal := new(big.Int)
+	for _, a := range job.Addresses {
+		if a.Scanned {
+			summary.Scanned++
+		}
+		if a.Action == actionSweep {
+			summary.ToSweep++
+			if b, err := amount.Parse(a.TokenBalance, job.Settings.Decimals); err == nil {
+				tokenTotal.Add(tokenTotal, b.Units())
+			}
+		}
+		switch a.Status {
+		case addressDone:
+			summary.Done++
+		case addressSkipped:
+			summary.Skipped++
+		case addressFailed:
+			summary.Failed++
+		}
+	}
+	summary.TokenTotal = amount.New(tokenTotal, job.Settings.Decimals).String()
+	return summary
+}
+
+// 深拷贝任务，避免读取时与执行中的任务产生数据竞争
+func (m *Manager) snapshot(job *types.SweepJob, withAddresses bool) *types.SweepJob {
+	data, _ := json.Marshal(job)
+	var out types.SweepJob
+	json.Unmarshal(data, &out)
+	if !withAddresses {
+		out.Addresses = nil
+	}
+	return &out
+}
+
+// 查询任务详情
+func (m *Manager) Get(id string) (*types.SweepJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, errors.New("归集任务不存在")
+	}
+	return m.snapshot(job, true), nil
+}
+
+// 查询任务列表(不含地址明细)
+func (m *Manager) List() []*types.SweepJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]*types.SweepJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		list = append(list, m.snapshot(job, false))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt > list[j].CreatedAt
+	})
+	return list
+}
+
+// 创建并启动归集任务
+func (m *Manager) Create(req *types.SweepRequest) (*types.SweepJob, error) {
+	settings, err := m.buildSettings(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sec, err := newSecrets(req, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &types.SweepJob{
+		ID:        newJobID(),
+		Status:    StatusRunning,
+		Settings:  *settings,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	err = m.saveLocked(job)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("保存归集任务失败: %v", err)
+	}
+
+	m.start(job, sec)
+	return m.snapshot(job, false), nil
+}
+
+// 暂停任务，当前地址处理完成后停止
+func (m *Manager) Pause(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return errors.New("归集任务不存在")
+	}
+	stop, running := m.stops[id]
+	if !running {
+		return errors.New("任务未在运行")
+	}
+	select {
+	case <-stop:
+		return errors.New("任务正在暂停")
+	default:
+		close(stop)
+	}
+	job.Error = "任务暂停中，当前地址处理完成后停止"
+	return m.saveLocked(job)
+}
+
+// 继续执行暂停或失败的任务，需要重新提供私钥
+func (m *Manager) Resume(id string, req *types.SweepRequest) (*types.SweepJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, errors.New("归集任务不存在")
+	}
+	if _, running := m.stops[id]; running {
+		m.mu.Unlock()
+		return nil, errors.New("任务正在运行或暂停中")
+	}
+	if job.Status == StatusCompleted || job.Status == StatusPlanned {
+		m.mu.Unlock()
+		return nil, errors.New("任务已结束，无法继续")
+	}
+	settings := job.Settings
+	m.mu.Unlock()
+
+	sec, err := newSecrets(req, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	job.Status = StatusRunning
+	job.Error = ""
+	// 失败的地址重新尝试
+	for i := range job.Addresses {
+		if job.Addresses[i].Status == addressFailed {
+			job.Addresses[i].Status = addressPending
+			job.Addresses[i].Reason = ""
+		}
+	}
+	err = m.saveLocked(job)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("保存归集任务失败: %v", err)
+	}
+
+	m.start(job, sec)
+	return m.snapshot(job, false), nil
+}
+
+// 在后台协程中执行任务
+func (m *Manager) start(job *types.SweepJob, sec *secrets) {
+	stop := make(chan struct{})
+	m.mu.Lock()
+	m.stops[job.ID] = stop
+	m.mu.Unlock()
+
+	r := &runner{m: m, job: job, sec: sec, stop: stop}
+	go func() {
+		err := r.run()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.stops[job.ID] == stop {
+			delete(m.stops, job.ID)
+		}
+		job.Error = ""
+		switch {
+		case err == errStopped:
+			job.Status = StatusPaused
+		case err != nil:
+			job.Status = StatusFailed
+			job.Error = err.Error()
+		case job.Settings.DryRun:
+			job.Status = StatusPlanned
+		default:
+			job.Status = StatusCompleted
+		}
+		m.saveLocked(job)
+	}()
+}
+
+// 校验请求并生成任务参数
+func (m *Manager) buildSettings(req *types.SweepRequest) (*types.SweepSettings, error) {
+	settings := &types.SweepSettings{
+		SweepTrx: req.SweepTrx,
+		DryRun:   req.DryRun,
+	}
+
+	switch {
+	case req.Mnemonic != "":
+		if req.Num <= 0 || req.Num > maxAddresses {
+			return nil, fmt.Errorf("地址数量必须为1-%d", maxAddresses)
+		}
+		if req.Offset < 0 {
+			return nil, errors.New("起始索引不能为负数")
+		}
+		settings.Source = "mnemonic"
+		settings.Offset = req.Offset
+		settings.Num = req.Num
+	case len(req.Keys) > 0:
+		if len(req.Keys) > maxAddresses {
+			return nil, fmt.Errorf("地址数量不能超过%d", maxAddresses)
+		}
+		settings.Source = "keys"
+		settings.Num = len(req.Keys)
+	default:
+		return nil, errors.New("需要提供助记词或私钥列表")
+	}
+
+	target, err := tron.ParseAddress(req.Target)
+	if err != nil {
+		return nil, errors.New("归集目标地址格式错误")
+	}
+	settings.Target = target.Base58()
+
+	contract := req.Contract
+	if contract == "" {
+		contract = m.config.ContractAddress
+	}
+	contractAddr, err := tron.ParseAddress(contract)
+	if err != nil {
+		return nil, errors.New("合约地址格式错误")
+	}
+	settings.Contract = contractAddr.Base58()
+
+	if settings.Decimals, err = utils.GetTokenDecimals(m.config, settings.Contract); err != nil {
+		return nil, err
+	}
+
+	threshold := req.TokenThreshold
+	if threshold == "" {
+		threshold = "0"
+	}
+	thresholdAmount, err := amount.Parse(threshold, settings.Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("tokenThreshold无效: %v", err)
+	}
+	settings.TokenThreshold = thresholdAmount.String()
+
+	if req.SweepTrx {
+		if settings.TrxThreshold, err = parseSun(req.TrxThreshold, "0"); err != nil {
+			return nil, fmt.Errorf("trxThreshold无效: %v", err)
+		}
+		if settings.TrxReserve, err = parseSun(req.TrxReserve, "0"); err != nil {
+			return nil, fmt.Errorf("trxReserve无效: %v", err)
+		}
+	}
+
+	settings.FeeMode = strings.ToLower(req.FeeMode)
+	switch settings.FeeMode {
+	case "", FeeModeNone:
+		settings.FeeMode = FeeModeNone
+	case FeeModeTopup:
+		if settings.TopupAmount, err = parseSun(req.TopupAmount, defaultTopupAmount); err != nil {
+			return nil, fmt.Errorf("topupAmount无效: %v", err)
+		}
+	case FeeModeDelegate:
+		if req.DelegateAmount == "" {
+			return nil, errors.New("delegate模式需要提供delegateAmount")
+		}
+		if settings.DelegateAmount, err = parseSun(req.DelegateAmount, ""); err != nil {
+			return nil, fmt.Errorf("delegateAmount无效: %v", err)
+		}
+	default:
+		return nil, errors.New("feeMode必须为topup、delegate或none")
+	}
+
+	if settings.FeeMode != FeeModeNone {
+		if req.FeeKey == "" {
+			if !req.DryRun {
+				return nil, errors.New("需要提供手续费钱包私钥feeKey")
+			}
+		} else {
+			feeKey, err := tron.ParsePrivateKey(req.FeeKey)
+			if err != nil {
+				return nil, fmt.Errorf("feeKey无效: %v", err)
+			}
+			settings.FeeAddress = feeKey.Address().Base58()
+		}
+	}
+
+	return settings, nil
+}
+
+// 解析TRX金额为SUN
+func parseSun(s, def string) (int64, error) {
+	if s == "" {
+		s = def
+	}
+	v, err := amount.ParseTrx(s)
+	if err != nil {
+		return 0, err
+	}
+	sun, ok := v.Int64()
+	if !ok {
+		return 0, errors.New("金额过大")
+	}
+	return sun, nil
+}
+
+func newJobID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("sweep_%s_%s", time.Now().Format("20060102150405"), hex.EncodeToString(b))
+}
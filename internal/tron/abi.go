@@ -0,0 +1,33 @@
+package tron
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+)
+
+// ABI编码地址参数(32字节，左侧补零)
+func EncodeAddressParam(addr Address) []byte {
+	out := make([]byte, 32)
+	copy(out[12:], addr.EVMBytes())
+	return out
+}
+
+// ABI编码uint256参数
+func EncodeUintParam(v *big.Int) ([]byte, error) {
+	if v.Sign() < 0 || v.BitLen() > 256 {
+		return nil, errors.New("数值超出uint256范围")
+	}
+	out := make([]byte, 32)
+	v.FillBytes(out)
+	return out, nil
+}
+
+// 编码TRC20 transfer(address,uint256)参数
+func EncodeTransferParams(to Address, amount *big.Int) (string, error) {
+	value, err := EncodeUintParam(amount)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(append(EncodeAddressParam(to), value...)), nil
+}
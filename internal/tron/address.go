@@ -0,0 +1,162 @@
+package tron
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// TRON地址前缀(主网)
+const AddressPrefix = 0x41
+
+// 地址长度：1字节前缀 + 20字节哈希
+const AddressLength = 21
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// TRON地址
+type Address [AddressLength]byte
+
+// Base58Check格式地址(T开头)
+func (a Address) Base58() string {
+	return base58CheckEncode(a[:])
+}
+
+// 十六进制格式地址(41开头)
+func (a Address) Hex() string {
+	return hex.EncodeToString(a[:])
+}
+
+// EVM格式的20字节地址
+func (a Address) EVMBytes() []byte {
+	return a[1:]
+}
+
+func (a Address) String() string {
+	return a.Base58()
+}
+
+// 解析Base58或41开头的十六进制地址
+func ParseAddress(s string) (Address, error) {
+	var addr Address
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return addr, errors.New("地址不能为空")
+	}
+
+	var raw []byte
+	if len(s) == 42 && strings.HasPrefix(s, "41") {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return addr, errors.New("十六进制地址格式错误")
+		}
+		raw = b
+	} else {
+		b, err := base58CheckDecode(s)
+		if err != nil {
+			return addr, err
+		}
+		raw = b
+	}
+
+	if len(raw) != AddressLength || raw[0] != AddressPrefix {
+		return addr, errors.New("地址格式错误")
+	}
+
+	copy(addr[:], raw)
+	return addr, nil
+}
+
+// 由20字节EVM地址构造TRON地址
+func AddressFromEVM(b []byte) Address {
+	var addr Address
+	addr[0] = AddressPrefix
+	if len(b) > 20 {
+		b = b[len(b)-20:]
+	}
+	copy(addr[AddressLength-len(b):], b)
+	return addr
+}
+
+// 检查地址是否合法
+func IsValidAddress(s string) bool {
+	_, err := ParseAddress(s)
+	return err == nil
+}
+
+func doubleSha256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+func base58CheckEncode(payload []byte) string {
+	data := make([]byte, 0, len(payload)+4)
+	data = append(data, payload...)
+	data = append(data, doubleSha256(payload)[:4]...)
+	return base58Encode(data)
+}
+
+func base58CheckDecode(s string) ([]byte, error) {
+	data, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 5 {
+		return nil, errors.New("地址长度错误")
+	}
+
+	payload, checksum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(doubleSha256(payload)[:4], checksum) {
+		return nil, errors.New("地址校验和错误")
+	}
+	return payload, nil
+}
+
+func base58Encode(data []byte) string {
+	x := new(big.Int).SetBytes(data)
+	base := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	base := big.NewInt(58)
+
+	for _, c := range s {
+		idx := strings.IndexRune(base58Alphabet, c)
+		if idx < 0 {
+			return nil, errors.New("地址包含非法字符")
+		}
+		x.Mul(x, base)
+		x.Add(x, big.NewInt(int64(idx)))
+	}
+
+	decoded := x.Bytes()
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), decoded...), nil
+}
@@ -0,0 +1,76 @@
+package tron
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	// Bitcoin base58_encode_decode 测试向量
+	tests := []struct {
+		hex, base58 string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"00000000000000000000", "1111111111"},
+		{"0000287fb4cd", "11233QC4"},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.hex)
+		if got := base58Encode(data); got != tt.base58 {
+			t.Errorf("base58Encode(%s) = %q, want %q", tt.hex, got, tt.base58)
+		}
+		decoded, err := base58Decode(tt.base58)
+		if err != nil {
+			t.Errorf("base58Decode(%q) error: %v", tt.base58, err)
+			continue
+		}
+		if got := hex.EncodeToString(decoded); got != tt.hex {
+			t.Errorf("base58Decode(%q) = %s, want %s", tt.base58, got, tt.hex)
+		}
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		hex  string // 为空表示应返回错误
+	}{
+		{"base58 usdt", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},
+		{"base58 zero address", "T9yD14Nj9j7xAB4dbGeiX9h8unkKHxuWwb", "410000000000000000000000000000000000000000"},
+		{"hex", "41a614f803b6fd780986a42c78ec9c7f77e6ded13c", "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},
+		{"surrounding spaces", " TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t ", "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},
+
+		{"bad checksum", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", ""},
+		{"invalid character", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj60", ""},
+		{"truncated", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6", ""},
+		{"bitcoin address", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", ""},
+		{"hex without 41 prefix", "a614f803b6fd780986a42c78ec9c7f77e6ded13c", ""},
+		{"bad hex", "41z614f803b6fd780986a42c78ec9c7f77e6ded13c", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := ParseAddress(tt.in)
+			if tt.hex == "" {
+				if err == nil {
+					t.Fatalf("ParseAddress(%q) = %s, want error", tt.in, addr.Hex())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAddress(%q) error: %v", tt.in, err)
+			}
+			if addr.Hex() != tt.hex {
+				t.Fatalf("ParseAddress(%q) = %s, want %s", tt.in, addr.Hex(), tt.hex)
+			}
+			if back, _ := ParseAddress(addr.Base58()); back != addr {
+				t.Fatalf("Base58 round trip = %s, want %s", back.Hex(), tt.hex)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tron
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// 合约类型编号(java-tron Transaction.Contract.ContractType)
+var ContractTypes = map[string]int{
+	"AccountCreateContract":           0,
+	"TransferContract":                1,
+	"TransferAssetContract":           2,
+	"VoteAssetContract":               3,
+	"VoteWitnessContract":             4,
+	"WitnessCreateContract":           5,
+	"AssetIssueContract":              6,
+	"WitnessUpdateContract":           8,
+	"ParticipateAssetIssueContract":   9,
+	"AccountUpdateContract":           10,
+	"FreezeBalanceContract":           11,
+	"UnfreezeBalanceContract":         12,
+	"WithdrawBalanceContract":         13,
+	"UnfreezeAssetContract":           14,
+	"UpdateAssetContract":             15,
+	"ProposalCreateContract":          16,
+	"ProposalApproveContract":         17,
+	"ProposalDeleteContract":          18,
+	"SetAccountIdContract":            19,
+	"CustomContract":                  20,
+	"CreateSmartContract":             30,
+	"TriggerSmartContract":            31,
+	"GetContract":                     32,
+	"UpdateSettingContract":           33,
+	"ExchangeCreateContract":          41,
+	"ExchangeInjectContract":          42,
+	"ExchangeWithdrawContract":        43,
+	"ExchangeTransactionContract":     44,
+	"UpdateEnergyLimitContract":       45,
+	"AccountPermissionUpdateContract": 46,
+	"ClearABIContract":                48,
+	"UpdateBrokerageContract":         49,
+	"ShieldedTransferContract":        51,
+	"MarketSellAssetContract":         52,
+	"MarketCancelOrderContract":       53,
+	"FreezeBalanceV2Contract":         54,
+	"UnfreezeBalanceV2Contract":       55,
+	"WithdrawExpireUnfreezeContract":  56,
+	"DelegateResourceContract":        57,
+	"UnDelegateResourceContract":      58,
+	"CancelAllUnfreezeV2Contract":     59,
+}
+
+// 根据编号查找合约类型名称
+func ContractTypeName(id int) string {
+	for name, v := range ContractTypes {
+		if v == id {
+			return name
+		}
+	}
+	return strconv.Itoa(id)
+}
+
+// 将合约类型名称或编号列表编码为32字节操作位图
+func EncodeOperations(ops []string) (string, error) {
+	bitmap := make([]byte, 32)
+	for _, op := range ops {
+		id, ok := ContractTypes[op]
+		if !ok {
+			n, err := strconv.Atoi(op)
+			if err != nil {
+				return "", fmt.Errorf("未知的合约类型: %s", op)
+			}
+			id = n
+		}
+		if id < 0 || id >= 256 {
+			return "", fmt.Errorf("合约类型编号超出范围: %d", id)
+		}
+		bitmap[id/8] |= 1 << uint(id%8)
+	}
+	return hex.EncodeToString(bitmap), nil
+}
+
+// 将操作位图解码为合约类型名称列表
+func DecodeOperations(operations string) ([]string, error) {
+	bitmap, err := hex.DecodeString(operations)
+	if err != nil || len(bitmap) != 32 {
+		return nil, errors.New("操作位图必须为64位十六进制字符串")
+	}
+
+	var ids []int
+	for i := 0; i < 256; i++ {
+		if bitmap[i/8]&(1<<uint(i%8)) != 0 {
+			ids = append(ids, i)
+		}
+	}
+	sort.Ints(ids)
+
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, ContractTypeName(id))
+	}
+	return names, nil
+}
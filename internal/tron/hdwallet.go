@@ -0,0 +1,176 @@
+package tron
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TRON的BIP44币种编号
+const CoinType = 195
+
+const hardenedOffset = 0x80000000
+
+// BIP32扩展私钥
+type extendedKey struct {
+	key       btcec.ModNScalar
+	chainCode []byte
+}
+
+// 由 crypto/rand 生成BIP39助记词，bits为熵长度：128位对应12个单词，256位对应24个单词
+func NewMnemonic(bits int) (string, error) {
+	if bits != 128 && bits != 256 {
+		return "", errors.New("助记词熵长度必须为128或256位")
+	}
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// 校验BIP39助记词的单词数、单词表和校验和，避免拼写错误时静默派生出其他地址
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return fmt.Errorf("助记词单词数必须为12、15、18、21或24个，当前为%d个", len(words))
+	}
+	for i, w := range words {
+		if _, ok := bip39.GetWordIndex(w); !ok {
+			return fmt.Errorf("助记词第%d个单词不在BIP39单词表中: %s", i+1, w)
+		}
+	}
+	if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+		return errors.New("助记词校验和错误，请检查单词拼写和顺序")
+	}
+	return nil
+}
+
+// 按BIP39由助记词生成种子
+func MnemonicToSeed(mnemonic, passphrase string) []byte {
+	words := strings.Join(strings.Fields(mnemonic), " ")
+	password := norm.NFKD.String(words)
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), 2048, 64, sha512.New)
+}
+
+// 助记词派生器，缓存 m/44'/195'/0'/0 节点以加速批量派生
+type MnemonicDeriver struct {
+	parent    *extendedKey
+	parentPub []byte
+}
+
+// 创建助记词派生器
+func NewMnemonicDeriver(mnemonic string) (*MnemonicDeriver, error) {
+	if strings.TrimSpace(mnemonic) == "" {
+		return nil, errors.New("助记词不能为空")
+	}
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	k, err := newMasterKey(MnemonicToSeed(mnemonic, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	path := []uint32{
+		44 + hardenedOffset,
+		CoinType + hardenedOffset,
+		0 + hardenedOffset,
+		0,
+	}
+	for _, i := range path {
+		k, err = k.child(i, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &MnemonicDeriver{
+		parent:    k,
+		parentPub: k.publicKey(),
+	}, nil
+}
+
+// 派生 m/44'/195'/0'/0/index 的私钥
+func (d *MnemonicDeriver) Derive(index uint32) (*PrivateKey, error) {
+	if index >= hardenedOffset {
+		return nil, errors.New("地址索引超出范围")
+	}
+
+	k, err := d.parent.child(index, d.parentPub)
+	if err != nil {
+		return nil, err
+	}
+	return newPrivateKey(&k.key), nil
+}
+
+// 按BIP44路径 m/44'/195'/0'/0/index 由助记词派生私钥
+func DeriveKeyFromMnemonic(mnemonic string, index uint32) (*PrivateKey, error) {
+	d, err := NewMnemonicDeriver(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	return d.Derive(index)
+}
+
+// 由种子生成主密钥
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	k := &extendedKey{chainCode: sum[32:]}
+	if k.key.SetByteSlice(sum[:32]) || k.key.IsZero() {
+		return nil, errors.New("无效的主密钥")
+	}
+	return k, nil
+}
+
+// 派生子私钥，pub为已计算好的压缩公钥(可为nil)
+func (k *extendedKey) child(i uint32, pub []byte) (*extendedKey, error) {
+	data := make([]byte, 0, 37)
+	if i >= hardenedOffset {
+		keyBytes := k.key.Bytes()
+		data = append(data, 0)
+		data = append(data, keyBytes[:]...)
+	} else {
+		if pub == nil {
+			pub = k.publicKey()
+		}
+		data = append(data, pub...)
+	}
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], i)
+	data = append(data, index[:]...)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	child := &extendedKey{chainCode: sum[32:]}
+	if child.key.SetByteSlice(sum[:32]) {
+		return nil, fmt.Errorf("派生索引 %d 无效", i)
+	}
+	child.key.Add(&k.key)
+	if child.key.IsZero() {
+		return nil, fmt.Errorf("派生索引 %d 无效", i)
+	}
+	return child, nil
+}
+
+// 压缩格式公钥(33字节)
+func (k *extendedKey) publicKey() []byte {
+	return btcec.PrivKeyFromScalar(&k.key).PubKey().SerializeCompressed()
+}
@@ -0,0 +1,131 @@
+package tron
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// secp256k1 私钥，签名及公钥运算使用 btcec(恒定时间实现，RFC6979确定性签名)
+type PrivateKey struct {
+	key *btcec.PrivateKey
+}
+
+// 随机生成私钥
+func GeneratePrivateKey() (*PrivateKey, error) {
+	key, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{key: key}, nil
+}
+
+// 解析64位十六进制私钥
+func ParsePrivateKey(s string) (*PrivateKey, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	if len(s) != 64 {
+		return nil, errors.New("私钥格式错误")
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("私钥格式错误")
+	}
+	return PrivateKeyFromBytes(b)
+}
+
+// 由32字节数据构造私钥
+func PrivateKeyFromBytes(b []byte) (*PrivateKey, error) {
+	var d btcec.ModNScalar
+	if len(b) != 32 || d.SetByteSlice(b) || d.IsZero() {
+		return nil, errors.New("私钥超出有效范围")
+	}
+	return newPrivateKey(&d), nil
+}
+
+func newPrivateKey(d *btcec.ModNScalar) *PrivateKey {
+	return &PrivateKey{key: btcec.PrivKeyFromScalar(d)}
+}
+
+// 私钥十六进制字符串
+func (k *PrivateKey) Hex() string {
+	return hex.EncodeToString(k.key.Serialize())
+}
+
+// 私钥对应的地址
+func (k *PrivateKey) Address() Address {
+	return publicKeyToAddress(k.key.PubKey())
+}
+
+// 对交易ID签名，返回65字节签名 r(32) || s(32) || v(1) 的十六进制字符串，v = 27 + recoveryId
+func (k *PrivateKey) SignTxID(txID string) (string, error) {
+	hash, err := hex.DecodeString(txID)
+	if err != nil || len(hash) != 32 {
+		return "", errors.New("交易ID格式错误")
+	}
+
+	// SignCompact返回 v || r || s，且已使用low-S形式
+	compact := ecdsa.SignCompact(k.key, hash, false)
+	sig := append(compact[1:65:65], compact[0])
+	return hex.EncodeToString(sig), nil
+}
+
+func publicKeyToAddress(pub *btcec.PublicKey) Address {
+	// 未压缩公钥去掉04前缀
+	return AddressFromEVM(Keccak256(pub.SerializeUncompressed()[1:])[12:])
+}
+
+// 从交易ID和签名中恢复签名者地址
+func RecoverSigner(txID, signature string) (Address, error) {
+	hash, err := hex.DecodeString(txID)
+	if err != nil || len(hash) != 32 {
+		return Address{}, errors.New("交易ID格式错误")
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return Address{}, errors.New("签名格式错误")
+	}
+	if len(sig) != 65 {
+		return Address{}, errors.New("签名长度必须为65字节")
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 3 {
+		return Address{}, errors.New("签名恢复标识无效")
+	}
+	compact := append([]byte{27 + v}, sig[:64]...)
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return Address{}, fmt.Errorf("无法恢复公钥: %v", err)
+	}
+	return publicKeyToAddress(pub), nil
+}
+
+// 根据raw_data_hex计算交易ID
+func TxIDFromRawData(rawDataHex string) (string, error) {
+	raw, err := hex.DecodeString(rawDataHex)
+	if err != nil {
+		return "", errors.New("raw_data_hex格式错误")
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Keccak256哈希
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,135 @@
+package tron
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrivateKeyAddress(t *testing.T) {
+	tests := []struct {
+		key, address string
+	}{
+		// 以太坊地址 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf
+		{strings.Repeat("0", 63) + "1", "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"},
+		// 以太坊地址 0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF
+		{strings.Repeat("0", 63) + "2", "TDvSsdrNM5eeXNL3czpa6AxLDHZA9nwe9K"},
+	}
+	for _, tt := range tests {
+		key, err := ParsePrivateKey(tt.key)
+		if err != nil {
+			t.Fatalf("ParsePrivateKey(%s) error: %v", tt.key, err)
+		}
+		if got := key.Address().Base58(); got != tt.address {
+			t.Errorf("Address(%s) = %s, want %s", tt.key, got, tt.address)
+		}
+		if key.Hex() != tt.key {
+			t.Errorf("Hex() = %s, want %s", key.Hex(), tt.key)
+		}
+	}
+}
+
+func TestParsePrivateKeyRejects(t *testing.T) {
+	for _, s := range []string{
+		"",
+		strings.Repeat("0", 64), // 零
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", // 等于曲线阶n
+		strings.Repeat("f", 64),
+		strings.Repeat("0", 62) + "1",
+		strings.Repeat("g", 64),
+	} {
+		if _, err := ParsePrivateKey(s); err == nil {
+			t.Errorf("ParsePrivateKey(%q) succeeded, want error", s)
+		}
+	}
+}
+
+// RFC6979确定性签名向量，签名格式为 r || s || v，v = 27 + recoveryId
+func TestSignTxID(t *testing.T) {
+	tests := []struct {
+		name, key, hash, sig string
+	}{
+		{
+			// sha256("Satoshi Nakamoto")
+			name: "recovery id 1",
+			key:  strings.Repeat("0", 63) + "1",
+			hash: "a0dc65ffca799873cbea0ac274015b9526505daaaed385155425f7337704883e",
+			sig:  "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8" + "2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5" + "1c",
+		},
+		{
+			// sha256("All those moments will be lost in time, like tears in rain. Time to die...")
+			name: "recovery id 0",
+			key:  strings.Repeat("0", 63) + "1",
+			hash: "7d1833f54854ac51659521afcd0ec6dca2ce2351429614bfa28a756b1b3c637f",
+			sig:  "8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b" + "547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc21" + "1b",
+		},
+		{
+			// 私钥为n-1，原始s大于n/2，需转为low-S并翻转恢复标识
+			name: "low-S normalization",
+			key:  "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+			hash: "a0dc65ffca799873cbea0ac274015b9526505daaaed385155425f7337704883e",
+			sig:  "fd567d121db66e382991534ada77a6bd3106f0a1098c231e47993447cd6af2d0" + "6b39cd0eb1bc8603e159ef5c20a5c8ad685a45b06ce9bebed3f153d10d93bed5" + "1b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParsePrivateKey(tt.key)
+			if err != nil {
+				t.Fatalf("ParsePrivateKey error: %v", err)
+			}
+			sig, err := key.SignTxID(tt.hash)
+			if err != nil {
+				t.Fatalf("SignTxID error: %v", err)
+			}
+			if sig != tt.sig {
+				t.Fatalf("SignTxID =\n%s\nwant\n%s", sig, tt.sig)
+			}
+
+			signer, err := RecoverSigner(tt.hash, sig)
+			if err != nil {
+				t.Fatalf("RecoverSigner error: %v", err)
+			}
+			if signer != key.Address() {
+				t.Fatalf("RecoverSigner = %s, want %s", signer.Base58(), key.Address().Base58())
+			}
+
+			// 翻转恢复标识后应恢复出其他地址
+			flipped := sig[:128] + map[string]string{"1b": "1c", "1c": "1b"}[sig[128:]]
+			if other, err := RecoverSigner(tt.hash, flipped); err == nil && other == key.Address() {
+				t.Fatalf("RecoverSigner with flipped v = signer, want a different address")
+			}
+		})
+	}
+}
+
+func TestRecoverSigner(t *testing.T) {
+	hash := "a0dc65ffca799873cbea0ac274015b9526505daaaed385155425f7337704883e"
+	rs := "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8" + "2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"
+	tests := []struct {
+		name, hash, sig string
+		ok              bool
+	}{
+		{"v = 28", hash, rs + "1c", true},
+		{"raw recovery id", hash, rs + "01", true},
+		{"invalid v", hash, rs + "05", false},
+		{"short signature", hash, rs, false},
+		{"bad hash", "abcd", rs + "1c", false},
+		{"bad hex", hash, "zz" + rs[2:] + "1c", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := RecoverSigner(tt.hash, tt.sig)
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("RecoverSigner = %s, want error", addr.Base58())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecoverSigner error: %v", err)
+			}
+			if addr.Base58() != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" {
+				t.Fatalf("RecoverSigner = %s, want TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC", addr.Base58())
+			}
+		})
+	}
+}
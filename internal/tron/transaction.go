@@ -0,0 +1,243 @@
+package tron
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// protobuf字段的编码类型
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// 交易原始数据(protocol.Transaction.raw)中本服务用到的字段，
+// raw_data_hex即其protobuf编码，签名的是该编码的sha256
+type RawData struct {
+	RefBlockBytes []byte
+	RefBlockNum   int64
+	RefBlockHash  []byte
+	Expiration    int64
+	Data          []byte // 备注
+	Contracts     []RawContract
+	Timestamp     int64
+	FeeLimit      int64
+}
+
+// 交易中的合约(protocol.Transaction.Contract)
+type RawContract struct {
+	Type         int
+	TypeURL      string
+	Value        []byte // 合约参数的protobuf编码
+	PermissionID int
+}
+
+// 解码raw_data_hex
+func DecodeRawData(rawDataHex string) (*RawData, error) {
+	data, err := hex.DecodeString(rawDataHex)
+	if err != nil {
+		return nil, errors.New("raw_data_hex格式错误")
+	}
+
+	raw := &RawData{}
+	err = walkFields(data, func(num, wire int, v uint64, b []byte) error {
+		switch {
+		case num == 1 && wire == wireBytes:
+			raw.RefBlockBytes = b
+		case num == 3 && wire == wireVarint:
+			raw.RefBlockNum = int64(v)
+		case num == 4 && wire == wireBytes:
+			raw.RefBlockHash = b
+		case num == 8 && wire == wireVarint:
+			raw.Expiration = int64(v)
+		case num == 10 && wire == wireBytes:
+			raw.Data = b
+		case num == 11 && wire == wireBytes:
+			c, err := decodeContract(b)
+			if err != nil {
+				return err
+			}
+			raw.Contracts = append(raw.Contracts, *c)
+		case num == 14 && wire == wireVarint:
+			raw.Timestamp = int64(v)
+		case num == 18 && wire == wireVarint:
+			raw.FeeLimit = int64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("raw_data_hex解码失败: %v", err)
+	}
+	return raw, nil
+}
+
+func decodeContract(data []byte) (*RawContract, error) {
+	c := &RawContract{}
+	err := walkFields(data, func(num, wire int, v uint64, b []byte) error {
+		switch {
+		case num == 1 && wire == wireVarint:
+			c.Type = int(v)
+		case num == 2 && wire == wireBytes:
+			// google.protobuf.Any
+			return walkFields(b, func(num, wire int, _ uint64, b []byte) error {
+				if wire == wireBytes {
+					switch num {
+					case 1:
+						c.TypeURL = string(b)
+					case 2:
+						c.Value = b
+					}
+				}
+				return nil
+			})
+		case num == 5 && wire == wireVarint:
+			c.PermissionID = int(v)
+		}
+		return nil
+	})
+	return c, err
+}
+
+// protobuf编码
+func (r *RawData) Marshal() []byte {
+	var e Encoder
+	e.Bytes(1, r.RefBlockBytes)
+	e.Varint(3, uint64(r.RefBlockNum))
+	e.Bytes(4, r.RefBlockHash)
+	e.Varint(8, uint64(r.Expiration))
+	e.Bytes(10, r.Data)
+	for _, c := range r.Contracts {
+		var any, contract Encoder
+		any.Bytes(1, []byte(c.TypeURL))
+		any.Bytes(2, c.Value)
+		contract.Varint(1, uint64(c.Type))
+		contract.Bytes(2, any.Result())
+		contract.Varint(5, uint64(c.PermissionID))
+		e.Message(11, contract.Result())
+	}
+	e.Varint(14, uint64(r.Timestamp))
+	e.Varint(18, uint64(r.FeeLimit))
+	return e.Result()
+}
+
+// protobuf消息的字段值，varint类型取Varint，bytes/string/message类型取Bytes
+type Field struct {
+	Varint uint64
+	Bytes  []byte
+}
+
+// 按字段编号解码合约参数等protobuf消息，重复出现的字段以最后一个为准(与protobuf解析规则一致)
+func DecodeFields(data []byte) (map[int]Field, error) {
+	fields := make(map[int]Field)
+	err := walkFields(data, func(num, wire int, v uint64, b []byte) error {
+		fields[num] = Field{Varint: v, Bytes: b}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// 按出现顺序读取重复字段(repeated bytes/message)的全部值
+func RepeatedField(data []byte, num int) ([][]byte, error) {
+	var values [][]byte
+	err := walkFields(data, func(n, wire int, v uint64, b []byte) error {
+		if n == num && wire == wireBytes {
+			values = append(values, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// 逐个读取protobuf字段
+func walkFields(data []byte, fn func(num, wire int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("字段标识错误")
+		}
+		data = data[n:]
+		num, wire := int(key>>3), int(key&7)
+		if num == 0 {
+			return errors.New("字段编号错误")
+		}
+
+		var v uint64
+		var b []byte
+		switch wire {
+		case wireVarint:
+			if v, n = binary.Uvarint(data); n <= 0 {
+				return errors.New("varint字段错误")
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return errors.New("fixed64字段长度不足")
+			}
+			v, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return errors.New("fixed32字段长度不足")
+			}
+			v, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || size > uint64(len(data)-n) {
+				return errors.New("字段长度错误")
+			}
+			b, data = data[n:n+int(size)], data[n+int(size):]
+		default:
+			return fmt.Errorf("不支持的字段类型: %d", wire)
+		}
+		if err := fn(num, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protobuf编码器，按proto3规则省略零值字段
+type Encoder struct {
+	buf []byte
+}
+
+func (e *Encoder) key(num, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(num)<<3|uint64(wire))
+}
+
+// 写入varint字段，int64负数按补码编码
+func (e *Encoder) Varint(num int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.key(num, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+// 写入bytes或string字段
+func (e *Encoder) Bytes(num int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.Message(num, b)
+}
+
+// 写入嵌套消息，空消息也会写入
+func (e *Encoder) Message(num int, b []byte) {
+	e.key(num, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *Encoder) Result() []byte {
+	return e.buf
+}
@@ -0,0 +1,118 @@
+package tron
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// 手工编码的TransferContract交易：ref_block_bytes=abcd，ref_block_hash=0102030405060708，
+// expiration=1700000060000，data="memo"，timestamp=1700000000000，fee_limit=150000000，
+// 由私钥1的地址向USDT合约地址转账1000000 SUN
+const (
+	transferRawData = "0a02abcd2208010203040506070840e0a499ffbc3152046d656d6f5a67080112630a2d747970652e676f6f676c65617069732e636f6d2f70726f746f636f6c2e5472616e73666572436f6e747261637412320a15417e5f4552091a69125d5dfcb7b8c2659029395bdf121541a614f803b6fd780986a42c78ec9c7f77e6ded13c18c0843d7080d095ffbc31900180a3c347"
+	transferValue   = "0a15417e5f4552091a69125d5dfcb7b8c2659029395bdf121541a614f803b6fd780986a42c78ec9c7f77e6ded13c18c0843d"
+	transferTxID    = "6b64426eb19ada9a6967cbc19e22d00d5f85300453d480f5af37e41b06fe0ea1"
+	transferSig     = "561dcf78708c1015efe9ab1d9929f2b62ae699f037f3c32fcd4bc5104fb5702e21c298d6074eb1076037148db133a829c31620f061a5037ceb084bc9b6e02aa21c"
+)
+
+func TestDecodeRawData(t *testing.T) {
+	raw, err := DecodeRawData(transferRawData)
+	if err != nil {
+		t.Fatalf("DecodeRawData error: %v", err)
+	}
+
+	checks := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"ref_block_bytes", hex.EncodeToString(raw.RefBlockBytes), "abcd"},
+		{"ref_block_hash", hex.EncodeToString(raw.RefBlockHash), "0102030405060708"},
+		{"expiration", raw.Expiration, int64(1700000060000)},
+		{"data", string(raw.Data), "memo"},
+		{"timestamp", raw.Timestamp, int64(1700000000000)},
+		{"fee_limit", raw.FeeLimit, int64(150000000)},
+		{"contracts", len(raw.Contracts), 1},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+		}
+	}
+	if len(raw.Contracts) != 1 {
+		t.FailNow()
+	}
+
+	contract := raw.Contracts[0]
+	if contract.Type != 1 || contract.TypeURL != "type.googleapis.com/protocol.TransferContract" || contract.PermissionID != 0 {
+		t.Fatalf("contract = %d %s permission %d", contract.Type, contract.TypeURL, contract.PermissionID)
+	}
+	if hex.EncodeToString(contract.Value) != transferValue {
+		t.Fatalf("contract value = %x, want %s", contract.Value, transferValue)
+	}
+
+	fields, err := DecodeFields(contract.Value)
+	if err != nil {
+		t.Fatalf("DecodeFields error: %v", err)
+	}
+	if owner := AddressFromEVM(fields[1].Bytes[1:]); owner.Base58() != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" {
+		t.Errorf("owner = %s", owner.Base58())
+	}
+	if to := AddressFromEVM(fields[2].Bytes[1:]); to.Base58() != "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t" {
+		t.Errorf("to = %s", to.Base58())
+	}
+	if fields[3].Varint != 1000000 {
+		t.Errorf("amount = %d, want 1000000", fields[3].Varint)
+	}
+
+	if got := hex.EncodeToString(raw.Marshal()); got != transferRawData {
+		t.Fatalf("Marshal =\n%s\nwant\n%s", got, transferRawData)
+	}
+}
+
+func TestDecodeRawDataRejects(t *testing.T) {
+	tests := []struct {
+		name, hex string
+	}{
+		{"not hex", "zz"},
+		{"truncated length", strings.TrimSuffix(transferRawData, "47")},
+		{"length beyond data", "0a05abcd"},
+		{"field number zero", "0001"},
+		{"unsupported wire type", "0b"},
+		{"truncated varint", "40ff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeRawData(tt.hex); err == nil {
+				t.Fatalf("DecodeRawData(%s) succeeded, want error", tt.hex)
+			}
+		})
+	}
+}
+
+func TestTransactionSignature(t *testing.T) {
+	txID, err := TxIDFromRawData(transferRawData)
+	if err != nil {
+		t.Fatalf("TxIDFromRawData error: %v", err)
+	}
+	if txID != transferTxID {
+		t.Fatalf("TxIDFromRawData = %s, want %s", txID, transferTxID)
+	}
+
+	key, _ := ParsePrivateKey(strings.Repeat("0", 63) + "1")
+	sig, err := key.SignTxID(txID)
+	if err != nil {
+		t.Fatalf("SignTxID error: %v", err)
+	}
+	if sig != transferSig {
+		t.Fatalf("SignTxID =\n%s\nwant\n%s", sig, transferSig)
+	}
+
+	signer, err := RecoverSigner(txID, sig)
+	if err != nil {
+		t.Fatalf("RecoverSigner error: %v", err)
+	}
+	if signer.Base58() != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" {
+		t.Fatalf("RecoverSigner = %s", signer.Base58())
+	}
+}
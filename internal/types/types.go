@@ -1,7 +1,9 @@
 package types
 
 import (
+	"encoding/json"
 	"math/big"
+	"net/url"
 )
 
 // 配置结构体
@@ -10,6 +12,73 @@ type Config struct {
 	TronAPIURL      string `json:"tron_api_url"`
 	ContractAddress string `json:"contract_address"`
 	Decimals        int    `json:"decimals"`
+	FeeLimit        int64  `json:"fee_limit"`     // 合约调用手续费上限(SUN)
+	DataDir         string `json:"data_dir"`      // 任务数据存储目录
+	QueueWorkers    int    `json:"queue_workers"` // 发送队列工作协程数
+	Backend         string `json:"backend"`       // 链后端：trongrid(默认)、fullnode、fixture、simulator
+	FixtureFile     string `json:"fixture_file"`  // fixture后端的JSON数据文件
+
+	Network         string             `json:"network"`           // 当前网络名称：mainnet、shasta、nile或自定义
+	SolidityNodeURL string             `json:"solidity_node_url"` // 固化节点地址，为空时使用TronAPIURL
+	EventServerURL  string             `json:"event_server_url"`  // TronGrid /v1 接口地址，为空时使用TronAPIURL
+	ExplorerURL     string             `json:"explorer_url"`      // 交易浏览器链接模板，{txid}替换为交易ID
+	Networks        map[string]Network `json:"networks"`          // 自定义网络，可覆盖内置网络
+
+	APIKeys    []string          `json:"api_keys"`     // TronGrid API Key(TRON-PRO-API-KEY)，多个时轮换使用
+	APIKeyRate int               `json:"api_key_rate"` // 每个API Key每秒请求数上限，0为不限制
+	Headers    map[string]string `json:"headers"`      // 请求节点时附加的请求头，用于自建网关鉴权等
+
+	FallbackNodeURLs []string `json:"fallback_node_urls"` // 备用全节点，主节点失败或熔断时切换
+	UpstreamTimeout  int      `json:"upstream_timeout"`   // 单次请求节点的超时时间(秒)
+	UpstreamRetries  int      `json:"upstream_retries"`   // 只读请求失败后的最大重试次数
+
+	Chain ChainClient `json:"-"` // 链访问客户端，为空时按TronAPIURL使用TronGrid
+}
+
+// 网络配置
+type Network struct {
+	Name            string `json:"name"`
+	FullNodeURL     string `json:"full_node_url"`
+	SolidityNodeURL string `json:"solidity_node_url,omitempty"`
+	EventServerURL  string `json:"event_server_url,omitempty"`
+	ContractAddress string `json:"contract_address"` // USDT合约地址
+	ExplorerURL     string `json:"explorer_url,omitempty"`
+
+	FallbackNodeURLs []string `json:"fallback_node_urls,omitempty"` // 备用全节点
+}
+
+// TronGrid API Key 使用统计
+type APIKeyStats struct {
+	Key           string `json:"key"`                      // 脱敏后的Key
+	Requests      int64  `json:"requests"`                 // 累计请求数
+	Throttled     int64  `json:"throttled"`                // 累计被限流(429)次数
+	CurrentRate   int    `json:"current_rate"`             // 当前秒内的请求数
+	CooldownUntil int64  `json:"cooldown_until,omitempty"` // 被限流暂停使用到该时间戳
+}
+
+// 节点健康统计
+type UpstreamStats struct {
+	Role      string  `json:"role"` // full、solidity 或 event
+	URL       string  `json:"url"`
+	Health    float64 `json:"health"`     // 最近请求成功率的加权平均，1为完全健康
+	LatencyMs int64   `json:"latency_ms"` // 最近请求耗时的加权平均(毫秒)
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	Breaker   string  `json:"breaker"` // 熔断状态：closed、open 或 half-open
+}
+
+// 链访问接口，实现见 chain 包
+type ChainClient interface {
+	// 后端名称
+	Name() string
+	// 调用节点 /wallet 接口，节点返回Error时返回错误
+	Wallet(path string, payload interface{}, out interface{}) error
+	// 查询账户TRX余额(SUN)，未激活账户返回0
+	Balance(address string) (int64, error)
+	// 查询账户交易(TRX、TRC10等)，query为TronGrid分页及筛选参数
+	AccountTransactions(address string, query url.Values) (*GridTransactionsResponse, error)
+	// 查询账户TRC20转账
+	AccountTrc20Transfers(address string, query url.Values) (*GridTrc20Response, error)
 }
 
 // 通用响应结构体
@@ -28,17 +97,688 @@ type AddressResponse struct {
 	Mnemonic   string `json:"mnemonic,omitempty"`
 }
 
-// 余额响应
+// 余额响应，balance为十进制字符串，balanceUnits为最小单位整数
 type BalanceResponse struct {
-	Balance string `json:"balance"`
-	Address string `json:"address"`
+	Balance      string `json:"balance"`
+	BalanceUnits string `json:"balanceUnits"`
+	Decimals     int    `json:"decimals"`
+	Token        string `json:"token"`
+	Address      string `json:"address"`
+}
+
+// TRC20授权额度
+type AllowanceResponse struct {
+	Owner          string `json:"owner"`
+	Spender        string `json:"spender"`
+	Token          string `json:"token"`
+	Allowance      string `json:"allowance"`
+	AllowanceUnits string `json:"allowanceUnits"`
+	Decimals       int    `json:"decimals"`
+	Unlimited      bool   `json:"unlimited"` // 是否为无限授权(uint256最大值)
+}
+
+// TRC721 NFT信息
+type Trc721Token struct {
+	Contract string `json:"contract,omitempty"`
+	TokenID  string `json:"tokenId"`
+	Owner    string `json:"owner,omitempty"`
+	TokenURI string `json:"tokenURI,omitempty"`
+}
+
+// TRC721持有数量
+type Trc721BalanceResponse struct {
+	Address  string `json:"address"`
+	Contract string `json:"contract"`
+	Balance  string `json:"balance"`
+}
+
+// 地址持有的TRC721 NFT列表
+type Trc721TokenList struct {
+	Address    string        `json:"address"`
+	Contract   string        `json:"contract"`
+	Balance    string        `json:"balance"`
+	Offset     int64         `json:"offset"`
+	NextOffset int64         `json:"nextOffset,omitempty"` // 还有更多时为下一页的offset
+	Tokens     []Trc721Token `json:"tokens"`
+}
+
+// TRC10代币信息
+type Trc10Token struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Abbr         string `json:"abbr,omitempty"`
+	Precision    int    `json:"precision"`
+	TotalSupply  int64  `json:"total_supply"`
+	OwnerAddress string `json:"owner_address"`
+	URL          string `json:"url,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// TRC10余额响应
+type Trc10BalanceResponse struct {
+	Address         string      `json:"address,omitempty"`
+	TokenInfo       *Trc10Token `json:"tokenInfo"`
+	TokenBalance    string      `json:"tokenBalance,omitempty"`
+	TokenUnits      string      `json:"tokenBalanceUnits,omitempty"`
+	TrxBalance      string      `json:"trxBalance,omitempty"`
+	TrxBalanceUnits string      `json:"trxBalanceUnits,omitempty"`
 }
 
 // 交易响应
 type TransactionResponse struct {
-	Result bool   `json:"result"`
-	TxID   string `json:"txID"`
-	TxId   string `json:"txid"`
+	Result      bool   `json:"result"`
+	TxID        string `json:"txID"`
+	TxId        string `json:"txid"`
+	Amount      string `json:"amount,omitempty"`
+	AmountUnits string `json:"amountUnits,omitempty"`
+}
+
+// 多签交易响应
+type MultiSignResponse struct {
+	Result      bool         `json:"result"`
+	TxID        string       `json:"txID"`
+	Amount      string       `json:"amount,omitempty"`
+	AmountUnits string       `json:"amountUnits,omitempty"`
+	Broadcast   bool         `json:"broadcast"`
+	SignWeight  *SignWeight  `json:"signWeight,omitempty"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
+
+// TRON交易结构(节点 /wallet 接口格式)
+type Transaction struct {
+	Visible    bool            `json:"visible"`
+	TxID       string          `json:"txID"`
+	RawData    json.RawMessage `json:"raw_data"`
+	RawDataHex string          `json:"raw_data_hex"`
+	Signature  []string        `json:"signature,omitempty"`
+}
+
+// 权限中的密钥及权重
+type PermissionKey struct {
+	Address string `json:"address"`
+	Weight  int64  `json:"weight"`
+}
+
+// 账户权限
+type Permission struct {
+	Type           string          `json:"type,omitempty"`
+	ID             int             `json:"id"`
+	PermissionName string          `json:"permission_name"`
+	Threshold      int64           `json:"threshold"`
+	Operations     string          `json:"operations,omitempty"`
+	OperationNames []string        `json:"operation_names,omitempty"`
+	Keys           []PermissionKey `json:"keys"`
+}
+
+// 账户当前权限(/wallet/getaccount)
+type AccountPermissions struct {
+	Address           string       `json:"address"`
+	OwnerPermission   *Permission  `json:"owner_permission"`
+	WitnessPermission *Permission  `json:"witness_permission,omitempty"`
+	ActivePermission  []Permission `json:"active_permission"`
+}
+
+// 权限配置请求中的单个权限
+type PermissionSpec struct {
+	Name          string          `json:"name"`
+	Threshold     int64           `json:"threshold"`
+	Operations    []string        `json:"operations"`    // 合约类型名称或编号
+	OperationsHex string          `json:"operationsHex"` // 或直接传入64位十六进制位图
+	Keys          []PermissionKey `json:"keys"`
+}
+
+// 账户权限更新请求
+type PermissionUpdateRequest struct {
+	Key          string           `json:"key"`
+	Address      string           `json:"address"`
+	PermissionID *int             `json:"permissionId"`
+	Owner        *PermissionSpec  `json:"owner"`
+	Actives      []PermissionSpec `json:"actives"`
+	DryRun       bool             `json:"dryRun"`
+}
+
+// 账户权限更新响应
+type PermissionUpdateResponse struct {
+	DryRun  bool                `json:"dryRun"`
+	Address string              `json:"address"`
+	Current *AccountPermissions `json:"current,omitempty"`
+	Owner   *Permission         `json:"owner"`
+	Actives []Permission        `json:"actives"`
+	Result  *MultiSignResponse  `json:"result,omitempty"`
+}
+
+// 签名权重(/wallet/getsignweight)
+type SignWeight struct {
+	Permission    *Permission `json:"permission"`
+	ApprovedList  []string    `json:"approved_list"`
+	CurrentWeight int64       `json:"current_weight"`
+	Threshold     int64       `json:"threshold"`
+	Enough        bool        `json:"enough"`
+	ResultCode    string      `json:"result_code,omitempty"`
+	ResultMessage string      `json:"result_message,omitempty"`
+}
+
+// 广播结果(/wallet/broadcasttransaction)
+type BroadcastResult struct {
+	Result  bool   `json:"result"`
+	TxID    string `json:"txid"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Stake 2.0 质押记录
+type FrozenV2 struct {
+	Type   string `json:"type"`
+	Amount int64  `json:"amount"`
+}
+
+// Stake 2.0 解锁中的记录
+type UnfrozenV2 struct {
+	Type               string `json:"type"`
+	UnfreezeAmount     int64  `json:"unfreeze_amount"`
+	UnfreezeExpireTime int64  `json:"unfreeze_expire_time"`
+}
+
+// 账户质押信息(/wallet/getaccount)
+type StakeAccount struct {
+	Address    string       `json:"address"`
+	Balance    int64        `json:"balance"`
+	FrozenV2   []FrozenV2   `json:"frozenV2"`
+	UnfrozenV2 []UnfrozenV2 `json:"unfrozenV2"`
+}
+
+// 质押金额展示
+type StakeAmount struct {
+	Resource  string `json:"resource"`
+	Amount    string `json:"amount"`
+	AmountSun int64  `json:"amountSun"`
+}
+
+// 待提取的解锁记录
+type PendingUnfreeze struct {
+	Resource     string `json:"resource"`
+	Amount       string `json:"amount"`
+	AmountSun    int64  `json:"amountSun"`
+	ExpireTime   int64  `json:"expireTime"`
+	ExpireDate   string `json:"expireDate"`
+	Withdrawable bool   `json:"withdrawable"`
+}
+
+// 质押及解锁状态响应
+type UnfreezeInfoResponse struct {
+	Address                string            `json:"address"`
+	Frozen                 []StakeAmount     `json:"frozen"`
+	Pending                []PendingUnfreeze `json:"pending"`
+	WithdrawableAmount     string            `json:"withdrawableAmount"`
+	WithdrawableAmountSun  int64             `json:"withdrawableAmountSun"`
+	NextWithdrawTime       int64             `json:"nextWithdrawTime,omitempty"`
+	AvailableUnfreezeCount int64             `json:"availableUnfreezeCount"`
+}
+
+// 资源代理记录(/wallet/getdelegatedresourcev2)
+type DelegatedResource struct {
+	From                      string `json:"from"`
+	To                        string `json:"to"`
+	FrozenBalanceForBandwidth int64  `json:"frozen_balance_for_bandwidth"`
+	FrozenBalanceForEnergy    int64  `json:"frozen_balance_for_energy"`
+	ExpireTimeForBandwidth    int64  `json:"expire_time_for_bandwidth"`
+	ExpireTimeForEnergy       int64  `json:"expire_time_for_energy"`
+}
+
+// 地址资源代理情况
+type DelegationInfoResponse struct {
+	Address      string              `json:"address"`
+	DelegatedTo  []DelegatedResource `json:"delegatedTo"`
+	ReceivedFrom []DelegatedResource `json:"receivedFrom"`
+}
+
+// 可代理资源上限
+type DelegatableResponse struct {
+	Address    string `json:"address"`
+	Resource   string `json:"resource"`
+	MaxSize    string `json:"maxSize"`
+	MaxSizeSun int64  `json:"maxSizeSun"`
+}
+
+// 交易执行结果(/wallet/gettransactioninfobyid)
+type TransactionInfo struct {
+	ID             string `json:"id"`
+	Fee            int64  `json:"fee"`
+	BlockNumber    int64  `json:"blockNumber"`
+	BlockTimeStamp int64  `json:"blockTimeStamp"`
+	Result         string `json:"result"`
+	ResMessage     string `json:"resMessage"`
+	Receipt        struct {
+		Result           string `json:"result"`
+		EnergyUsageTotal int64  `json:"energy_usage_total"`
+		NetUsage         int64  `json:"net_usage"`
+		NetFee           int64  `json:"net_fee"`
+		EnergyFee        int64  `json:"energy_fee"`
+	} `json:"receipt"`
+	Log []TransactionLog `json:"log,omitempty"`
+}
+
+// 合约事件日志(地址为不带41前缀的十六进制)
+type TransactionLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// 节点返回的区块(visible=true)
+type NodeBlock struct {
+	BlockID     string `json:"blockID"`
+	BlockHeader struct {
+		RawData struct {
+			Number         int64  `json:"number"`
+			Timestamp      int64  `json:"timestamp"`
+			ParentHash     string `json:"parentHash"`
+			WitnessAddress string `json:"witness_address"`
+		} `json:"raw_data"`
+	} `json:"block_header"`
+	Transactions []NodeTransaction `json:"transactions"`
+}
+
+// 节点返回的区块内交易
+type NodeTransaction struct {
+	TxID string `json:"txID"`
+	Ret  []struct {
+		ContractRet string `json:"contractRet"`
+	} `json:"ret"`
+	RawData struct {
+		Contract []struct {
+			Type      string `json:"type"`
+			Parameter struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"parameter"`
+		} `json:"contract"`
+	} `json:"raw_data"`
+}
+
+// 区块
+type Block struct {
+	Number     int64      `json:"number"`
+	Hash       string     `json:"hash"`
+	ParentHash string     `json:"parentHash"`
+	Timestamp  int64      `json:"timestamp"`
+	Witness    string     `json:"witness"`
+	TxCount    int        `json:"txCount"`
+	Transfers  []Transfer `json:"transfers,omitempty"`
+}
+
+// 区块中的转账
+type Transfer struct {
+	TxID        string `json:"txID"`
+	Index       int    `json:"index"` // 同一交易中的序号，TRC20为事件日志序号
+	BlockNumber int64  `json:"blockNumber"`
+	BlockTime   int64  `json:"blockTimeStamp"`
+	TokenType   string `json:"tokenType"` // trx | trc10 | trc20
+	Token       string `json:"token"`     // TRX | TRC10代币ID | TRC20合约地址
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount,omitempty"`
+	AmountUnits string `json:"amountUnits"`
+	Decimals    *int   `json:"decimals,omitempty"`
+}
+
+// 合约事件
+type ContractEvent struct {
+	TxID           string                 `json:"txID"`
+	BlockNumber    int64                  `json:"blockNumber"`
+	BlockTimeStamp int64                  `json:"blockTimeStamp"`
+	LogIndex       int                    `json:"logIndex"` // 同一交易中的日志序号
+	Contract       string                 `json:"contract"`
+	Event          string                 `json:"event,omitempty"`
+	Signature      string                 `json:"signature,omitempty"`
+	Fields         map[string]interface{} `json:"fields,omitempty"`
+	Topics         []string               `json:"topics,omitempty"` // 无法解码时返回原始主题和数据
+	Data           string                 `json:"data,omitempty"`
+}
+
+// 合约事件查询结果
+type ContractEventsResult struct {
+	Contract  string          `json:"contract"`
+	FromBlock int64           `json:"fromBlock"`
+	ToBlock   int64           `json:"toBlock"`
+	NextBlock int64           `json:"nextBlock,omitempty"` // 区间超出单次上限时，下一次查询的起始区块
+	Count     int             `json:"count"`
+	Events    []ContractEvent `json:"events"`
+}
+
+// 账户交易记录中的一笔转账
+type HistoryTransfer struct {
+	TxID         string `json:"txID"`
+	Type         string `json:"type"`      // trx、trc10、trc20
+	Direction    string `json:"direction"` // in、out、self
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Counterparty string `json:"counterparty"`
+	Token        string `json:"token"` // TRX、TRC10代币ID或TRC20合约地址
+	Symbol       string `json:"symbol,omitempty"`
+	Amount       string `json:"amount"`
+	AmountUnits  string `json:"amountUnits"`
+	Decimals     int    `json:"decimals"`
+	Fee          string `json:"fee"` // 交易手续费(TRX)，由发送方支付
+	FeeSun       int64  `json:"feeSun"`
+	BlockNumber  int64  `json:"blockNumber,omitempty"`
+	Timestamp    int64  `json:"timestamp"` // 区块时间(毫秒)
+	Status       string `json:"status"`    // in_block、solidified、failed
+	Result       string `json:"result,omitempty"`
+}
+
+// 账户交易记录查询结果
+type TransactionHistory struct {
+	Address    string            `json:"address"`
+	Type       string            `json:"type"`
+	Contract   string            `json:"contract,omitempty"`
+	SolidBlock int64             `json:"solidBlock"`
+	Count      int               `json:"count"`
+	Transfers  []HistoryTransfer `json:"transfers"`
+	Cursor     string            `json:"cursor,omitempty"` // 下一页游标，没有更多记录时为空
+}
+
+// 合约调用请求(JSON请求体或表单参数)
+type ContractCallRequest struct {
+	Contract     string          `json:"contract"`
+	Function     string          `json:"function"` // 函数签名，如 approve(address,uint256)；提供ABI时也可为函数名
+	ABI          json.RawMessage `json:"abi"`      // 可选，ABI JSON，默认使用链上登记的ABI
+	Args         json.RawMessage `json:"args"`     // 参数列表，或以参数名为键的对象
+	Returns      string          `json:"returns"`  // 可选，返回值类型，如 (uint256,address)
+	From         string          `json:"from"`     // 调用地址，triggerContract多签时为账户地址
+	Key          string          `json:"key"`      // 私钥，仅triggerContract需要
+	PermissionID *int            `json:"permissionId"`
+	CallValue    string          `json:"callValue"` // 随调用转入合约的TRX
+	FeeLimit     string          `json:"feeLimit"`  // 手续费上限(TRX)，默认使用配置
+}
+
+// 合约部署请求(JSON请求体或表单参数)
+type DeployContractRequest struct {
+	Name                       string          `json:"name"`
+	Bytecode                   string          `json:"bytecode"` // 编译后的合约字节码(十六进制)
+	ABI                        json.RawMessage `json:"abi"`
+	Args                       json.RawMessage `json:"args"` // 构造函数参数
+	From                       string          `json:"from"`
+	Key                        string          `json:"key"`
+	PermissionID               *int            `json:"permissionId"`
+	CallValue                  string          `json:"callValue"`                  // 部署时转入合约的TRX
+	FeeLimit                   string          `json:"feeLimit"`                   // 手续费上限(TRX)，默认使用配置
+	ConsumeUserResourcePercent *int            `json:"consumeUserResourcePercent"` // 调用者承担的能量比例(0-100)，默认100
+	OriginEnergyLimit          *int64          `json:"originEnergyLimit"`          // 部署者每次调用最多承担的能量，默认10000000
+	Timeout                    int             `json:"timeout"`                    // 等待上链的秒数
+}
+
+// 合约部署结果
+type DeployContractResponse struct {
+	TxID            string             `json:"txID"`
+	ContractAddress string             `json:"contractAddress"`
+	Status          *TransactionStatus `json:"status,omitempty"`
+	Broadcast       bool               `json:"broadcast"`
+	SignWeight      *SignWeight        `json:"signWeight,omitempty"`
+	Transaction     *Transaction       `json:"transaction,omitempty"` // 多签未集齐时返回部分签名的交易
+}
+
+// 合约只读调用的节点返回结果
+type ConstantCallResult struct {
+	Result     string // 返回数据(十六进制)
+	EnergyUsed int64
+	Reverted   bool
+	Message    string
+}
+
+// 合约只读调用结果
+type ContractCallResult struct {
+	Contract     string                 `json:"contract"`
+	Function     string                 `json:"function"`
+	Selector     string                 `json:"selector"`
+	Result       string                 `json:"result"` // 原始返回数据(十六进制)
+	Outputs      map[string]interface{} `json:"outputs,omitempty"`
+	EnergyUsed   int64                  `json:"energyUsed"`
+	Reverted     bool                   `json:"reverted,omitempty"`
+	RevertReason string                 `json:"revertReason,omitempty"`
+}
+
+// 归集任务请求
+type SweepRequest struct {
+	Mnemonic       string   `json:"mnemonic"`       // 充值地址助记词，按 m/44'/195'/0'/0/index 派生
+	Offset         int      `json:"offset"`         // 起始索引
+	Num            int      `json:"num"`            // 地址数量
+	Keys           []string `json:"keys"`           // 或直接传入充值地址私钥
+	Target         string   `json:"target"`         // 归集目标地址
+	Contract       string   `json:"contract"`       // 代币合约，默认USDT
+	TokenThreshold string   `json:"tokenThreshold"` // 代币余额达到该值才归集
+	SweepTrx       bool     `json:"sweepTrx"`       // 是否同时归集TRX
+	TrxThreshold   string   `json:"trxThreshold"`   // TRX余额达到该值才归集
+	TrxReserve     string   `json:"trxReserve"`     // 归集TRX时保留的余额
+	FeeMode        string   `json:"feeMode"`        // 手续费方式：topup | delegate | none
+	FeeKey         string   `json:"feeKey"`         // 手续费钱包或质押账户私钥
+	TopupAmount    string   `json:"topupAmount"`    // topup模式下每个地址需要的TRX
+	DelegateAmount string   `json:"delegateAmount"` // delegate模式下代理能量的质押TRX数量
+	DryRun         bool     `json:"dryRun"`         // 仅规划，不发送交易
+}
+
+// 归集任务参数(不含私钥等敏感信息)
+type SweepSettings struct {
+	Source         string `json:"source"` // mnemonic | keys
+	Offset         int    `json:"offset"`
+	Num            int    `json:"num"`
+	Target         string `json:"target"`
+	Contract       string `json:"contract"`
+	Decimals       int    `json:"decimals"`
+	TokenThreshold string `json:"tokenThreshold"`
+	SweepTrx       bool   `json:"sweepTrx"`
+	TrxThreshold   int64  `json:"trxThreshold"`
+	TrxReserve     int64  `json:"trxReserve"`
+	FeeMode        string `json:"feeMode"`
+	FeeAddress     string `json:"feeAddress,omitempty"`
+	TopupAmount    int64  `json:"topupAmount"`
+	DelegateAmount int64  `json:"delegateAmount"`
+	DryRun         bool   `json:"dryRun"`
+}
+
+// 归集步骤记录
+type SweepStep struct {
+	Name   string `json:"name"` // topup | delegate | transfer | undelegate | trxTransfer
+	TxID   string `json:"txID,omitempty"`
+	Amount string `json:"amount,omitempty"`
+	Status string `json:"status"` // broadcast | success | failed | expired
+	Error  string `json:"error,omitempty"`
+	Time   int64  `json:"time"`
+	// 签名交易的过期时间(毫秒)，超过后仍未上链的交易不会再被打包
+	Expiration int64 `json:"expiration,omitempty"`
+}
+
+// 单个地址的归集结果
+type SweepAddress struct {
+	Index        int         `json:"index"`
+	Address      string      `json:"address"`
+	TrxBalance   string      `json:"trxBalance,omitempty"`
+	TokenBalance string      `json:"tokenBalance,omitempty"`
+	Scanned      bool        `json:"scanned"`
+	Action       string      `json:"action,omitempty"` // sweep | skip
+	Status       string      `json:"status"`           // pending | planned | done | skipped | failed
+	Reason       string      `json:"reason,omitempty"`
+	Steps        []SweepStep `json:"steps,omitempty"`
+}
+
+// 归集汇总
+type SweepSummary struct {
+	Total      int    `json:"total"`
+	Scanned    int    `json:"scanned"`
+	ToSweep    int    `json:"toSweep"`
+	Done       int    `json:"done"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	TokenTotal string `json:"tokenTotal"`
+}
+
+// 归集任务
+type SweepJob struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"` // running | paused | planned | completed | failed
+	Error     string         `json:"error,omitempty"`
+	Settings  SweepSettings  `json:"settings"`
+	Summary   SweepSummary   `json:"summary"`
+	Addresses []SweepAddress `json:"addresses,omitempty"`
+	CreatedAt int64          `json:"createdAt"`
+	UpdatedAt int64          `json:"updatedAt"`
+}
+
+// 幂等请求记录
+type IdempotencyRecord struct {
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"` // 请求路径和参数的哈希
+	Status      string          `json:"status"`      // processing | completed
+	TxID        string          `json:"txID,omitempty"`
+	JobID       string          `json:"jobID,omitempty"` // 批量付款、归集等异步任务的ID
+	Response    json.RawMessage `json:"response,omitempty"`
+	CreatedAt   int64           `json:"createdAt"`
+	UpdatedAt   int64           `json:"updatedAt"`
+}
+
+// 交易确认状态
+type TransactionStatus struct {
+	TxID          string `json:"txID"`
+	Status        string `json:"status"` // broadcast | in_block | solidified | failed | expired | not_found
+	BlockNumber   int64  `json:"blockNumber,omitempty"`
+	BlockTime     int64  `json:"blockTimeStamp,omitempty"`
+	Confirmations int64  `json:"confirmations"`
+	HeadBlock     int64  `json:"headBlock,omitempty"`
+	SolidBlock    int64  `json:"solidBlock,omitempty"`
+	Result        string `json:"result,omitempty"` // 合约执行结果，如SUCCESS、REVERT、OUT_OF_ENERGY
+	Message       string `json:"message,omitempty"`
+	Fee           int64  `json:"fee,omitempty"`
+	Expiration    int64  `json:"expiration,omitempty"` // 交易过期时间(毫秒)
+	Rebroadcasts  int    `json:"rebroadcasts,omitempty"`
+	TimedOut      bool   `json:"timedOut,omitempty"`
+}
+
+// 账户资源(带宽/能量)
+type AccountResource struct {
+	FreeNetLimit int64 `json:"freeNetLimit"`
+	FreeNetUsed  int64 `json:"freeNetUsed"`
+	NetLimit     int64 `json:"NetLimit"`
+	NetUsed      int64 `json:"NetUsed"`
+	EnergyLimit  int64 `json:"EnergyLimit"`
+	EnergyUsed   int64 `json:"EnergyUsed"`
+}
+
+// 批量付款明细行
+type PayoutRow struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Token   string `json:"token,omitempty"` // TRX | TRC20合约地址 | TRC10代币ID
+	Memo    string `json:"memo,omitempty"`
+}
+
+// 批量付款请求
+type PayoutRequest struct {
+	Key             string      `json:"key"`
+	Token           string      `json:"token"` // 明细未指定token时使用，默认USDT
+	Rows            []PayoutRow `json:"rows"`
+	ContinueOnError bool        `json:"continueOnError"`
+	DryRun          bool        `json:"dryRun"`
+}
+
+// 批量付款单行结果
+type PayoutItem struct {
+	Row         int    `json:"row"` // 明细行号，从1开始
+	Address     string `json:"address"`
+	Amount      string `json:"amount"`
+	AmountUnits string `json:"amountUnits"`
+	Token       string `json:"token"`
+	TokenType   string `json:"tokenType"` // trx | trc20 | trc10
+	Memo        string `json:"memo,omitempty"`
+	Status      string `json:"status"` // pending | invalid | broadcast | success | failed | skipped
+	TxID        string `json:"txID,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Time        int64  `json:"time,omitempty"`
+	Expiration  int64  `json:"expiration,omitempty"` // 交易过期时间(毫秒)
+}
+
+// 批量付款按代币汇总
+type PayoutTotal struct {
+	Token        string `json:"token"`
+	TokenType    string `json:"tokenType"`
+	Decimals     int    `json:"decimals"`
+	Rows         int    `json:"rows"`
+	Amount       string `json:"amount"`
+	AmountUnits  string `json:"amountUnits"`
+	Balance      string `json:"balance"`
+	BalanceUnits string `json:"balanceUnits"`
+	Sufficient   bool   `json:"sufficient"`
+}
+
+// 批量付款资源及手续费预估
+type PayoutResources struct {
+	EnergyRequired     int64  `json:"energyRequired"`
+	EnergyAvailable    int64  `json:"energyAvailable"`
+	BandwidthRequired  int64  `json:"bandwidthRequired"`
+	BandwidthAvailable int64  `json:"bandwidthAvailable"`
+	FeeEstimate        string `json:"feeEstimate"` // 需燃烧的TRX(含备注费用)
+	FeeEstimateSun     int64  `json:"feeEstimateSun"`
+	TrxRequired        string `json:"trxRequired"` // TRX付款总额 + 预估手续费
+	TrxBalance         string `json:"trxBalance"`
+	Sufficient         bool   `json:"sufficient"`
+}
+
+// 批量付款进度
+type PayoutSummary struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Success int `json:"success"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// 批量付款批次
+type PayoutBatch struct {
+	ID              string           `json:"id"`
+	Status          string           `json:"status"` // invalid | checked | running | completed | failed | interrupted
+	Error           string           `json:"error,omitempty"`
+	From            string           `json:"from"`
+	ContinueOnError bool             `json:"continueOnError"`
+	DryRun          bool             `json:"dryRun"`
+	Totals          []PayoutTotal    `json:"totals"`
+	Resources       *PayoutResources `json:"resources,omitempty"`
+	Summary         PayoutSummary    `json:"summary"`
+	Items           []PayoutItem     `json:"items,omitempty"`
+	CreatedAt       int64            `json:"createdAt"`
+	UpdatedAt       int64            `json:"updatedAt"`
+}
+
+// 发送队列任务事件
+type QueueEvent struct {
+	Time   int64  `json:"time"`
+	Status string `json:"status"`
+	TxID   string `json:"txID,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// 发送队列任务
+type QueueJob struct {
+	ID              string       `json:"id"`
+	Status          string       `json:"status"` // queued | retrying | broadcast | confirmed | failed | cancelled | key_required
+	Error           string       `json:"error,omitempty"`
+	TokenType       string       `json:"tokenType"` // trx | trc10 | trc20
+	Token           string       `json:"token"`
+	From            string       `json:"from"`
+	Signer          string       `json:"signer"`
+	To              string       `json:"to"`
+	Amount          string       `json:"amount"`
+	AmountUnits     string       `json:"amountUnits"`
+	Decimals        int          `json:"decimals"`
+	PermissionID    int          `json:"permissionId,omitempty"`
+	TxID            string       `json:"txID,omitempty"`
+	Transaction     *Transaction `json:"transaction,omitempty"`
+	BlockNumber     int64        `json:"blockNumber,omitempty"`
+	Attempts        int          `json:"attempts"`
+	MaxAttempts     int          `json:"maxAttempts"`
+	Rebuilds        int          `json:"rebuilds"`
+	NextRunAt       int64        `json:"nextRunAt,omitempty"`
+	LastBroadcastAt int64        `json:"lastBroadcastAt,omitempty"`
+	History         []QueueEvent `json:"history,omitempty"`
+	CreatedAt       int64        `json:"createdAt"`
+	UpdatedAt       int64        `json:"updatedAt"`
 }
 
 // TRON API响应结构
@@ -68,6 +808,77 @@ type TRC20Data struct {
 	Vip             bool    `json:"vip"`
 }
 
+// TronGrid /v1 接口分页信息
+type GridMeta struct {
+	Fingerprint string `json:"fingerprint"`
+	PageSize    int    `json:"page_size"`
+}
+
+// TronGrid账户交易(/v1/accounts/{address}/transactions，地址为41开头的十六进制)
+type GridTransaction struct {
+	TxID           string `json:"txID"`
+	BlockNumber    int64  `json:"blockNumber"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	NetFee         int64  `json:"net_fee"`
+	EnergyFee      int64  `json:"energy_fee"`
+	Ret            []struct {
+		ContractRet string `json:"contractRet"`
+		Fee         int64  `json:"fee"`
+	} `json:"ret"`
+	RawData struct {
+		Contract []struct {
+			Type      string `json:"type"`
+			Parameter struct {
+				Value struct {
+					OwnerAddress string `json:"owner_address"`
+					ToAddress    string `json:"to_address"`
+					Amount       int64  `json:"amount"`
+					AssetName    string `json:"asset_name"`
+				} `json:"value"`
+			} `json:"parameter"`
+		} `json:"contract"`
+	} `json:"raw_data"`
+}
+
+type GridTransactionsResponse struct {
+	Data []GridTransaction `json:"data"`
+	Meta GridMeta          `json:"meta"`
+}
+
+// TronGrid账户TRC20转账(/v1/accounts/{address}/transactions/trc20)
+type GridTrc20Transfer struct {
+	TransactionID  string `json:"transaction_id"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Type           string `json:"type"`
+	Value          string `json:"value"`
+	TokenInfo      struct {
+		Symbol   string `json:"symbol"`
+		Address  string `json:"address"`
+		Decimals int    `json:"decimals"`
+		Name     string `json:"name"`
+	} `json:"token_info"`
+}
+
+type GridTrc20Response struct {
+	Data []GridTrc20Transfer `json:"data"`
+	Meta GridMeta            `json:"meta"`
+}
+
+// TronGrid合约事件(/v1/contracts/{address}/events)，只用于定位含事件的交易，字段解码以交易日志为准
+type GridEvent struct {
+	TransactionID  string `json:"transaction_id"`
+	BlockNumber    int64  `json:"block_number"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	EventName      string `json:"event_name"`
+}
+
+type GridEventsResponse struct {
+	Data []GridEvent `json:"data"`
+	Meta GridMeta    `json:"meta"`
+}
+
 // TronScan API响应结构
 type TronScanAPIResponse struct {
 	WithPriceTokens []TronScanToken `json:"withPriceTokens"`
@@ -84,3 +895,62 @@ type TronScanToken struct {
 	TokenPriceInTrx float64     `json:"tokenPriceInTrx"`
 	Amount          interface{} `json:"amount"` // 可能是string或float64
 }
+
+// 充值监控地址
+type WatchAddress struct {
+	ID      string   `json:"id"`
+	Address string   `json:"address"`
+	Tokens  []string `json:"tokens,omitempty"` // 只通知这些代币(TRX、TRC10代币ID或TRC20合约地址)，为空时通知全部
+	Webhook string   `json:"webhook"`
+	Secret  string   `json:"secret,omitempty"` // Webhook签名密钥，仅在添加时返回
+	Label   string   `json:"label,omitempty"`
+	// 是否在区块固化前通知(unconfirmed)，并在区块因分叉回滚时通知(reverted)
+	Unconfirmed bool  `json:"unconfirmed,omitempty"`
+	CreatedAt   int64 `json:"createdAt"`
+}
+
+// 充值通知事件
+type DepositEvent struct {
+	ID       string   `json:"id"`     // txID-代币类型-序号，同一笔转账的各状态通知相同
+	Type     string   `json:"type"`   // deposit
+	Status   string   `json:"status"` // unconfirmed | confirmed | reverted
+	WatchID  string   `json:"watchId"`
+	Address  string   `json:"address"`
+	Label    string   `json:"label,omitempty"`
+	Transfer Transfer `json:"transfer"`
+}
+
+// Webhook投递记录
+type WebhookDelivery struct {
+	ID            string          `json:"id"`
+	WatchID       string          `json:"watchId"`
+	EventID       string          `json:"eventId"`
+	URL           string          `json:"url"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"` // pending | delivered | failed
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt int64           `json:"nextAttemptAt,omitempty"`
+	ResponseCode  int             `json:"responseCode,omitempty"`
+	LastError     string          `json:"lastError,omitempty"`
+	CreatedAt     int64           `json:"createdAt"`
+	DeliveredAt   int64           `json:"deliveredAt,omitempty"`
+}
+
+// 区块扫描状态
+type ScannerStatus struct {
+	Final   int64  `json:"final"`   // 已固化并处理完成的最高区块
+	Tip     int64  `json:"tip"`     // 已处理的最高区块
+	Head    int64  `json:"head"`    // 节点最新区块
+	Solid   int64  `json:"solid"`   // 节点最新固化区块
+	Pending int    `json:"pending"` // 已处理但尚未固化的区块数
+	Error   string `json:"error,omitempty"`
+
+	ForkResets    int   `json:"forkResets,omitempty"`    // 分叉深度超过已固化区块而重置进度的次数
+	LastForkReset int64 `json:"lastForkReset,omitempty"` // 最近一次重置的时间
+}
+
+// 充值监控状态
+type WatchStatus struct {
+	Scanner   ScannerStatus   `json:"scanner"`
+	Addresses []*WatchAddress `json:"addresses"`
+}
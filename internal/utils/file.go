@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// 将数据以JSON格式写入文件，先写临时文件再重命名，避免写入中断导致文件损坏
+func WriteJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// 从JSON文件读取数据
+func ReadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
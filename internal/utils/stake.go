@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"tron-api-go/internal/amount"
+	"tron-api-go/internal/types"
+)
+
+// Stake 2.0 资源类型
+const (
+	ResourceBandwidth = "BANDWIDTH"
+	ResourceEnergy    = "ENERGY"
+)
+
+// 校验资源类型，默认为ENERGY
+func NormalizeResource(resource string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(resource)) {
+	case "", ResourceEnergy:
+		return ResourceEnergy, nil
+	case ResourceBandwidth:
+		return ResourceBandwidth, nil
+	default:
+		return "", errors.New("资源类型必须为ENERGY或BANDWIDTH")
+	}
+}
+
+// 节点JSON中省略默认枚举值，BANDWIDTH不会出现在type字段中
+func resourceName(t string) string {
+	if t == "" {
+		return ResourceBandwidth
+	}
+	return t
+}
+
+// 构建质押交易(FreezeBalanceV2Contract)
+func FreezeBalanceV2(config *types.Config, owner string, amount int64, resource string, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address":  owner,
+		"frozen_balance": amount,
+		"resource":       resource,
+	}
+	return BuildTransaction(config, "/wallet/freezebalancev2", payload, permissionID)
+}
+
+// 构建解锁交易(UnfreezeBalanceV2Contract)
+func UnfreezeBalanceV2(config *types.Config, owner string, amount int64, resource string, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address":    owner,
+		"unfreeze_balance": amount,
+		"resource":         resource,
+	}
+	return BuildTransaction(config, "/wallet/unfreezebalancev2", payload, permissionID)
+}
+
+// 构建提取已到期解锁金额交易(WithdrawExpireUnfreezeContract)
+func WithdrawExpireUnfreeze(config *types.Config, owner string, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address": owner,
+	}
+	return BuildTransaction(config, "/wallet/withdrawexpireunfreeze", payload, permissionID)
+}
+
+// 构建取消全部解锁交易(CancelAllUnfreezeV2Contract)
+func CancelAllUnfreezeV2(config *types.Config, owner string, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address": owner,
+	}
+	return BuildTransaction(config, "/wallet/cancelallunfreezev2", payload, permissionID)
+}
+
+// 查询账户质押及解锁状态
+func GetUnfreezeInfo(config *types.Config, address string) (*types.UnfreezeInfoResponse, error) {
+	var account types.StakeAccount
+	payload := map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getaccount", payload, &account); err != nil {
+		return nil, err
+	}
+	if account.Address == "" {
+		return nil, errors.New("账户未激活")
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	info := &types.UnfreezeInfoResponse{
+		Address: address,
+		Frozen:  []types.StakeAmount{},
+		Pending: []types.PendingUnfreeze{},
+	}
+
+	for _, f := range account.FrozenV2 {
+		// TRON_POWER为投票权，不属于资源质押
+		if f.Amount == 0 || f.Type == "TRON_POWER" {
+			continue
+		}
+		info.Frozen = append(info.Frozen, types.StakeAmount{
+			Resource:  resourceName(f.Type),
+			Amount:    amount.Sun(f.Amount).String(),
+			AmountSun: f.Amount,
+		})
+	}
+
+	for _, u := range account.UnfrozenV2 {
+		withdrawable := u.UnfreezeExpireTime <= now
+		info.Pending = append(info.Pending, types.PendingUnfreeze{
+			Resource:     resourceName(u.Type),
+			Amount:       amount.Sun(u.UnfreezeAmount).String(),
+			AmountSun:    u.UnfreezeAmount,
+			ExpireTime:   u.UnfreezeExpireTime,
+			ExpireDate:   time.Unix(u.UnfreezeExpireTime/1000, 0).Format("2006-01-02 15:04:05"),
+			Withdrawable: withdrawable,
+		})
+		if withdrawable {
+			info.WithdrawableAmountSun += u.UnfreezeAmount
+		} else if info.NextWithdrawTime == 0 || u.UnfreezeExpireTime < info.NextWithdrawTime {
+			info.NextWithdrawTime = u.UnfreezeExpireTime
+		}
+	}
+
+	// 以节点计算的可提取金额为准
+	var withdraw struct {
+		Amount int64 `json:"amount"`
+	}
+	withdrawPayload := map[string]interface{}{
+		"owner_address": address,
+		"timestamp":     now,
+		"visible":       true,
+	}
+	if err := WalletPost(config, "/wallet/getcanwithdrawunfreezeamount", withdrawPayload, &withdraw); err == nil {
+		info.WithdrawableAmountSun = withdraw.Amount
+	}
+	info.WithdrawableAmount = amount.Sun(info.WithdrawableAmountSun).String()
+
+	var count struct {
+		Count int64 `json:"count"`
+	}
+	countPayload := map[string]interface{}{
+		"owner_address": address,
+		"visible":       true,
+	}
+	if err := WalletPost(config, "/wallet/getavailableunfreezecount", countPayload, &count); err != nil {
+		return nil, err
+	}
+	info.AvailableUnfreezeCount = count.Count
+
+	return info, nil
+}
+
+// 构建资源代理交易(DelegateResourceContract)，lockPeriod单位为区块(约3秒)
+func DelegateResource(config *types.Config, owner, receiver string, amount int64, resource string, lockPeriod int64, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address":    owner,
+		"receiver_address": receiver,
+		"balance":          amount,
+		"resource":         resource,
+		"lock":             lockPeriod > 0,
+	}
+	if lockPeriod > 0 {
+		payload["lock_period"] = lockPeriod
+	}
+	return BuildTransaction(config, "/wallet/delegateresource", payload, permissionID)
+}
+
+// 构建取消资源代理交易(UnDelegateResourceContract)
+func UnDelegateResource(config *types.Config, owner, receiver string, amount int64, resource string, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address":    owner,
+		"receiver_address": receiver,
+		"balance":          amount,
+		"resource":         resource,
+	}
+	return BuildTransaction(config, "/wallet/undelegateresource", payload, permissionID)
+}
+
+// 查询两个地址之间的资源代理记录
+func GetDelegatedResource(config *types.Config, from, to string) ([]types.DelegatedResource, error) {
+	var resp struct {
+		DelegatedResource []types.DelegatedResource `json:"delegatedResource"`
+	}
+	payload := map[string]interface{}{
+		"fromAddress": from,
+		"toAddress":   to,
+		"visible":     true,
+	}
+	if err := WalletPost(config, "/wallet/getdelegatedresourcev2", payload, &resp); err != nil {
+		return nil, err
+	}
+	return resp.DelegatedResource, nil
+}
+
+// 查询地址代理出去和接收到的全部资源
+func GetDelegationInfo(config *types.Config, address string) (*types.DelegationInfoResponse, error) {
+	var index struct {
+		FromAccounts []string `json:"fromAccounts"`
+		ToAccounts   []string `json:"toAccounts"`
+	}
+	payload := map[string]interface{}{
+		"value":   address,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getdelegatedresourceaccountindexv2", payload, &index); err != nil {
+		return nil, err
+	}
+
+	info := &types.DelegationInfoResponse{
+		Address:      address,
+		DelegatedTo:  []types.DelegatedResource{},
+		ReceivedFrom: []types.DelegatedResource{},
+	}
+
+	for _, to := range index.ToAccounts {
+		records, err := GetDelegatedResource(config, address, to)
+		if err != nil {
+			return nil, err
+		}
+		info.DelegatedTo = append(info.DelegatedTo, records...)
+	}
+	for _, from := range index.FromAccounts {
+		records, err := GetDelegatedResource(config, from, address)
+		if err != nil {
+			return nil, err
+		}
+		info.ReceivedFrom = append(info.ReceivedFrom, records...)
+	}
+
+	return info, nil
+}
+
+// 查询可代理的资源上限(SUN)
+func GetCanDelegatedMaxSize(config *types.Config, address, resource string) (int64, error) {
+	resourceType := 0
+	if resource == ResourceEnergy {
+		resourceType = 1
+	}
+
+	var resp struct {
+		MaxSize int64 `json:"max_size"`
+	}
+	payload := map[string]interface{}{
+		"owner_address": address,
+		"type":          resourceType,
+		"visible":       true,
+	}
+	if err := WalletPost(config, "/wallet/getcandelegatedmaxsize", payload, &resp); err != nil {
+		return 0, err
+	}
+	return resp.MaxSize, nil
+}
@@ -3,16 +3,14 @@ package utils
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"math/big"
 	"net"
 	"net/http"
 	"os/exec"
 	"runtime"
-	"strings"
 	"time"
 
+	"tron-api-go/internal/amount"
 	"tron-api-go/internal/types"
 
 	"github.com/gin-gonic/gin"
@@ -29,25 +27,6 @@ func GenerateTronAddress() string {
 	return address
 }
 
-// 辅助函数：生成模拟助记词
-func GenerateMnemonic() string {
-	words := []string{
-		"abandon", "ability", "able", "about", "above", "absent", "absorb", "abstract",
-		"absurd", "abuse", "access", "accident", "account", "accuse", "achieve", "acid",
-		"acoustic", "acquire", "across", "act", "action", "actor", "actress", "actual",
-	}
-
-	var mnemonic []string
-	for i := 0; i < 12; i++ {
-		randomBytes := make([]byte, 1)
-		rand.Read(randomBytes)
-		index := int(randomBytes[0]) % len(words)
-		mnemonic = append(mnemonic, words[index])
-	}
-
-	return strings.Join(mnemonic, " ")
-}
-
 // 辅助函数：生成交易ID
 func GenerateTxId() string {
 	// 生成随机的64位十六进制字符串作为交易ID
@@ -56,6 +35,19 @@ func GenerateTxId() string {
 	return hex.EncodeToString(bytes)
 }
 
+// 辅助函数：是否为非空的十进制数字串(仅0-9)，用于区分区块号、TRC10代币ID等数字参数
+func IsDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // 辅助函数：获取基础URL
 func GetBaseURL(c *gin.Context) string {
 	scheme := "http"
@@ -185,69 +177,26 @@ func OpenBrowser(url string) {
 }
 
 // 查询TRX真实余额
-func GetTronBalance(address string, config *types.Config) (string, error) {
-	url := fmt.Sprintf("%s/v1/accounts/%s", config.TronAPIURL, address)
-
-	resp, err := http.Get(url)
+func GetTronBalance(address string, config *types.Config) (amount.Amount, error) {
+	sun, err := Chain(config).Balance(address)
 	if err != nil {
-		return "", fmt.Errorf("请求TRON API失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	var apiResp types.TronAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("解析API响应失败: %v", err)
-	}
-
-	if !apiResp.Success || len(apiResp.Data) == 0 {
-		return "0", nil
+		return amount.Amount{}, err
 	}
-
-	// 将SUN转换为TRX (1 TRX = 1,000,000 SUN)
-	balance := apiResp.Data[0].Balance
-	if balance == nil {
-		return "0", nil
-	}
-
-	trxBalance := new(big.Float).Quo(new(big.Float).SetInt(balance), big.NewFloat(1000000))
-	return trxBalance.Text('f', 6), nil
+	return amount.Sun(sun), nil
 }
 
-// 查询TRC20真实余额
-func GetTrc20Balance(address, contractAddress string) (string, error) {
-	// 使用TronScan API查询账户信息
-	url := fmt.Sprintf("https://apilist.tronscanapi.com/api/accountv2?address=%s", address)
-
-	resp, err := http.Get(url)
+// 查询TRC20真实余额(调用合约balanceOf)
+func GetTrc20Balance(config *types.Config, address, contractAddress string) (amount.Amount, error) {
+	decimals, err := GetTokenDecimals(config, contractAddress)
 	if err != nil {
-		return "", fmt.Errorf("请求TronScan API失败: %v", err)
+		return amount.Amount{}, err
 	}
-	defer resp.Body.Close()
 
-	var apiResp types.TronScanAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("解析API响应失败: %v", err)
-	}
-
-	// 查找指定合约的代币余额
-	for _, token := range apiResp.WithPriceTokens {
-		if strings.EqualFold(token.TokenId, contractAddress) && token.TokenType == "trc20" {
-			// 将余额转换为正确的小数位数
-			if token.TokenDecimal > 0 {
-				balance, ok := new(big.Int).SetString(token.Balance, 10)
-				if !ok {
-					return "0", nil
-				}
-
-				divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.TokenDecimal)), nil)
-				result := new(big.Float).Quo(new(big.Float).SetInt(balance), new(big.Float).SetInt(divisor))
-				return result.Text('f', token.TokenDecimal), nil
-			}
-			return token.Balance, nil
-		}
+	balance, err := GetTrc20BalanceRaw(config, address, contractAddress)
+	if err != nil {
+		return amount.Amount{}, err
 	}
-
-	return "0", nil
+	return amount.New(balance, decimals), nil
 }
 
 // CORS中间件
@@ -255,7 +204,7 @@ func CorsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
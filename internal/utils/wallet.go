@@ -0,0 +1,1033 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"tron-api-go/internal/chain"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+)
+
+// 配置对应的链访问客户端，未设置时按TronAPIURL使用TronGrid
+func Chain(config *types.Config) types.ChainClient {
+	if config.Chain != nil {
+		return config.Chain
+	}
+	return chain.NewTronGrid(config.TronAPIURL)
+}
+
+// 调用TRON节点 /wallet 接口
+func WalletPost(config *types.Config, path string, payload interface{}, out interface{}) error {
+	return Chain(config).Wallet(path, payload, out)
+}
+
+// 节点错误信息通常为十六进制编码
+func decodeNodeMessage(msg string) string {
+	if b, err := hex.DecodeString(msg); err == nil && len(b) > 0 {
+		return string(b)
+	}
+	return msg
+}
+
+// 调用节点交易构建接口，返回未签名交易
+func BuildTransaction(config *types.Config, path string, payload map[string]interface{}, permissionID int) (*types.Transaction, error) {
+	payload["visible"] = true
+	if permissionID > 0 {
+		payload["Permission_id"] = permissionID
+	}
+
+	var tx types.Transaction
+	if err := WalletPost(config, path, payload, &tx); err != nil {
+		return nil, err
+	}
+	return checkBuilt(&tx, path, payload, permissionID)
+}
+
+// 构建TRX转账交易
+func CreateTrxTransaction(config *types.Config, owner, to string, amount int64, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address": owner,
+		"to_address":    to,
+		"amount":        amount,
+	}
+	return BuildTransaction(config, "/wallet/createtransaction", payload, permissionID)
+}
+
+// 构建TRC10转账交易
+func CreateTrc10Transaction(config *types.Config, owner, to, tokenID string, amount int64, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address": owner,
+		"to_address":    to,
+		"asset_name":    tokenID,
+		"amount":        amount,
+	}
+	return BuildTransaction(config, "/wallet/transferasset", payload, permissionID)
+}
+
+// 构建智能合约调用交易
+func TriggerSmartContract(config *types.Config, owner, contract, selector, parameter string, callValue int64, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address":     owner,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"fee_limit":         config.FeeLimit,
+		"call_value":        callValue,
+	}
+	return BuildContractTransaction(config, payload, permissionID)
+}
+
+// 调用节点 triggersmartcontract 接口，返回未签名交易
+func BuildContractTransaction(config *types.Config, payload map[string]interface{}, permissionID int) (*types.Transaction, error) {
+	payload["visible"] = true
+	if permissionID > 0 {
+		payload["Permission_id"] = permissionID
+	}
+
+	var resp struct {
+		Result struct {
+			Result  bool   `json:"result"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		Transaction types.Transaction `json:"transaction"`
+	}
+	if err := WalletPost(config, "/wallet/triggersmartcontract", payload, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Result.Result {
+		return nil, fmt.Errorf("%s: %s", resp.Result.Code, decodeNodeMessage(resp.Result.Message))
+	}
+	return checkBuilt(&resp.Transaction, "/wallet/triggersmartcontract", payload, permissionID)
+}
+
+// 构建合约部署交易(CreateSmartContract)，返回未签名交易及节点计算的合约地址
+func DeployContract(config *types.Config, payload map[string]interface{}, permissionID int) (*types.Transaction, string, error) {
+	payload["visible"] = true
+	if permissionID > 0 {
+		payload["Permission_id"] = permissionID
+	}
+
+	var resp struct {
+		types.Transaction
+		ContractAddress string `json:"contract_address"`
+	}
+	if err := WalletPost(config, "/wallet/deploycontract", payload, &resp); err != nil {
+		return nil, "", err
+	}
+	tx, err := checkBuilt(&resp.Transaction, "/wallet/deploycontract", payload, permissionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	addr, err := tron.ParseAddress(resp.ContractAddress)
+	if err != nil {
+		return nil, "", errors.New("节点未返回合约地址")
+	}
+	return tx, addr.Base58(), nil
+}
+
+// 校验节点返回的交易，确保txID与raw_data_hex一致
+func checkTransaction(tx *types.Transaction) (*types.Transaction, error) {
+	if tx.TxID == "" || tx.RawDataHex == "" {
+		return nil, errors.New("节点未返回有效交易")
+	}
+
+	txID, err := tron.TxIDFromRawData(tx.RawDataHex)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(txID, tx.TxID) {
+		return nil, errors.New("交易ID与交易数据不匹配")
+	}
+	return tx, nil
+}
+
+// 交易构建接口对应的合约类型，及请求参数在合约参数protobuf中的字段编号和类型
+var builtContracts = map[string]struct {
+	kind   string
+	fields map[string]paramField
+}{
+	"/wallet/createtransaction": {"TransferContract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "to_address": {2, paramAddress}, "amount": {3, paramInt},
+	}},
+	"/wallet/transferasset": {"TransferAssetContract", map[string]paramField{
+		"asset_name": {1, paramString}, "owner_address": {2, paramAddress}, "to_address": {3, paramAddress}, "amount": {4, paramInt},
+	}},
+	"/wallet/triggersmartcontract": {"TriggerSmartContract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "contract_address": {2, paramAddress}, "call_value": {3, paramInt},
+		"call_token_value": {5, paramInt}, "token_id": {6, paramInt},
+	}},
+	"/wallet/deploycontract": {"CreateSmartContract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "call_token_value": {3, paramInt}, "token_id": {4, paramInt},
+	}},
+	"/wallet/accountpermissionupdate": {"AccountPermissionUpdateContract", map[string]paramField{
+		"owner_address": {1, paramAddress},
+	}},
+	"/wallet/freezebalancev2": {"FreezeBalanceV2Contract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "frozen_balance": {2, paramInt}, "resource": {3, paramResource},
+	}},
+	"/wallet/unfreezebalancev2": {"UnfreezeBalanceV2Contract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "unfreeze_balance": {2, paramInt}, "resource": {3, paramResource},
+	}},
+	"/wallet/withdrawexpireunfreeze": {"WithdrawExpireUnfreezeContract", map[string]paramField{
+		"owner_address": {1, paramAddress},
+	}},
+	"/wallet/cancelallunfreezev2": {"CancelAllUnfreezeV2Contract", map[string]paramField{
+		"owner_address": {1, paramAddress},
+	}},
+	"/wallet/delegateresource": {"DelegateResourceContract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "resource": {2, paramResource}, "balance": {3, paramInt},
+		"receiver_address": {4, paramAddress}, "lock": {5, paramBool}, "lock_period": {6, paramInt},
+	}},
+	"/wallet/undelegateresource": {"UnDelegateResourceContract", map[string]paramField{
+		"owner_address": {1, paramAddress}, "resource": {2, paramResource}, "balance": {3, paramInt},
+		"receiver_address": {4, paramAddress},
+	}},
+}
+
+type paramField struct {
+	num  int
+	kind int
+}
+
+// 请求参数类型
+const (
+	paramAddress = iota
+	paramInt
+	paramBool
+	paramString
+	paramResource
+)
+
+// 资源类型枚举(java-tron ResourceCode)
+var resourceCodes = map[string]uint64{ResourceBandwidth: 0, ResourceEnergy: 1, "TRON_POWER": 2}
+
+// 校验节点返回的交易ID，并确认签名的raw_data_hex与请求一致
+func checkBuilt(tx *types.Transaction, path string, payload map[string]interface{}, permissionID int) (*types.Transaction, error) {
+	if _, err := checkTransaction(tx); err != nil {
+		return nil, err
+	}
+	if err := verifyTransaction(tx, path, payload, permissionID); err != nil {
+		return nil, fmt.Errorf("节点返回的交易与请求不一致: %v", err)
+	}
+	return tx, nil
+}
+
+// 解码raw_data_hex，逐项比对合约类型、权限ID、地址、金额、合约调用数据、部署参数、权限配置、手续费上限和备注。
+// 签名的是raw_data_hex，节点返回的raw_data JSON不可信，不参与校验
+func verifyTransaction(tx *types.Transaction, path string, payload map[string]interface{}, permissionID int) error {
+	spec, ok := builtContracts[path]
+	if !ok {
+		return fmt.Errorf("未知的交易构建接口 %s", path)
+	}
+	raw, err := tron.DecodeRawData(tx.RawDataHex)
+	if err != nil {
+		return err
+	}
+	if len(raw.Contracts) != 1 {
+		return fmt.Errorf("交易包含 %d 个合约", len(raw.Contracts))
+	}
+	c := raw.Contracts[0]
+	if c.Type != tron.ContractTypes[spec.kind] {
+		return fmt.Errorf("合约类型为 %s，应为 %s", tron.ContractTypeName(c.Type), spec.kind)
+	}
+	if c.PermissionID != permissionID {
+		return fmt.Errorf("权限ID为 %d，应为 %d", c.PermissionID, permissionID)
+	}
+
+	fields, err := tron.DecodeFields(c.Value)
+	if err != nil {
+		return fmt.Errorf("合约参数解码失败: %v", err)
+	}
+	for key, f := range spec.fields {
+		want, err := f.expect(payload[key])
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		got := fields[f.num]
+		if got.Varint != want.Varint || !bytes.Equal(got.Bytes, want.Bytes) {
+			return fmt.Errorf("%s 不一致", key)
+		}
+	}
+
+	switch spec.kind {
+	case "TriggerSmartContract":
+		data, err := callData(payload)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(fields[4].Bytes, data) {
+			return errors.New("合约调用数据不一致")
+		}
+	case "CreateSmartContract":
+		// new_contract(SmartContract)中的bytecode和call_value
+		contract, err := tron.DecodeFields(fields[2].Bytes)
+		if err != nil {
+			return fmt.Errorf("合约参数解码失败: %v", err)
+		}
+		code, _ := payload["bytecode"].(string)
+		bytecode, err := hex.DecodeString(strings.TrimPrefix(code, "0x"))
+		if err != nil || !bytes.Equal(contract[4].Bytes, bytecode) {
+			return errors.New("合约字节码不一致")
+		}
+		callValue, err := paramField{kind: paramInt}.expect(payload["call_value"])
+		if err != nil || contract[5].Varint != callValue.Varint {
+			return errors.New("call_value 不一致")
+		}
+		if !bytes.Equal(contract[1].Bytes, fields[1].Bytes) {
+			return errors.New("origin_address 不一致")
+		}
+		for key, f := range map[string]paramField{
+			"consume_user_resource_percent": {6, paramInt}, "name": {7, paramString}, "origin_energy_limit": {8, paramInt},
+		} {
+			want, err := f.expect(payload[key])
+			if err != nil || contract[f.num].Varint != want.Varint || !bytes.Equal(contract[f.num].Bytes, want.Bytes) {
+				return fmt.Errorf("%s 不一致", key)
+			}
+		}
+		abiJSON, _ := payload["abi"].(string)
+		if err := verifyABI(contract[3].Bytes, abiJSON); err != nil {
+			return err
+		}
+	case "AccountPermissionUpdateContract":
+		if err := verifyPermissions(c.Value, payload); err != nil {
+			return err
+		}
+	}
+	if limit, ok := payload["fee_limit"]; ok {
+		want, err := toInt64(limit)
+		if err != nil {
+			return fmt.Errorf("fee_limit: %v", err)
+		}
+		if raw.FeeLimit > want {
+			return fmt.Errorf("fee_limit 为 %d，超过请求的 %d", raw.FeeLimit, want)
+		}
+	}
+	memo, _ := payload["extra_data"].(string)
+	if !bytes.Equal(raw.Data, []byte(memo)) {
+		return errors.New("备注不一致")
+	}
+	return nil
+}
+
+// 请求参数在合约参数中应有的编码值，未设置的参数为零值
+func (f paramField) expect(v interface{}) (tron.Field, error) {
+	if v == nil {
+		return tron.Field{}, nil
+	}
+	switch f.kind {
+	case paramAddress:
+		s, _ := v.(string)
+		addr, err := tron.ParseAddress(s)
+		if err != nil {
+			return tron.Field{}, err
+		}
+		return tron.Field{Bytes: addr[:]}, nil
+	case paramInt:
+		n, err := toInt64(v)
+		return tron.Field{Varint: uint64(n)}, err
+	case paramBool:
+		if b, _ := v.(bool); b {
+			return tron.Field{Varint: 1}, nil
+		}
+		return tron.Field{}, nil
+	case paramResource:
+		s, _ := v.(string)
+		code, ok := resourceCodes[strings.ToUpper(s)]
+		if !ok {
+			return tron.Field{}, fmt.Errorf("未知的资源类型 %s", s)
+		}
+		return tron.Field{Varint: code}, nil
+	}
+	s, _ := v.(string)
+	if s == "" {
+		return tron.Field{}, nil
+	}
+	return tron.Field{Bytes: []byte(s)}, nil
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	}
+	return 0, fmt.Errorf("不是整数: %v", v)
+}
+
+// 合约调用数据：function_selector哈希的前4字节与parameter拼接，或直接使用data
+func callData(payload map[string]interface{}) ([]byte, error) {
+	selector, _ := payload["function_selector"].(string)
+	parameter, _ := payload["parameter"].(string)
+	if selector == "" {
+		data, _ := payload["data"].(string)
+		parameter = data
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(parameter, "0x"))
+	if err != nil {
+		return nil, errors.New("parameter 不是有效的十六进制")
+	}
+	if selector == "" {
+		return b, nil
+	}
+	return append(tron.Keccak256([]byte(selector))[:4], b...), nil
+}
+
+// 合约ABI条目，只包含节点保存的字段(java-tron SmartContract.ABI.Entry)
+type abiEntry struct {
+	Anonymous       bool       `json:"anonymous"`
+	Constant        bool       `json:"constant"`
+	Name            string     `json:"name"`
+	Inputs          []abiParam `json:"inputs"`
+	Outputs         []abiParam `json:"outputs"`
+	Type            string     `json:"type"`
+	Payable         bool       `json:"payable"`
+	StateMutability string     `json:"stateMutability"`
+}
+
+type abiParam struct {
+	Indexed bool   `json:"indexed"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+}
+
+// ABI条目类型和状态可变性枚举，节点解析时不区分大小写
+var (
+	abiEntryTypes      = map[string]uint64{"constructor": 1, "function": 2, "event": 3, "fallback": 4, "receive": 5, "error": 6}
+	abiStateMutability = map[string]uint64{"pure": 1, "view": 2, "nonpayable": 3, "payable": 4}
+)
+
+// 逐条比对new_contract.abi(字段3)与请求的ABI：
+// abi=1 下的entrys按顺序对应，条目字段 anonymous=1 constant=2 name=3 inputs=4 outputs=5 type=6 payable=7 stateMutability=8
+func verifyABI(data []byte, abiJSON string) error {
+	var want []abiEntry
+	if err := json.Unmarshal([]byte(abiJSON), &want); err != nil {
+		return fmt.Errorf("ABI解析失败: %v", err)
+	}
+	got, err := tron.RepeatedField(data, 1)
+	if err != nil {
+		return fmt.Errorf("ABI解码失败: %v", err)
+	}
+	if len(got) != len(want) {
+		return fmt.Errorf("ABI条目数量为 %d，应为 %d", len(got), len(want))
+	}
+
+	for i, e := range want {
+		fields, err := tron.DecodeFields(got[i])
+		if err != nil {
+			return fmt.Errorf("ABI解码失败: %v", err)
+		}
+		if fields[1].Varint != boolVarint(e.Anonymous) || fields[2].Varint != boolVarint(e.Constant) ||
+			string(fields[3].Bytes) != e.Name || fields[7].Varint != boolVarint(e.Payable) ||
+			fields[6].Varint != abiEntryTypes[strings.ToLower(e.Type)] ||
+			fields[8].Varint != abiStateMutability[strings.ToLower(e.StateMutability)] {
+			return fmt.Errorf("ABI条目 %s 不一致", e.Name)
+		}
+		for num, params := range map[int][]abiParam{4: e.Inputs, 5: e.Outputs} {
+			values, err := tron.RepeatedField(got[i], num)
+			if err != nil || len(values) != len(params) {
+				return fmt.Errorf("ABI条目 %s 的参数不一致", e.Name)
+			}
+			for j, p := range params {
+				f, err := tron.DecodeFields(values[j])
+				if err != nil || f[1].Varint != boolVarint(p.Indexed) || string(f[2].Bytes) != p.Name || string(f[3].Bytes) != p.Type {
+					return fmt.Errorf("ABI条目 %s 的参数不一致", e.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func boolVarint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// 权限类型枚举(java-tron Permission.PermissionType)
+var permissionTypes = map[string]uint64{"Owner": 0, "Witness": 1, "Active": 2}
+
+// 比对权限更新合约中的owner(字段2)、witness(字段3)和actives(字段4)，
+// 节点替换任何密钥、权重、阈值或操作都会使编码不同
+func verifyPermissions(value []byte, payload map[string]interface{}) error {
+	fields, err := tron.DecodeFields(value)
+	if err != nil {
+		return fmt.Errorf("合约参数解码失败: %v", err)
+	}
+
+	owner, _ := payload["owner"].(*types.Permission)
+	if owner == nil {
+		return errors.New("缺少owner权限")
+	}
+	want, err := encodePermission(owner)
+	if err != nil {
+		return fmt.Errorf("owner: %v", err)
+	}
+	if !bytes.Equal(fields[2].Bytes, want) {
+		return errors.New("owner权限不一致")
+	}
+
+	// 本服务不设置witness权限
+	if witness, err := tron.RepeatedField(value, 3); err != nil || len(witness) > 0 {
+		return errors.New("交易包含未请求的witness权限")
+	}
+
+	actives, _ := payload["actives"].([]types.Permission)
+	got, err := tron.RepeatedField(value, 4)
+	if err != nil {
+		return fmt.Errorf("合约参数解码失败: %v", err)
+	}
+	if len(got) != len(actives) {
+		return fmt.Errorf("active权限数量为 %d，应为 %d", len(got), len(actives))
+	}
+	for i := range actives {
+		want, err := encodePermission(&actives[i])
+		if err != nil {
+			return fmt.Errorf("actives[%d]: %v", i, err)
+		}
+		if !bytes.Equal(got[i], want) {
+			return fmt.Errorf("active权限 %s 不一致", actives[i].PermissionName)
+		}
+	}
+	return nil
+}
+
+// 按java-tron Permission消息编码权限：type=1 id=2 permission_name=3 threshold=4 operations=6 keys=7
+func encodePermission(p *types.Permission) ([]byte, error) {
+	permType, ok := permissionTypes[p.Type]
+	if !ok {
+		return nil, fmt.Errorf("未知的权限类型 %s", p.Type)
+	}
+	operations, err := hex.DecodeString(p.Operations)
+	if err != nil {
+		return nil, errors.New("operations 不是有效的十六进制")
+	}
+
+	var e tron.Encoder
+	e.Varint(1, permType)
+	e.Varint(2, uint64(p.ID))
+	e.Bytes(3, []byte(p.PermissionName))
+	e.Varint(4, uint64(p.Threshold))
+	e.Bytes(6, operations)
+	for _, k := range p.Keys {
+		addr, err := tron.ParseAddress(k.Address)
+		if err != nil {
+			return nil, err
+		}
+		var key tron.Encoder
+		key.Bytes(1, addr[:])
+		key.Varint(2, uint64(k.Weight))
+		e.Message(7, key.Result())
+	}
+	return e.Result(), nil
+}
+
+// 使用私钥为交易追加签名
+func SignTransaction(tx *types.Transaction, key *tron.PrivateKey) error {
+	if _, err := checkTransaction(tx); err != nil {
+		return err
+	}
+
+	signer := key.Address()
+	for _, sig := range tx.Signature {
+		if addr, err := tron.RecoverSigner(tx.TxID, sig); err == nil && addr == signer {
+			return fmt.Errorf("地址 %s 已签名", signer.Base58())
+		}
+	}
+
+	sig, err := key.SignTxID(tx.TxID)
+	if err != nil {
+		return err
+	}
+	tx.Signature = append(tx.Signature, sig)
+	return nil
+}
+
+// 查询交易当前签名权重
+func GetSignWeight(config *types.Config, tx *types.Transaction) (*types.SignWeight, error) {
+	var resp struct {
+		Result struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		Permission    *types.Permission `json:"permission"`
+		ApprovedList  []string          `json:"approved_list"`
+		CurrentWeight int64             `json:"current_weight"`
+	}
+	if err := WalletPost(config, "/wallet/getsignweight", tx, &resp); err != nil {
+		return nil, err
+	}
+
+	weight := &types.SignWeight{
+		Permission:    resp.Permission,
+		ApprovedList:  resp.ApprovedList,
+		CurrentWeight: resp.CurrentWeight,
+		ResultCode:    resp.Result.Code,
+		ResultMessage: decodeNodeMessage(resp.Result.Message),
+	}
+	if resp.Permission != nil {
+		weight.Threshold = resp.Permission.Threshold
+	}
+	weight.Enough = resp.Result.Code == "ENOUGH_PERMISSION" ||
+		(weight.Threshold > 0 && weight.CurrentWeight >= weight.Threshold)
+
+	return weight, nil
+}
+
+// 查询已对交易签名的地址列表
+func GetApprovedList(config *types.Config, tx *types.Transaction) ([]string, error) {
+	var resp struct {
+		Result struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		ApprovedList []string `json:"approved_list"`
+	}
+	if err := WalletPost(config, "/wallet/getapprovedlist", tx, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Result.Code != "" && resp.Result.Code != "SUCCESS" {
+		return nil, fmt.Errorf("%s: %s", resp.Result.Code, decodeNodeMessage(resp.Result.Message))
+	}
+	return resp.ApprovedList, nil
+}
+
+// 广播已签名交易
+func BroadcastTransaction(config *types.Config, tx *types.Transaction) (*types.BroadcastResult, error) {
+	var result types.BroadcastResult
+	if err := WalletPost(config, "/wallet/broadcasttransaction", tx, &result); err != nil {
+		return nil, err
+	}
+	if !result.Result {
+		return &result, fmt.Errorf("广播失败: %s %s", result.Code, decodeNodeMessage(result.Message))
+	}
+	if result.TxID == "" {
+		result.TxID = tx.TxID
+	}
+	return &result, nil
+}
+
+// 节点明确拒绝交易的错误码，交易未进入交易池，不会上链。
+// 其他错误(超时、节点繁忙、连接不足等)时交易可能已被节点接收并转发
+var rejectCodes = map[string]bool{
+	"SIGERROR":                     true,
+	"CONTRACT_VALIDATE_ERROR":      true,
+	"CONTRACT_EXE_ERROR":           true,
+	"BANDWITH_ERROR":               true,
+	"TAPOS_ERROR":                  true,
+	"TOO_BIG_TRANSACTION_ERROR":    true,
+	"TRANSACTION_EXPIRATION_ERROR": true,
+}
+
+// 广播是否被节点明确拒绝，result为BroadcastTransaction的返回值
+func BroadcastRejected(result *types.BroadcastResult) bool {
+	return result != nil && rejectCodes[result.Code]
+}
+
+// 查询账户当前权限配置
+func GetAccountPermissions(config *types.Config, address string) (*types.AccountPermissions, error) {
+	var account types.AccountPermissions
+	payload := map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getaccount", payload, &account); err != nil {
+		return nil, err
+	}
+	if account.Address == "" {
+		return nil, errors.New("账户未激活")
+	}
+	return &account, nil
+}
+
+// 构建账户权限更新交易
+func UpdateAccountPermission(config *types.Config, owner string, ownerPerm *types.Permission, actives []types.Permission, permissionID int) (*types.Transaction, error) {
+	payload := map[string]interface{}{
+		"owner_address": owner,
+		"owner":         ownerPerm,
+		"actives":       actives,
+	}
+	return BuildTransaction(config, "/wallet/accountpermissionupdate", payload, permissionID)
+}
+
+// 调用合约只读方法，返回constant_result
+func TriggerConstantContract(config *types.Config, owner, contract, selector, parameter string) ([]string, error) {
+	payload := map[string]interface{}{
+		"owner_address":     owner,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"visible":           true,
+	}
+
+	var resp struct {
+		Result struct {
+			Result  bool   `json:"result"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		ConstantResult []string `json:"constant_result"`
+	}
+	if err := WalletPost(config, "/wallet/triggerconstantcontract", payload, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Result.Result {
+		return nil, fmt.Errorf("%s: %s", resp.Result.Code, decodeNodeMessage(resp.Result.Message))
+	}
+	return resp.ConstantResult, nil
+}
+
+// 调用合约只读方法(payload为triggerconstantcontract请求)，返回执行结果、消耗能量及是否回滚
+func CallConstantContract(config *types.Config, payload map[string]interface{}) (*types.ConstantCallResult, error) {
+	payload["visible"] = true
+
+	var resp struct {
+		Result struct {
+			Result  bool   `json:"result"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		EnergyUsed     int64    `json:"energy_used"`
+		ConstantResult []string `json:"constant_result"`
+		Transaction    struct {
+			Ret []struct {
+				Ret string `json:"ret"`
+			} `json:"ret"`
+		} `json:"transaction"`
+	}
+	if err := WalletPost(config, "/wallet/triggerconstantcontract", payload, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Result.Result {
+		return nil, fmt.Errorf("%s: %s", resp.Result.Code, decodeNodeMessage(resp.Result.Message))
+	}
+
+	result := &types.ConstantCallResult{
+		EnergyUsed: resp.EnergyUsed,
+		Message:    decodeNodeMessage(resp.Result.Message),
+	}
+	if len(resp.ConstantResult) > 0 {
+		result.Result = resp.ConstantResult[0]
+	}
+	for _, ret := range resp.Transaction.Ret {
+		if ret.Ret != "" && ret.Ret != "SUCCESS" && ret.Ret != "SUCESS" {
+			result.Reverted = true
+		}
+	}
+	if strings.Contains(result.Message, "REVERT") {
+		result.Reverted = true
+	}
+	return result, nil
+}
+
+// 查询TRC20余额(代币最小单位)
+func GetTrc20BalanceRaw(config *types.Config, address, contract string) (*big.Int, error) {
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := TriggerConstantContract(config, address, contract, "balanceOf(address)", hex.EncodeToString(tron.EncodeAddressParam(addr)))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 || result[0] == "" {
+		return nil, errors.New("合约未返回余额")
+	}
+
+	balance, ok := new(big.Int).SetString(result[0], 16)
+	if !ok {
+		return nil, errors.New("合约返回的余额格式错误")
+	}
+	return balance, nil
+}
+
+// 查询TRC20代币精度，默认合约使用配置中的精度
+func GetTokenDecimals(config *types.Config, contract string) (int, error) {
+	addr, err := tron.ParseAddress(contract)
+	if err != nil {
+		return 0, errors.New("合约地址格式错误")
+	}
+	if addr.Base58() == config.ContractAddress {
+		return config.Decimals, nil
+	}
+
+	decimals, err := GetTrc20Decimals(config, addr.Base58())
+	if err != nil {
+		return 0, fmt.Errorf("查询代币精度失败: %v", err)
+	}
+	return decimals, nil
+}
+
+// 调用合约decimals()查询TRC20代币精度
+func GetTrc20Decimals(config *types.Config, contract string) (int, error) {
+	result, err := TriggerConstantContract(config, contract, contract, "decimals()", "")
+	if err != nil {
+		return 0, err
+	}
+	if len(result) == 0 || result[0] == "" {
+		return 0, errors.New("合约未返回精度")
+	}
+
+	decimals, ok := new(big.Int).SetString(result[0], 16)
+	if !ok || !decimals.IsInt64() || decimals.Int64() > 77 {
+		return 0, errors.New("合约返回的精度格式错误")
+	}
+	return int(decimals.Int64()), nil
+}
+
+// 查询账户TRX余额(SUN)，未激活账户返回0
+func GetAccountBalance(config *types.Config, address string) (int64, error) {
+	return Chain(config).Balance(address)
+}
+
+// 查询交易执行结果，交易尚未上链时返回nil
+func GetTransactionInfo(config *types.Config, txID string) (*types.TransactionInfo, error) {
+	var info types.TransactionInfo
+	if err := WalletPost(config, "/wallet/gettransactioninfobyid", map[string]string{"value": txID}, &info); err != nil {
+		return nil, err
+	}
+	if info.ID == "" {
+		return nil, nil
+	}
+	info.ResMessage = decodeNodeMessage(info.ResMessage)
+	return &info, nil
+}
+
+// 估算合约调用消耗的能量
+func EstimateEnergy(config *types.Config, owner, contract, selector, parameter string) (int64, error) {
+	payload := map[string]interface{}{
+		"owner_address":     owner,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"visible":           true,
+	}
+
+	var resp struct {
+		Result struct {
+			Result  bool   `json:"result"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		EnergyUsed int64 `json:"energy_used"`
+	}
+	if err := WalletPost(config, "/wallet/triggerconstantcontract", payload, &resp); err != nil {
+		return 0, err
+	}
+	if !resp.Result.Result {
+		return 0, fmt.Errorf("%s: %s", resp.Result.Code, decodeNodeMessage(resp.Result.Message))
+	}
+	return resp.EnergyUsed, nil
+}
+
+// 查询账户带宽和能量
+func GetAccountResource(config *types.Config, address string) (*types.AccountResource, error) {
+	var resource types.AccountResource
+	payload := map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getaccountresource", payload, &resource); err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// 查询链参数(如getEnergyFee、getTransactionFee)
+func GetChainParameters(config *types.Config) (map[string]int64, error) {
+	var resp struct {
+		ChainParameter []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"chainParameter"`
+	}
+	if err := WalletPost(config, "/wallet/getchainparameters", map[string]interface{}{}, &resp); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]int64, len(resp.ChainParameter))
+	for _, p := range resp.ChainParameter {
+		params[p.Key] = p.Value
+	}
+	return params, nil
+}
+
+// 查询TRC10代币信息
+func GetTrc10Token(config *types.Config, tokenID string) (*types.Trc10Token, error) {
+	var token types.Trc10Token
+	payload := map[string]interface{}{
+		"value":   tokenID,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getassetissuebyid", payload, &token); err != nil {
+		return nil, err
+	}
+	if token.ID == "" {
+		return nil, fmt.Errorf("TRC10代币 %s 不存在", tokenID)
+	}
+	return &token, nil
+}
+
+// 查询账户TRC10余额(代币最小单位)
+func GetTrc10BalanceRaw(config *types.Config, address, tokenID string) (int64, error) {
+	var account struct {
+		AssetV2 []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"assetV2"`
+	}
+	payload := map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getaccount", payload, &account); err != nil {
+		return 0, err
+	}
+	for _, asset := range account.AssetV2 {
+		if asset.Key == tokenID {
+			return asset.Value, nil
+		}
+	}
+	return 0, nil
+}
+
+// 查询固化节点上的交易执行结果，交易尚未固化时返回nil
+func GetSolidTransactionInfo(config *types.Config, txID string) (*types.TransactionInfo, error) {
+	var info types.TransactionInfo
+	if err := WalletPost(config, "/walletsolidity/gettransactioninfobyid", map[string]string{"value": txID}, &info); err != nil {
+		return nil, err
+	}
+	if info.ID == "" {
+		return nil, nil
+	}
+	info.ResMessage = decodeNodeMessage(info.ResMessage)
+	return &info, nil
+}
+
+// 查询已上链的交易，未找到时返回nil
+func GetTransactionByID(config *types.Config, txID string) (map[string]interface{}, error) {
+	var tx map[string]interface{}
+	payload := map[string]interface{}{
+		"value":   txID,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/gettransactionbyid", payload, &tx); err != nil {
+		return nil, err
+	}
+	if _, ok := tx["txID"]; !ok {
+		return nil, nil
+	}
+	return tx, nil
+}
+
+// 查询节点交易池中等待打包的交易，未找到时返回nil
+func GetPendingTransaction(config *types.Config, txID string) (*types.Transaction, error) {
+	var tx types.Transaction
+	payload := map[string]interface{}{
+		"value":   txID,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/gettransactionfrompending", payload, &tx); err != nil {
+		return nil, err
+	}
+	if tx.TxID == "" {
+		return nil, nil
+	}
+	return &tx, nil
+}
+
+// 查询最新区块高度，solid为true时查询最新固化区块
+func GetNowBlockNumber(config *types.Config, solid bool) (int64, error) {
+	path := "/wallet/getnowblock"
+	if solid {
+		path = "/walletsolidity/getnowblock"
+	}
+
+	var block struct {
+		BlockHeader struct {
+			RawData struct {
+				Number int64 `json:"number"`
+			} `json:"raw_data"`
+		} `json:"block_header"`
+	}
+	if err := WalletPost(config, path, map[string]interface{}{}, &block); err != nil {
+		return 0, err
+	}
+	return block.BlockHeader.RawData.Number, nil
+}
+
+// 按区块号查询区块，区块不存在时返回nil
+func GetBlockByNum(config *types.Config, num int64) (*types.NodeBlock, error) {
+	var block types.NodeBlock
+	payload := map[string]interface{}{
+		"num":     num,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getblockbynum", payload, &block); err != nil {
+		return nil, err
+	}
+	if block.BlockID == "" {
+		return nil, nil
+	}
+	return &block, nil
+}
+
+// 查询区块内全部交易的执行结果和事件日志
+func GetTransactionInfoByBlockNum(config *types.Config, num int64) ([]types.TransactionInfo, error) {
+	var infos []types.TransactionInfo
+	if err := WalletPost(config, "/wallet/gettransactioninfobyblocknum", map[string]int64{"num": num}, &infos); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// 查询合约在链上登记的ABI，合约不存在时返回错误，未上传ABI时返回nil
+func GetContractABI(config *types.Config, contract string) (json.RawMessage, error) {
+	var resp struct {
+		ContractAddress string          `json:"contract_address"`
+		ABI             json.RawMessage `json:"abi"`
+	}
+	payload := map[string]interface{}{
+		"value":   contract,
+		"visible": true,
+	}
+	if err := WalletPost(config, "/wallet/getcontract", payload, &resp); err != nil {
+		return nil, err
+	}
+	if resp.ContractAddress == "" {
+		return nil, fmt.Errorf("合约%s不存在", contract)
+	}
+	if len(resp.ABI) == 0 || string(resp.ABI) == "{}" {
+		return nil, nil
+	}
+	return resp.ABI, nil
+}
+
+// 交易过期时间(毫秒)，以签名的raw_data_hex为准，无法解析时返回0
+func TransactionExpiration(tx *types.Transaction) int64 {
+	if decoded, err := tron.DecodeRawData(tx.RawDataHex); err == nil && decoded.Expiration > 0 {
+		return decoded.Expiration
+	}
+	var raw struct {
+		Expiration int64 `json:"expiration"`
+	}
+	if err := json.Unmarshal(tx.RawData, &raw); err != nil {
+		return 0
+	}
+	return raw.Expiration
+}
@@ -0,0 +1,322 @@
+package watch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tron-api-go/internal/blocks"
+	"tron-api-go/internal/scanner"
+	"tron-api-go/internal/tron"
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 通知状态
+const (
+	eventUnconfirmed = "unconfirmed"
+	eventConfirmed   = "confirmed"
+	eventReverted    = "reverted"
+)
+
+// 单个地址最多注册的Webhook数
+const maxWatchesPerAddress = 10
+
+// 持久化的监控状态
+type state struct {
+	Addresses []*types.WatchAddress `json:"addresses"`
+}
+
+// 充值监控：通过区块扫描器跟随新区块，发现转入已注册地址的TRX、TRC10和TRC20转账后发送Webhook通知
+type Watcher struct {
+	config   *types.Config
+	dir      string
+	decimals *blocks.DecimalsCache
+	scanner  *scanner.Scanner
+
+	mu         sync.Mutex
+	state      state
+	deliveries []*types.WebhookDelivery
+	index      map[string]*types.WebhookDelivery
+	sending    map[string]bool // 正在投递的Webhook地址
+}
+
+// 创建充值监控，加载已注册地址和投递记录，并启动区块扫描和Webhook投递协程
+func NewWatcher(config *types.Config) *Watcher {
+	w := &Watcher{
+		config:   config,
+		dir:      filepath.Join(config.DataDir, "watch"),
+		decimals: blocks.NewDecimalsCache(config),
+		index:    make(map[string]*types.WebhookDelivery),
+		sending:  make(map[string]bool),
+	}
+	w.load()
+
+	w.scanner = scanner.New(config, scanner.Options{
+		Name:    "watch",
+		Handler: w,
+		Filter:  w.watched,
+		Idle:    w.idle,
+	})
+	w.scanner.Start()
+	go w.deliver()
+	return w
+}
+
+func (w *Watcher) statePath() string {
+	return filepath.Join(w.dir, "watch.json")
+}
+
+func (w *Watcher) load() {
+	utils.ReadJSONFile(w.statePath(), &w.state)
+	utils.ReadJSONFile(w.deliveriesPath(), &w.deliveries)
+	for _, d := range w.deliveries {
+		w.index[d.ID] = d
+	}
+}
+
+// 保存监控状态，调用方需持有锁
+func (w *Watcher) saveStateLocked() {
+	if err := utils.WriteJSONFile(w.statePath(), &w.state); err != nil {
+		fmt.Printf("⚠️  保存充值监控状态失败: %v\n", err)
+	}
+}
+
+// 复制地址信息，不含签名密钥
+func public(a *types.WatchAddress) *types.WatchAddress {
+	out := *a
+	out.Secret = ""
+	out.Tokens = append([]string(nil), a.Tokens...)
+	return &out
+}
+
+// 注册监控地址。secret为空时自动生成，仅在本次返回
+func (w *Watcher) Add(address, webhook, secret, label string, tokens []string, unconfirmed bool) (*types.WatchAddress, error) {
+	addr, err := tron.ParseAddress(address)
+	if err != nil {
+		return nil, errors.New("监控地址格式错误")
+	}
+
+	u, err := url.Parse(webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("webhook必须为http或https地址")
+	}
+
+	var normalized []string
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		switch {
+		case token == "":
+			continue
+		case strings.EqualFold(token, "TRX"):
+			token = "TRX"
+		case utils.IsDigits(token):
+		default:
+			contract, err := tron.ParseAddress(token)
+			if err != nil {
+				return nil, fmt.Errorf("代币 %s 格式错误", token)
+			}
+			token = contract.Base58()
+		}
+		normalized = append(normalized, token)
+	}
+
+	if secret == "" {
+		secret = randomHex(32)
+	}
+
+	watch := &types.WatchAddress{
+		ID:          "watch_" + randomHex(6),
+		Address:     addr.Base58(),
+		Tokens:      normalized,
+		Webhook:     webhook,
+		Secret:      secret,
+		Label:       label,
+		Unconfirmed: unconfirmed,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	count := 0
+	for _, a := range w.state.Addresses {
+		if a.Address == watch.Address {
+			count++
+		}
+	}
+	if count >= maxWatchesPerAddress {
+		return nil, fmt.Errorf("同一地址最多注册%d个Webhook", maxWatchesPerAddress)
+	}
+
+	w.state.Addresses = append(w.state.Addresses, watch)
+	w.saveStateLocked()
+
+	out := *watch
+	return &out, nil
+}
+
+// 删除监控地址，尚未投递的通知不再发送
+func (w *Watcher) Remove(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, a := range w.state.Addresses {
+		if a.ID == id {
+			w.state.Addresses = append(w.state.Addresses[:i], w.state.Addresses[i+1:]...)
+			w.saveStateLocked()
+			return nil
+		}
+	}
+	return errors.New("监控地址不存在")
+}
+
+// 查询监控状态和已注册地址
+func (w *Watcher) Status() *types.WatchStatus {
+	scan := w.scanner.Status()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := &types.WatchStatus{
+		Scanner:   scan,
+		Addresses: make([]*types.WatchAddress, 0, len(w.state.Addresses)),
+	}
+	for _, a := range w.state.Addresses {
+		status.Addresses = append(status.Addresses, public(a))
+	}
+	return status
+}
+
+// 是否没有需要监控的地址
+func (w *Watcher) idle() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.state.Addresses) == 0
+}
+
+// 只保留转入已注册地址的转账
+func (w *Watcher) watched(t *types.Transfer) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, a := range w.state.Addresses {
+		if a.Address == t.To {
+			return true
+		}
+	}
+	return false
+}
+
+// 新区块：通知选择了unconfirmed的地址
+func (w *Watcher) Block(block *types.Block) error {
+	w.notify(block, eventUnconfirmed)
+	return nil
+}
+
+// 区块因分叉回滚：通知已收到unconfirmed通知的地址
+func (w *Watcher) Rollback(block *types.Block) error {
+	w.notify(block, eventReverted)
+	return nil
+}
+
+// 区块已固化：通知全部地址
+func (w *Watcher) Final(block *types.Block) error {
+	w.notify(block, eventConfirmed)
+	return nil
+}
+
+// 为区块中匹配的转账生成投递记录
+func (w *Watcher) notify(block *types.Block, status string) {
+	type match struct {
+		watch    types.WatchAddress
+		transfer types.Transfer
+	}
+
+	w.mu.Lock()
+	var matches []match
+	for _, t := range block.Transfers {
+		for _, a := range w.state.Addresses {
+			if a.Address != t.To || !wants(a, &t) {
+				continue
+			}
+			if status != eventConfirmed && !a.Unconfirmed {
+				continue
+			}
+			matches = append(matches, match{watch: *a, transfer: t})
+		}
+	}
+	w.mu.Unlock()
+	if len(matches) == 0 {
+		return
+	}
+
+	now := time.Now().Unix()
+	var created []*types.WebhookDelivery
+	for _, m := range matches {
+		w.decimals.Fill(&m.transfer)
+
+		event := types.DepositEvent{
+			ID:       fmt.Sprintf("%s-%s-%d", m.transfer.TxID, m.transfer.TokenType, m.transfer.Index),
+			Type:     "deposit",
+			Status:   status,
+			WatchID:  m.watch.ID,
+			Address:  m.watch.Address,
+			Label:    m.watch.Label,
+			Transfer: m.transfer,
+		}
+		payload, _ := json.Marshal(event)
+		created = append(created, &types.WebhookDelivery{
+			// 投递ID带区块哈希：交易因分叉被回滚后重新打包进其他区块时，会再次发出unconfirmed/reverted通知
+			ID:            event.ID + ":" + status + ":" + block.Hash + "@" + m.watch.ID,
+			WatchID:       m.watch.ID,
+			EventID:       event.ID,
+			URL:           m.watch.Webhook,
+			Payload:       payload,
+			Status:        deliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	added := false
+	for _, d := range created {
+		// 服务中断后重新处理同一区块时不重复通知
+		if _, ok := w.index[d.ID]; ok {
+			continue
+		}
+		w.index[d.ID] = d
+		w.deliveries = append(w.deliveries, d)
+		added = true
+	}
+	if added {
+		w.saveDeliveriesLocked()
+	}
+}
+
+// 是否需要通知该代币的转账
+func wants(a *types.WatchAddress, t *types.Transfer) bool {
+	if len(a.Tokens) == 0 {
+		return true
+	}
+	for _, token := range a.Tokens {
+		if token == t.Token {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
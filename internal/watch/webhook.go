@@ -0,0 +1,255 @@
+package watch
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"tron-api-go/internal/types"
+	"tron-api-go/internal/utils"
+)
+
+// 投递状态
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+const (
+	// 投递检查间隔
+	deliverInterval = 2 * time.Second
+	// 单次请求超时
+	deliverTimeout = 10 * time.Second
+	// 同时投递的Webhook地址数，同一地址的通知按顺序逐个投递
+	deliverWorkers = 16
+	// 最多投递次数
+	maxDeliveryAttempts = 10
+	// 重试退避：10秒起每次翻倍，最长1小时
+	baseRetryDelay = 10 * time.Second
+	maxRetryDelay  = time.Hour
+	// 最多保留的投递记录数，超出时删除最早的已完成记录
+	maxDeliveries = 5000
+)
+
+var httpClient = &http.Client{Timeout: deliverTimeout}
+
+func (w *Watcher) deliveriesPath() string {
+	return filepath.Join(w.dir, "deliveries.json")
+}
+
+// 保存投递记录，调用方需持有锁
+func (w *Watcher) saveDeliveriesLocked() {
+	if len(w.deliveries) > maxDeliveries {
+		kept := make([]*types.WebhookDelivery, 0, len(w.deliveries))
+		drop := len(w.deliveries) - maxDeliveries
+		for _, d := range w.deliveries {
+			if drop > 0 && d.Status != deliveryPending {
+				delete(w.index, d.ID)
+				drop--
+				continue
+			}
+			kept = append(kept, d)
+		}
+		w.deliveries = kept
+	}
+
+	if err := utils.WriteJSONFile(w.deliveriesPath(), w.deliveries); err != nil {
+		fmt.Printf("⚠️  保存Webhook投递记录失败: %v\n", err)
+	}
+}
+
+// 计算Webhook签名：HMAC-SHA256(secret, 时间戳 + "." + 请求体)
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// 定期投递到期的通知：每个Webhook地址由独立协程按顺序投递，慢速或无响应的地址不影响其他地址，
+// 同时投递的地址数不超过deliverWorkers
+func (w *Watcher) deliver() {
+	ticker := time.NewTicker(deliverInterval)
+	defer ticker.Stop()
+	slots := make(chan struct{}, deliverWorkers)
+	for range ticker.C {
+		for url, items := range w.due() {
+			go func(url string, items []dueDelivery) {
+				slots <- struct{}{}
+				defer func() {
+					<-slots
+					w.mu.Lock()
+					delete(w.sending, url)
+					w.mu.Unlock()
+				}()
+				for _, d := range items {
+					w.attempt(d)
+				}
+			}(url, items)
+		}
+	}
+}
+
+// 到期待投递的通知及其签名密钥
+type dueDelivery struct {
+	delivery *types.WebhookDelivery
+	url      string
+	payload  []byte
+	secret   string
+	found    bool
+}
+
+// 按Webhook地址分组的到期通知，跳过仍在投递中的地址并将返回的地址标记为投递中
+func (w *Watcher) due() map[string][]dueDelivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	secrets := make(map[string]string, len(w.state.Addresses))
+	for _, a := range w.state.Addresses {
+		secrets[a.ID] = a.Secret
+	}
+
+	now := time.Now().Unix()
+	groups := make(map[string][]dueDelivery)
+	for _, d := range w.deliveries {
+		if d.Status != deliveryPending || d.NextAttemptAt > now || w.sending[d.URL] {
+			continue
+		}
+		secret, ok := secrets[d.WatchID]
+		groups[d.URL] = append(groups[d.URL], dueDelivery{
+			delivery: d,
+			url:      d.URL,
+			payload:  d.Payload,
+			secret:   secret,
+			found:    ok,
+		})
+	}
+	for url := range groups {
+		w.sending[url] = true
+	}
+	return groups
+}
+
+// 投递一次通知，非2xx响应按退避时间重试
+func (w *Watcher) attempt(item dueDelivery) {
+	d := item.delivery
+	if !item.found {
+		w.mu.Lock()
+		d.Status = deliveryFailed
+		d.LastError = "监控地址已删除"
+		d.NextAttemptAt = 0
+		w.saveDeliveriesLocked()
+		w.mu.Unlock()
+		return
+	}
+
+	code, err := post(item.url, item.payload, item.secret, d.ID)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	d.Attempts++
+	d.ResponseCode = code
+	switch {
+	case err == nil:
+		d.Status = deliveryDelivered
+		d.LastError = ""
+		d.NextAttemptAt = 0
+		d.DeliveredAt = time.Now().Unix()
+	case d.Attempts >= maxDeliveryAttempts:
+		d.Status = deliveryFailed
+		d.LastError = err.Error()
+		d.NextAttemptAt = 0
+	default:
+		d.LastError = err.Error()
+		d.NextAttemptAt = time.Now().Add(retryDelay(d.Attempts)).Unix()
+	}
+	w.saveDeliveriesLocked()
+}
+
+// 发送签名的Webhook请求
+func post(url string, payload []byte, secret, deliveryID string) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tron-Event", "deposit")
+	req.Header.Set("X-Tron-Delivery", deliveryID)
+	req.Header.Set("X-Tron-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Tron-Signature", Sign(secret, timestamp, payload))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// 第n次失败后的等待时间
+func retryDelay(n int) time.Duration {
+	d := baseRetryDelay
+	for i := 1; i < n && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+// 查询投递记录(按时间倒序)，watchID和status为空时不筛选
+func (w *Watcher) Deliveries(watchID, status string, limit int) []*types.WebhookDelivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var list []*types.WebhookDelivery
+	for i := len(w.deliveries) - 1; i >= 0 && len(list) < limit; i-- {
+		d := w.deliveries[i]
+		if (watchID != "" && d.WatchID != watchID) || (status != "" && d.Status != status) {
+			continue
+		}
+		copied := *d
+		list = append(list, &copied)
+	}
+	return list
+}
+
+// 重新投递失败的通知
+func (w *Watcher) Redeliver(id string) (*types.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	d, ok := w.index[id]
+	if !ok {
+		return nil, errors.New("投递记录不存在")
+	}
+	if d.Status != deliveryFailed {
+		return nil, fmt.Errorf("投递状态为%s，无需重新投递", d.Status)
+	}
+
+	d.Status = deliveryPending
+	d.Attempts = 0
+	d.LastError = ""
+	d.NextAttemptAt = time.Now().Unix()
+	w.saveDeliveriesLocked()
+
+	copied := *d
+	return &copied, nil
+}
@@ -3,10 +3,13 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"time"
 
+	"tron-api-go/internal/chain"
 	"tron-api-go/internal/routes"
-	"tron-api-go/internal/types"
+	"tron-api-go/internal/settings"
+	"tron-api-go/internal/simulator"
 	"tron-api-go/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -28,29 +31,58 @@ import (
  * 温馨提示：接受各种代码定制
  */
 
-// 全局配置
-var config = &types.Config{
-	Port:            "9527",
-	TronAPIURL:      "https://api.trongrid.io",
-	ContractAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", // USDT TRC20 合约地址
-	Decimals:        6,                                    // USDT 精度
-}
-
 func main() {
+	// 加载配置：默认值、配置文件、环境变量和命令行参数
+	loader, err := settings.NewLoader(os.Args[1:])
+	if err != nil {
+		log.Fatalf("命令行参数错误: %v", err)
+	}
+	config, err := loader.Load()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// 创建链访问客户端，节点地址可通过SIGHUP热加载
+	client, err := chain.New(config)
+	if err != nil {
+		log.Fatalf("链后端配置错误: %v", err)
+	}
+	var devAccounts []simulator.DevAccount
+	if node, ok := client.(*simulator.Node); ok {
+		devAccounts = node.DevAccounts()
+	}
+	config.Chain = chain.NewReloadable(client)
+	endpoint := config.TronAPIURL
+	switch client.Name() {
+	case chain.BackendFixture:
+		endpoint = config.FixtureFile
+	case simulator.Name:
+		endpoint = "(进程内)"
+	}
+
 	// 设置Gin模式
 	gin.SetMode(gin.ReleaseMode)
 
 	// 创建Gin引擎
 	r := gin.Default()
 
-	// 设置路由
-	routes.SetupRoutes(r, config)
+	// 设置路由，收到SIGHUP时各网络按新配置重建链客户端
+	networks := routes.SetupRoutes(r, config)
+	settings.NewReloader(loader, config, networks.Reload).Watch()
 
 	// 启动服务器
 	utils.PrintASCIIArt()
 	fmt.Printf("🚀 TRON API服务启动成功！\n")
 	fmt.Printf("📍 服务地址: http://localhost:%s\n", config.Port)
 	fmt.Printf("📚 接口文档: http://localhost:%s/doc\n", config.Port)
+	fmt.Printf("🌐 网络: %s\n", config.Network)
+	if loader.Path() != "" {
+		fmt.Printf("⚙️ 配置文件: %s (发送SIGHUP重新加载节点地址)\n", loader.Path())
+	}
+	fmt.Printf("⛓️ 链后端: %s %s\n", client.Name(), endpoint)
+	for _, acc := range devAccounts {
+		fmt.Printf("🧪 模拟账户: %s 私钥: %s\n", acc.Address, acc.PrivateKey)
+	}
 	fmt.Printf("✈️ 技术支持: https://t.me/king_orz\n")
 	fmt.Println()
 